@@ -7,7 +7,9 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	elasticsearch "github.com/elastic/go-elasticsearch/v8"
@@ -15,19 +17,25 @@ import (
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/docs"
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/handlers"
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/middlewares"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/common"
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/daemons"
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/it"
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/logger"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/tracing"
 	_ "github.com/lib/pq"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
 
 	"github.com/gin-contrib/cors"
 	limits "github.com/gin-contrib/size"
@@ -38,20 +46,49 @@ import (
 )
 
 var (
-	ctx    context.Context
-	router *gin.Engine
-
-	userService      services.UserService
-	roadmapService   services.RoadmapService
-	emailService     services.EmailService
-	objectService    services.ObjectService
-	telemetryService services.TelemetryService
-	genService       services.GenService
-	searchService    services.ElasticService
+	ctx             context.Context
+	router          *gin.Engine
+	registry        *prometheus.Registry
+	shutdownTracing func(context.Context) error
+
+	userService           services.UserService
+	roadmapService        services.RoadmapService
+	emailService          services.EmailService
+	objectService         services.ObjectService
+	telemetryService      services.TelemetryService
+	genService            services.GenService
+	searchService         services.SearchService
+	searchSync            services.SearchSyncService
+	quotaService          services.QuotaService
+	promptScreen          services.PromptScreenService
+	enrollmentService     services.EnrollmentService
+	recommendationService services.RecommendationService
+	trendingService       services.TrendingService
+	tagService            services.TagService
+	dedupService          services.DedupService
+	telemetryRetention    services.TelemetryRetentionService
+	activityService       services.ActivityService
+	funnelService         services.FunnelService
+	creatorService        services.CreatorService
+	calibrationService    services.CalibrationService
+	analyticsService      services.AnalyticsService
 
 	telemetryMiddleware middlewares.TelemetryMiddleware
-
-	roadmapHandler handlers.RoadmapHandler
+	authMiddleware      middlewares.AuthMiddleware
+	quotaMiddleware     middlewares.QuotaMiddleware
+	activityMiddleware  middlewares.ActivityMiddleware
+
+	roadmapHandler     handlers.RoadmapHandler
+	quotaHandler       handlers.QuotaHandler
+	searchHandler      handlers.SearchHandler
+	meHandler          handlers.MeHandler
+	tagHandler         handlers.TagHandler
+	dedupHandler       handlers.DedupHandler
+	metricsHandler     handlers.MetricsHandler
+	analyticsHandler   handlers.AnalyticsHandler
+	funnelHandler      handlers.FunnelHandler
+	creatorHandler     handlers.CreatorHandler
+	calibrationHandler handlers.CalibrationHandler
 
 	taskRunner daemons.TaskRunner
 )
@@ -59,18 +96,17 @@ var (
 func init() {
 	logger.InitSlogger()
 	ctx = context.Background()
-
-	cfg := elasticsearch.Config{
-		Addresses: []string{"https://elastic.roady.patos.dev/"},
-	}
-	es, err := elasticsearch.NewTypedClient(cfg)
-	if err != nil {
-		panic(err)
-	}
+	registry = prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	shutdownTracing = it.Must(tracing.Init(ctx, tracing.Config{
+		ServiceName: constants.ProjectName,
+		Exporter:    tracing.Exporter(constants.TracingExporter),
+		FilePath:    constants.TracingFile,
+	}))
 
 	mongoConn := options.Client().ApplyURI(
 		common.GetEnvVarDefault("MONGO_URI", "mongodb://localhost:27017"),
-	)
+	).SetMonitor(tracing.NewMongoMonitor())
 	mongoClient := it.Must(mongo.Connect(ctx, mongoConn))
 	if err := mongoClient.Ping(ctx, readpref.Primary()); err != nil {
 		panic(errors.Join(err, errors.New("could not ping mongodb")))
@@ -81,13 +117,97 @@ func init() {
 		Options: options.Index(),
 	}
 
-	metricsCol := mongoClient.Database("telemetry").Collection("metrics")
-	eventsCol := mongoClient.Database("telemetry").Collection("events")
+	metricRetention := it.Must(services.ParseRetentionPolicy(constants.TelemetryMetricRetention, constants.TelemetryMetricRetentionOverrides))
+	eventRetention := it.Must(services.ParseRetentionPolicy(constants.TelemetryEventRetention, constants.TelemetryEventRetentionOverrides))
+	telemetryDb := mongoClient.Database("telemetry")
+	it.MustNotErr(services.EnsureTelemetryCollection(ctx, telemetryDb, "metrics", metricRetention.Max()))
+	it.MustNotErr(services.EnsureTelemetryCollection(ctx, telemetryDb, "events", eventRetention.Max()))
+
+	metricsCol := telemetryDb.Collection("metrics")
+	eventsCol := telemetryDb.Collection("events")
+	rollupsCol := telemetryDb.Collection("rollups")
+	rollupStateCol := telemetryDb.Collection("rollup_state")
+	quarantineCol := telemetryDb.Collection("events_quarantine")
 	roadmapsCol := mongoClient.Database("roadmaps").Collection("roadmaps")
 	usersCol := mongoClient.Database("roadmaps").Collection("users")
+	generationsCol := mongoClient.Database("roadmaps").Collection("generations")
+	searchOutboxCol := mongoClient.Database("roadmaps").Collection("search_outbox")
+	searchSyncCol := mongoClient.Database("roadmaps").Collection("search_sync")
+	enrollmentsCol := mongoClient.Database("roadmaps").Collection("enrollments")
+	viewsCol := mongoClient.Database("roadmaps").Collection("views")
+	tagsCol := mongoClient.Database("roadmaps").Collection("tags")
+	fingerprintsCol := mongoClient.Database("roadmaps").Collection("fingerprints")
+	activitiesCol := mongoClient.Database("roadmaps").Collection("activities")
+	activityDaysCol := mongoClient.Database("roadmaps").Collection("activity_days")
+	reportsCol := mongoClient.Database("analytics").Collection("reports")
+	roadmapFunnelsCol := mongoClient.Database("analytics").Collection("roadmap_funnels")
+	roadmapDaysCol := mongoClient.Database("analytics").Collection("roadmap_days")
+	roadmapStatsCol := mongoClient.Database("analytics").Collection("roadmap_stats")
+	calibrationsCol := mongoClient.Database("analytics").Collection("calibrations")
+	quotaBucketsCol := mongoClient.Database("quotas").Collection("buckets")
+	quotaUsageCol := mongoClient.Database("quotas").Collection("usage")
+	quotaOverridesCol := mongoClient.Database("quotas").Collection("overrides")
 
 	it.Must(metricsCol.Indexes().CreateOne(ctx, tsIdxModel))
 	it.Must(eventsCol.Indexes().CreateOne(ctx, tsIdxModel))
+	nameTsIdxModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "name", Value: 1}, {Key: "ts", Value: 1}},
+	}
+	it.Must(metricsCol.Indexes().CreateOne(ctx, nameTsIdxModel))
+	it.Must(eventsCol.Indexes().CreateOne(ctx, nameTsIdxModel))
+	it.Must(quarantineCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.M{"ts": 1},
+			Options: options.Index().SetExpireAfterSeconds(int32(constants.TelemetryEventRetention.Seconds())),
+		},
+	))
+	it.Must(rollupsCol.Indexes().CreateMany(
+		ctx,
+		[]mongo.IndexModel{
+			{Keys: bson.D{{Key: "name", Value: 1}, {Key: "resolution", Value: 1}, {Key: "ts", Value: 1}}},
+			{
+				Keys: bson.M{"ts": 1},
+				Options: options.Index().
+					SetExpireAfterSeconds(int32(constants.HourlyRollupTTL.Seconds())).
+					SetPartialFilterExpression(bson.M{"resolution": models.RollupHour}),
+			},
+		},
+	))
+	it.Must(activitiesCol.Indexes().CreateMany(
+		ctx,
+		[]mongo.IndexModel{
+			{Keys: bson.D{{Key: "userEmail", Value: 1}, {Key: "ts", Value: -1}}},
+			{
+				Keys:    bson.M{"ts": 1},
+				Options: options.Index().SetExpireAfterSeconds(int32(constants.ActivityTTL.Seconds())),
+			},
+		},
+	))
+	it.Must(activityDaysCol.Indexes().CreateMany(
+		ctx,
+		[]mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "userEmail", Value: 1}, {Key: "day", Value: -1}},
+				Options: options.Index().SetUnique(true),
+			},
+			{Keys: bson.M{"day": 1}},
+		},
+	))
+	it.Must(roadmapDaysCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "roadmapId", Value: 1}, {Key: "day", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	))
+	it.Must(roadmapsCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.M{"forkedFrom": 1},
+			Options: options.Index().SetSparse(true),
+		},
+	))
 	it.Must(usersCol.Indexes().CreateOne(
 		ctx,
 		mongo.IndexModel{
@@ -95,6 +215,106 @@ func init() {
 			Options: options.Index().SetUnique(true),
 		},
 	))
+	it.Must(generationsCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.M{"ts": 1},
+			Options: options.Index().SetExpireAfterSeconds(int32(constants.GenerationCacheTTL.Seconds())),
+		},
+	))
+	it.Must(enrollmentsCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.M{"enrolledAt": 1},
+			Options: options.Index(),
+		},
+	))
+	it.Must(enrollmentsCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "userEmail", Value: 1}, {Key: "roadmapId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	))
+	it.Must(viewsCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "roadmapId", Value: 1}, {Key: "day", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	))
+	it.Must(viewsCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.M{"day": 1},
+			Options: options.Index().SetExpireAfterSeconds(int32(constants.ViewsTTL.Seconds())),
+		},
+	))
+	it.Must(roadmapsCol.Indexes().CreateMany(
+		ctx,
+		[]mongo.IndexModel{
+			{Keys: bson.D{{Key: "trending.day", Value: -1}, {Key: "upvotes", Value: -1}}},
+			{Keys: bson.D{{Key: "trending.week", Value: -1}, {Key: "upvotes", Value: -1}}},
+			{Keys: bson.D{{Key: "trending.month", Value: -1}, {Key: "upvotes", Value: -1}}},
+		},
+	))
+	it.Must(tagsCol.Indexes().CreateMany(
+		ctx,
+		[]mongo.IndexModel{
+			{Keys: bson.M{"synonyms": 1}},
+			{Keys: bson.M{"compact": 1}},
+			{Keys: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		},
+	))
+	it.Must(fingerprintsCol.Indexes().CreateMany(
+		ctx,
+		[]mongo.IndexModel{
+			{Keys: bson.M{"bands": 1}},
+			{Keys: bson.M{"duplicates.roadmapId": 1}},
+		},
+	))
+	it.Must(roadmapsCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "tags", Value: 1}, {Key: "upvotes", Value: -1}},
+			Options: options.Index(),
+		},
+	))
+	it.Must(searchOutboxCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.M{"ts": 1},
+			Options: options.Index().SetExpireAfterSeconds(int32(constants.SearchOutboxTTL.Seconds())),
+		},
+	))
+	it.Must(quotaBucketsCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.M{"updatedAt": 1},
+			Options: options.Index().SetExpireAfterSeconds(int32((24 * time.Hour).Seconds())),
+		},
+	))
+	it.Must(quotaUsageCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.M{"expiresAt": 1},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	))
+	it.Must(roadmapsCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "promptKey", Value: 1}, {Key: "upvotes", Value: -1}},
+			Options: options.Index(),
+		},
+	))
+	it.Must(roadmapsCol.Indexes().CreateOne(
+		ctx,
+		mongo.IndexModel{
+			Keys:    bson.M{"promptBands": 1},
+			Options: options.Index().SetSparse(true),
+		},
+	))
 
 	s3Host := it.Must(common.ExtractHostFromUrl(constants.S3Endpoint))
 	s3Secure := it.Must(common.UrlIsSecure(constants.S3Endpoint))
@@ -106,8 +326,9 @@ func init() {
 				os.Getenv("S3_SECRET_ACCESS_KEY"),
 				"",
 			),
-			Region: constants.S3Region,
-			Secure: s3Secure,
+			Region:    constants.S3Region,
+			Secure:    s3Secure,
+			Transport: otelhttp.NewTransport(it.Must(minio.DefaultTransport(s3Secure))),
 		},
 	))
 
@@ -116,19 +337,226 @@ func init() {
 	} else {
 		emailService = services.NewEmailServiceResendImpl(os.Getenv("RESEND_API_KEY"), "internal/templates")
 	}
+	emailService = services.NewEmailServiceMeteredImpl(emailService, registry)
 	objectService = services.NewObjectServiceMinioImpl(minioClient)
-	telemetryService = services.NewTelemetryServiceMongoAsyncImpl(mongoClient, metricsCol, eventsCol, 100)
-	services.NewUserServiceImpl(mongoClient, usersCol)
-	roadmapService = services.NewRoadmapServiceImpl(mongoClient, roadmapsCol)
-	genService = services.NewGenServiceImpl("http://genservice:5000/")
-	searchService = services.NewElasticServiceImpl(es)
+	telemetryService = services.NewTelemetryServiceMongoAsyncImpl(
+		mongoClient,
+		metricsCol,
+		eventsCol,
+		services.TelemetryConfig{
+			QueueSize:     constants.TelemetryQueueSize,
+			BatchSize:     constants.TelemetryBatchSize,
+			FlushInterval: constants.TelemetryFlushInterval,
+			Overflow:      services.OverflowPolicy(constants.TelemetryOverflowPolicy),
+		},
+	)
+	eventRegistry := services.NewEventRegistry(services.ProductEvents...)
+	telemetryService = services.NewTelemetryServiceValidatedImpl(
+		telemetryService,
+		eventRegistry,
+		services.EventValidationMode(constants.EventValidationMode),
+		quarantineCol,
+		services.TelemetryConfig{
+			QueueSize:     constants.TelemetryQueueSize,
+			BatchSize:     constants.TelemetryBatchSize,
+			FlushInterval: constants.TelemetryFlushInterval,
+			Overflow:      services.OverflowPolicy(constants.TelemetryOverflowPolicy),
+		},
+		registry,
+	)
+	analyticsService = services.NewAnalyticsServiceMongoImpl(metricsCol, eventsCol, rollupsCol, constants.AnalyticsMaxBuckets)
+	telemetryRetention = services.NewTelemetryRetentionServiceMongoImpl(
+		metricsCol,
+		eventsCol,
+		rollupsCol,
+		rollupStateCol,
+		metricRetention,
+		eventRetention,
+		constants.RollupDelay,
+	)
+	activityService = services.NewActivityServiceMongoAsyncImpl(
+		activitiesCol,
+		activityDaysCol,
+		telemetryService,
+		services.TelemetryConfig{
+			QueueSize:     constants.TelemetryQueueSize,
+			BatchSize:     constants.TelemetryBatchSize,
+			FlushInterval: constants.TelemetryFlushInterval,
+			Overflow:      services.OverflowPolicy(constants.TelemetryOverflowPolicy),
+		},
+	)
+	funnelService = services.NewFunnelServiceMongoImpl(
+		roadmapsCol,
+		enrollmentsCol,
+		activityDaysCol,
+		reportsCol,
+		roadmapFunnelsCol,
+		constants.FunnelWindow,
+		constants.CohortWeeks,
+	)
+	creatorService = services.NewCreatorServiceMongoImpl(
+		roadmapsCol,
+		viewsCol,
+		enrollmentsCol,
+		activitiesCol,
+		roadmapDaysCol,
+		roadmapStatsCol,
+		constants.MaxStudyGap,
+	)
+	calibrationService = services.NewCalibrationServiceMongoImpl(
+		roadmapsCol,
+		roadmapStatsCol,
+		calibrationsCol,
+		services.CalibrationPolicy{
+			MinSamples: constants.CalibrationMinSamples,
+			MinNodes:   constants.CalibrationMinNodes,
+			MaxFactor:  constants.CalibrationMaxFactor,
+		},
+		constants.CalibrationHintTopics,
+	)
+	userService = services.NewUserServiceImpl(mongoClient, usersCol)
+	roadmapService = services.NewRoadmapServiceImpl(
+		mongoClient,
+		roadmapsCol,
+		searchOutboxCol,
+		constants.PromptDuplicateThreshold,
+		constants.PromptDuplicateCandidates,
+	)
+	enrollmentService = services.NewEnrollmentServiceImpl(mongoClient, enrollmentsCol)
+	trendingService = services.NewTrendingServiceMongoImpl(roadmapsCol, viewsCol, enrollmentService)
+	tagService = services.NewTagServiceMongoImpl(tagsCol, roadmapService)
+	dedupService = services.NewDedupServiceMongoImpl(
+		roadmapsCol,
+		fingerprintsCol,
+		constants.DuplicateThreshold,
+		constants.DuplicateCandidates,
+	)
+	recommendationService = services.NewRecommendationServiceImpl(
+		roadmapService,
+		enrollmentService,
+		userService,
+		telemetryService,
+		constants.TrendingWindow,
+	)
+	genService = services.NewGenServiceCachedImpl(
+		services.NewGenServiceCalibratedImpl(
+			services.NewGenServiceMeteredImpl(services.NewGenServiceImpl(constants.GenServiceUrl), registry),
+			calibrationService,
+		),
+		generationsCol,
+		constants.GeneratorVersion,
+		constants.GenerationCacheTTL,
+	)
+	switch constants.SearchBackend {
+	case "memory":
+		searchService = services.NewSearchServiceMemoryImpl()
+	default:
+		esCfg := elasticsearch.Config{
+			Addresses: strings.Split(constants.ElasticAddresses, ","),
+			Username:  constants.ElasticUsername,
+			Password:  constants.ElasticPassword,
+			APIKey:    constants.ElasticApiKey,
+
+			Instrumentation: elasticsearch.NewOpenTelemetryInstrumentation(otel.GetTracerProvider(), false),
+		}
+		if constants.ElasticCaCertPath != "" {
+			esCfg.CACert = it.Must(os.ReadFile(constants.ElasticCaCertPath))
+		}
+		es := it.Must(elasticsearch.NewTypedClient(esCfg))
+		searchService = services.NewSearchServiceElasticImpl(es, constants.SearchIndex)
+	}
+	if err := searchService.EnsureIndex(ctx); err != nil {
+		slog.Error(err.Error())
+	}
+	searchSync = services.NewSearchSyncServiceMongoImpl(
+		roadmapsCol,
+		searchOutboxCol,
+		searchSyncCol,
+		searchService,
+		constants.SearchSyncBatchSize,
+	)
+	if constants.SearchBackend == "memory" {
+		// the memory index starts empty on every boot
+		if err := searchSync.Reindex(ctx); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+	quotaService = services.NewQuotaServiceMongoImpl(
+		mongoClient,
+		quotaBucketsCol,
+		quotaUsageCol,
+		quotaOverridesCol,
+		usersCol,
+		services.QuotaLimits{
+			UserBucket: services.TokenBucket{Capacity: 3, RefillPerSec: 1.0 / 60},
+			IpBucket:   services.TokenBucket{Capacity: 10, RefillPerSec: 5.0 / 60},
+			DailyCaps: map[models.PlanTier]int{
+				models.PlanFree: 10,
+				models.PlanPro:  100,
+			},
+			IpDailyCap: 200, // room for a few users behind the same NAT
+		},
+	)
 
-	telemetryMiddleware = middlewares.NewTelemetryMiddleware(telemetryService)
+	promptScreen = services.NewPromptScreenServiceImpl(
+		telemetryService,
+		nil,
+		strings.Split(constants.PromptDenyList, ","),
+		constants.PromptMinLen,
+		constants.PromptMaxLen,
+	)
+
+	registry.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "telemetry_events_dropped_total",
+			Help: "Telemetry events dropped on queue overflow.",
+		}, func() float64 {
+			return float64(telemetryService.Stats().Events.Dropped)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "telemetry_metrics_dropped_total",
+			Help: "Telemetry metrics dropped on queue overflow.",
+		}, func() float64 {
+			return float64(telemetryService.Stats().Metrics.Dropped)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "telemetry_queued",
+			Help: "Telemetry items waiting to be written.",
+		}, func() float64 {
+			stats := telemetryService.Stats()
+			return float64(stats.Events.Queued + stats.Metrics.Queued + stats.Quarantined.Queued)
+		}),
+	)
 
-	roadmapHandler = handlers.NewRoadmapHandler(roadmapService, genService, searchService)
+	telemetryMiddleware = middlewares.NewTelemetryMiddleware(telemetryService, registry)
+	authMiddleware = middlewares.NewAuthMiddleware(constants.AdminToken, constants.MetricsToken)
+	quotaMiddleware = middlewares.NewQuotaMiddleware(quotaService)
+	activityMiddleware = middlewares.NewActivityMiddleware(activityService)
+
+	roadmapHandler = handlers.NewRoadmapHandler(roadmapService, genService, searchService, promptScreen, enrollmentService, trendingService, tagService, dedupService, calibrationService)
+	quotaHandler = handlers.NewQuotaHandler(quotaService)
+	searchHandler = handlers.NewSearchHandler(searchSync)
+	meHandler = handlers.NewMeHandler(recommendationService, userService, activityService)
+	tagHandler = handlers.NewTagHandler(tagService, roadmapService)
+	dedupHandler = handlers.NewDedupHandler(dedupService)
+	metricsHandler = handlers.NewMetricsHandler(registry)
+	analyticsHandler = handlers.NewAnalyticsHandler(analyticsService, eventRegistry)
+	funnelHandler = handlers.NewFunnelHandler(funnelService)
+	creatorHandler = handlers.NewCreatorHandler(creatorService, roadmapService)
+	calibrationHandler = handlers.NewCalibrationHandler(calibrationService)
 
 	router = gin.Default()
-	router.SetTrustedProxies([]string{"*"})
+	// lets services reach the request span through the *gin.Context they are given
+	router.ContextWithFallback = true
+	// X-Forwarded-For is only honoured from the configured proxies, the quota
+	// keys on the client IP
+	trustedProxies := []string{}
+	for _, proxy := range strings.Split(constants.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	it.MustNotErr(router.SetTrustedProxies(trustedProxies))
 
 	corsCfg := cors.DefaultConfig()
 	// corsCfg.AllowOrigins = []string{constants.ApiHostUrl, constants.AppHostUrl}
@@ -162,10 +590,23 @@ func init() {
 	})
 
 	// Daemons
-	// taskRunner.RegisterTask(24*time.Hour, userService.DeleteExpiredPwResets, 1)
-	// taskRunner.RegisterTask(24*time.Hour, organizationService.DeleteExpiredOrgInvites, 1)
-	taskRunner.RegisterTask(time.Second, telemetryService.Upload, 1)
+	taskRunner.Instrument(registry)
+	// taskRunner.RegisterTask("delete-expired-pw-resets", 24*time.Hour, userService.DeleteExpiredPwResets, 1)
+	// taskRunner.RegisterTask("delete-expired-org-invites", 24*time.Hour, organizationService.DeleteExpiredOrgInvites, 1)
+	taskRunner.RegisterTask("telemetry-upload", time.Second, telemetryService.Upload, 1)
+	taskRunner.RegisterTask("activity-upload", time.Second, activityService.Upload, 1)
+	taskRunner.RegisterTask("telemetry-rollup", constants.RollupInterval, telemetryRetention.Rollup, 1)
+	taskRunner.RegisterTask("telemetry-expire", constants.TelemetryExpireInterval, telemetryRetention.Expire, 1)
+	taskRunner.RegisterTask("search-sync", time.Second, searchSync.Sync, 1)
+	taskRunner.RegisterTask("trending-recompute", constants.TrendingRecomputeInterval, trendingService.Recompute, 1)
+	taskRunner.RegisterTask("tag-backfill", constants.TagBackfillInterval, tagService.Backfill, 1)
+	taskRunner.RegisterTask("dedup-backfill", constants.DedupBackfillInterval, dedupService.Backfill, 1)
+	taskRunner.RegisterTask("funnel-recompute", constants.FunnelRecomputeInterval, funnelService.Recompute, 1)
+	taskRunner.RegisterTask("creator-days", constants.CreatorDaysInterval, creatorService.MaterializeDays, 1)
+	taskRunner.RegisterTask("creator-stats", constants.CreatorStatsInterval, creatorService.RecomputeStats, 1)
+	taskRunner.RegisterTask("calibration-recompute", constants.CalibrationInterval, calibrationService.Recompute, 1)
 	taskRunner.RegisterTask(
+		"study-reminders",
 		12*time.Hour,
 		func() error {
 			ctx := context.TODO()
@@ -174,33 +615,23 @@ func init() {
 				return err
 			}
 
-			now := time.Now()
-			startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-			endOfDay := startOfDay.Add(24 * time.Hour)
+			// a rolling day, whatever time of the day the task runs
+			active, err := activityService.ActiveUsers(ctx, time.Now().Add(-24*time.Hour))
+			if err != nil {
+				return err
+			}
+			studied := map[string]bool{}
+			for _, email := range active {
+				studied[email] = true
+			}
 
 			for _, u := range users {
-				evs, err := telemetryService.GetEvents(ctx,
-					bson.M{
-						"name": "user_log",
-						"metadata": bson.M{
-							"logged": true,
-							"email":  u.Email,
-						},
-						"ts": bson.M{
-							"$gte": startOfDay,
-							"$lt":  endOfDay,
-						},
-					},
-				)
-				if err != nil {
+				if studied[u.Email] {
 					continue
 				}
-
-				if len(evs) == 0 {
-					err = emailService.SendReminder(u.Email, "", "")
-					if err != nil {
-						slog.Error(err.Error())
-					}
+				err = emailService.SendReminder(u.Email, "", "")
+				if err != nil {
+					slog.Error(err.Error())
 				}
 			}
 			return nil
@@ -218,18 +649,99 @@ func init() {
 // @name Authorization
 // @description "Type 'Bearer $TOKEN' to correctly set the API Key"
 func main() {
+	// maintenance commands, e.g. `roady reindex`
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1]))
+	}
 
 	// LB healthcheck
 	router.GET("/", func(ctx *gin.Context) {
 		ctx.String(http.StatusOK, "OK")
 	})
 
+	router.Use(telemetryMiddleware.Trace())
 	router.Use(telemetryMiddleware.CollectApiCalls())
+	metricsHandler.RegisterRoutes(&router.RouterGroup, authMiddleware)
 
 	basePath := router.Group("/v1")
-	roadmapHandler.RegisterRoutes(basePath, telemetryMiddleware)
+	roadmapHandler.RegisterRoutes(basePath, activityMiddleware, quotaMiddleware)
+	quotaHandler.RegisterRoutes(basePath, authMiddleware)
+	searchHandler.RegisterRoutes(basePath, authMiddleware)
+	meHandler.RegisterRoutes(basePath, activityMiddleware)
+	tagHandler.RegisterRoutes(basePath, authMiddleware)
+	dedupHandler.RegisterRoutes(basePath, authMiddleware)
+	analyticsHandler.RegisterRoutes(basePath, authMiddleware)
+	funnelHandler.RegisterRoutes(basePath, authMiddleware)
+	creatorHandler.RegisterRoutes(basePath)
+	calibrationHandler.RegisterRoutes(basePath, authMiddleware)
 
 	taskRunner.Dispatch()
 
-	slog.Error(router.Run(":8080").Error())
+	srv := &http.Server{Addr: ":8080", Handler: router}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	}()
+
+	stop, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	<-stop.Done()
+	slog.Info("shutting down")
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(ctx, constants.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error(err.Error())
+	}
+	// requests are done, whatever they recorded is in the queues, activities
+	// record telemetry events so they go first
+	if err := activityService.Flush(shutdownCtx); err != nil {
+		slog.Error(err.Error())
+	}
+	if err := telemetryService.Flush(shutdownCtx); err != nil {
+		slog.Error(err.Error())
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+// runCommand runs a maintenance command and returns the exit code.
+func runCommand(cmd string) int {
+	switch cmd {
+	case "reindex":
+		if err := searchSync.Reindex(ctx); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		return 0
+	case "check-index":
+		drift, err := searchSync.CheckConsistency(ctx, false)
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		fmt.Printf("%+v\n", drift)
+		if len(drift.Missing)+len(drift.Extra)+len(drift.Stale) > 0 {
+			return 2
+		}
+		return 0
+	case "backfill-tags":
+		if err := tagService.Backfill(); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		return 0
+	case "migrate-telemetry":
+		if err := telemetryRetention.Migrate(ctx); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		return 0
+	default:
+		slog.Error(fmt.Sprintf("unknown command %q, expected reindex, check-index, backfill-tags or migrate-telemetry", cmd))
+		return 1
+	}
 }
@@ -0,0 +1,13 @@
+package dto
+
+import "github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+
+type ActivityQuery struct {
+	Email string `form:"email" binding:"required"`
+	Days  int    `form:"days" binding:"omitempty,min=1,max=365"`
+}
+
+type ActivitySummary struct {
+	Streak int                  `json:"streak"` // consecutive active days, up to today or yesterday
+	Days   []models.ActivityDay `json:"days"`   // most recent first
+}
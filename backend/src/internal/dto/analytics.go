@@ -0,0 +1,69 @@
+package dto
+
+import "time"
+
+// AnalyticsInterval is the width of the time buckets of an analytics query.
+type AnalyticsInterval string
+
+const (
+	IntervalMinute AnalyticsInterval = "minute"
+	IntervalHour   AnalyticsInterval = "hour"
+	IntervalDay    AnalyticsInterval = "day"
+	IntervalWeek   AnalyticsInterval = "week"
+	IntervalMonth  AnalyticsInterval = "month"
+)
+
+// AnalyticsRange is the time range of an analytics query, from inclusive and
+// to exclusive, in RFC 3339.
+type AnalyticsRange struct {
+	From     time.Time         `form:"from"`
+	To       time.Time         `form:"to"`
+	Interval AnalyticsInterval `form:"interval" binding:"omitempty,oneof=minute hour day week month"`
+}
+
+type MetricQuery struct {
+	AnalyticsRange
+	Name    string   `form:"name" binding:"required,max=100"`
+	Tags    []string `form:"tag" binding:"omitempty,max=10,dive,contains=:"` // key:value filters
+	GroupBy string   `form:"groupBy" binding:"omitempty,max=40"`             // tag key
+}
+
+type MetricBucket struct {
+	Ts    time.Time `json:"ts"`
+	Group string    `json:"group,omitempty"`
+	Count int       `json:"count"`
+	Sum   float64   `json:"sum"`
+	Avg   float64   `json:"avg"`
+	P50   float64   `json:"p50"`
+	P95   float64   `json:"p95"`
+	P99   float64   `json:"p99"`
+}
+
+type EventCountQuery struct {
+	AnalyticsRange
+	Names []string `form:"name" binding:"omitempty,max=20,dive,max=100"`
+}
+
+type EventBucket struct {
+	Ts    time.Time `json:"ts"`
+	Name  string    `json:"name"`
+	Count int       `json:"count"`
+}
+
+type DistinctUsersQuery struct {
+	AnalyticsRange
+	Names []string `form:"name" binding:"omitempty,max=20,dive,max=100"`
+}
+
+type DistinctUsersBucket struct {
+	Ts    time.Time `json:"ts"`
+	Users int       `json:"users"`
+}
+
+type RollupQuery struct {
+	From       time.Time `form:"from"`
+	To         time.Time `form:"to"`
+	Resolution string    `form:"resolution" binding:"omitempty,oneof=hour day"`
+	Name       string    `form:"name" binding:"required,max=100"`
+	Tags       []string  `form:"tag" binding:"omitempty,max=10,dive,contains=:"` // key:value filters
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+
+type CreatorAnalyticsQuery struct {
+	Email string `form:"email" binding:"required"`
+	Days  int    `form:"days" binding:"omitempty,min=1,max=365"`
+}
+
+type CreatorAnalytics struct {
+	RoadmapID string `json:"roadmapId"`
+	Views     int    `json:"views"` // over the requested days
+	Upvotes   int    `json:"upvotes"`
+	models.RoadmapStats
+	Daily []models.RoadmapDay `json:"daily"` // oldest first
+}
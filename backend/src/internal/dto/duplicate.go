@@ -0,0 +1,16 @@
+package dto
+
+type DuplicateRoadmap struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	UserEmail  string  `json:"userEmail"`
+	Upvotes    int     `json:"upvotes"`
+	Similarity float64 `json:"similarity,omitempty"`
+}
+
+// DuplicateCluster is a group of near-identical roadmaps, most upvoted first,
+// which is the one to keep when merging.
+type DuplicateCluster struct {
+	Size     int                `json:"size"`
+	Roadmaps []DuplicateRoadmap `json:"roadmaps"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+import "github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+
+// FeedReasonKind tells what made a roadmap show up in a feed.
+type FeedReasonKind string
+
+const (
+	FeedReasonLearning  FeedReasonKind = "learning"
+	FeedReasonCompleted FeedReasonKind = "completed"
+	FeedReasonLevel     FeedReasonKind = "level"
+	FeedReasonPopular   FeedReasonKind = "popular"
+)
+
+type FeedQuery struct {
+	Email string `form:"email" binding:"required"`
+	Size  int    `form:"size" binding:"omitempty,min=1,max=50"`
+}
+
+type FeedReason struct {
+	Kind FeedReasonKind `json:"kind"`
+	Text string         `json:"text"`
+}
+
+type FeedItem struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Difficulty string     `json:"difficulty"`
+	Tags       []string   `json:"tags"`
+	Upvotes    int        `json:"upvotes"`
+	Score      float64    `json:"score"`
+	Reason     FeedReason `json:"reason"`
+}
+
+// Feed is a personalized list of roadmaps, FeedID ties clicks to the impression.
+type Feed struct {
+	FeedID string     `json:"feedId"`
+	Items  []FeedItem `json:"items"`
+}
+
+type FeedClick struct {
+	FeedID    string `json:"feedId" binding:"required"`
+	RoadmapID string `json:"roadmapId" binding:"required"`
+	Position  int    `json:"position" binding:"min=0"`
+}
+
+type SkillLevel struct {
+	SkillLevel models.SkillLevel `json:"skillLevel" binding:"required,oneof=beginner intermediate advanced"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type PromptRejection struct {
+	Reasons []string `json:"reasons"`
+}
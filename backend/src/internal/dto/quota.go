@@ -0,0 +1,6 @@
+package dto
+
+type QuotaOverride struct {
+	DailyLimit int  `json:"dailyLimit" binding:"min=0"`
+	Unlimited  bool `json:"unlimited"`
+}
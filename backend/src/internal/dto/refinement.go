@@ -0,0 +1,27 @@
+package dto
+
+// RefinementScope is the part of a roadmap a refinement applies to.
+type RefinementScope string
+
+const (
+	RefinementScopeRoadmap RefinementScope = "roadmap"
+	RefinementScopeModule  RefinementScope = "module"
+	RefinementScopeNode    RefinementScope = "node"
+)
+
+// Refinement is a follow-up instruction applied to an existing roadmap. For
+// RefinementScopeModule the module TargetID is regenerated, for
+// RefinementScopeNode the node TargetID is expanded into sub-nodes.
+type Refinement struct {
+	Scope       RefinementScope `json:"scope"`
+	TargetID    string          `json:"targetId,omitempty"`
+	Instruction string          `json:"instruction"`
+}
+
+type RefineRoadmap struct {
+	Instruction string `json:"instruction" binding:"required,max=2000"`
+}
+
+type RefineTarget struct {
+	Instruction string `json:"instruction" binding:"max=2000"`
+}
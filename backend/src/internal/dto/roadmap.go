@@ -14,4 +14,55 @@ type Roadmap struct {
 	Tags                  []string         `json:"tags"`
 	Modules               []models.Modules `json:"modules"`
 	Nodes                 []models.Nodes   `json:"nodes"`
+	Measured              *MeasuredTime    `json:"measured,omitempty" bson:"-"` // set on reads only
+}
+
+// MeasuredTime is the community-measured study time of a roadmap, shown next
+// to its estimates. Nodes without enough samples are estimated through the
+// calibration of the roadmap's topics.
+type MeasuredTime struct {
+	TotalMinutes int                     `json:"totalMinutes"`
+	Coverage     float64                 `json:"coverage"` // share of the nodes actually measured
+	Nodes        map[string]MeasuredNode `json:"nodes"`    // by node ID, measured nodes only
+}
+
+type MeasuredNode struct {
+	MedianMinutes float64 `json:"medianMinutes"`
+	Samples       int     `json:"samples"`
+	Factor        float64 `json:"factor"` // measured over estimated
+}
+
+// CalibrationHints tell the generator how its past estimates compare with the
+// time learners actually took.
+type CalibrationHints struct {
+	Overall float64            `json:"overall"`          // over every measured node, 0 if unknown
+	Topics  map[string]float64 `json:"topics,omitempty"` // by tag slug
+}
+
+type ListQuery struct {
+	Sort models.RoadmapSort `form:"sort" binding:"omitempty,oneof=upvotes trending"`
+}
+
+type TrendingQuery struct {
+	Window models.TrendingWindow `form:"window" binding:"omitempty,oneof=day week month"`
+	Size   int                   `form:"size" binding:"omitempty,min=1,max=100"`
+}
+
+// GenerateRoadmapRequest is the body of a generation request. Every field but
+// Prompt is an optional hint passed through to the generator.
+type GenerateRoadmapRequest struct {
+	Prompt          string   `json:"prompt" binding:"required"`
+	Difficulty      string   `json:"difficulty,omitempty" binding:"omitempty,oneof=beginner intermediate advanced mixed"`
+	SkillLevel      string   `json:"skillLevel,omitempty" binding:"omitempty,oneof=none beginner intermediate advanced"`
+	WeeklyHours     int      `json:"weeklyHours,omitempty" binding:"omitempty,min=1,max=80"`
+	TimeBudgetHours int      `json:"timeBudgetHours,omitempty" binding:"omitempty,min=1,max=2000"`
+	Language        string   `json:"language,omitempty" binding:"omitempty,oneof=pt-BR en"`
+	FocusTags       []string `json:"focusTags,omitempty" binding:"omitempty,max=10,dive,min=2,max=40"`
+
+	// Calibration is filled in by the server, whatever the client sent.
+	Calibration *CalibrationHints `json:"calibration,omitempty" swaggerignore:"true"`
+
+	// Refresh asks for a new generation rather than a cached one, set by the
+	// server on forced requests.
+	Refresh bool `json:"-"`
 }
@@ -0,0 +1,73 @@
+package dto
+
+// SearchQuery holds the paging and facet filters of a search. Filters on the
+// same facet are OR'ed, different facets are AND'ed.
+type SearchQuery struct {
+	Page       int      `form:"page" binding:"omitempty,min=1"`
+	PageSize   int      `form:"pageSize" binding:"omitempty,min=1,max=100"`
+	Difficulty []string `form:"difficulty" binding:"omitempty,dive,oneof=beginner intermediate advanced mixed"`
+	Tags       []string `form:"tag" binding:"omitempty,max=10"`
+	Time       []string `form:"time" binding:"omitempty,dive,oneof=under-5h 5-20h 20-50h over-50h"`
+	Author     string   `form:"author"`
+}
+
+type SearchResult struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Highlights []string `json:"highlights"`
+	Score      float64  `json:"score"`
+	Difficulty string   `json:"difficulty"`
+	Tags       []string `json:"tags"`
+}
+
+type FacetBucket struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+type SearchFacets struct {
+	Difficulty []FacetBucket `json:"difficulty"`
+	Tags       []FacetBucket `json:"tags"`
+	Time       []FacetBucket `json:"time"`
+	Author     []FacetBucket `json:"author"`
+}
+
+type SearchResults struct {
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"pageSize"`
+	Results  []SearchResult `json:"results"`
+	Facets   SearchFacets   `json:"facets"`
+}
+
+type SuggestQuery struct {
+	Q    string `form:"q" binding:"required,max=100"`
+	Size int    `form:"size" binding:"omitempty,min=1,max=20"`
+}
+
+// SimilarQuery identifies who similar roadmaps are for, their own roadmaps and
+// enrollments are left out.
+type SimilarQuery struct {
+	Email string `form:"email"`
+	Size  int    `form:"size" binding:"omitempty,min=1,max=20"`
+}
+
+type TitleSuggestion struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type Suggestions struct {
+	Titles []TitleSuggestion `json:"titles"`
+	Tags   []string          `json:"tags"`
+}
+
+// IndexDrift lists the roadmap IDs on which Mongo and the search index disagree.
+type IndexDrift struct {
+	Stored   int      `json:"stored"`
+	Indexed  int      `json:"indexed"`
+	Missing  []string `json:"missing"`
+	Extra    []string `json:"extra"`
+	Stale    []string `json:"stale"`
+	Repaired bool     `json:"repaired"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type TagSynonyms struct {
+	Synonyms []string `json:"synonyms" binding:"max=50,dive,min=1,max=40"`
+}
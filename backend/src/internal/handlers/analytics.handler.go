@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/middlewares"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	analyticsService services.AnalyticsService
+	eventRegistry    *services.EventRegistry
+}
+
+func NewAnalyticsHandler(analyticsService services.AnalyticsService, eventRegistry *services.EventRegistry) AnalyticsHandler {
+	return AnalyticsHandler{
+		analyticsService: analyticsService,
+		eventRegistry:    eventRegistry,
+	}
+}
+
+// defaultRange fills in the last 24 hours, by hour, for what the query left out.
+func defaultRange(r *dto.AnalyticsRange) {
+	if r.To.IsZero() {
+		r.To = time.Now()
+	}
+	if r.From.IsZero() {
+		r.From = r.To.Add(-24 * time.Hour)
+	}
+	if r.Interval == "" {
+		r.Interval = dto.IntervalHour
+	}
+}
+
+func (h *AnalyticsHandler) respond(ctx *gin.Context, res any, err error) {
+	if errors.Is(err, constants.ErrAnalyticsQuery) {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// @Summary Get metric series
+// @Description Count, sum, avg and p50/p95/p99 of a metric per time bucket, optionally split by a tag
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Param name query string true "Metric name, e.g. http_request"
+// @Param from query string false "RFC 3339, defaults to 24h before to"
+// @Param to query string false "RFC 3339, defaults to now"
+// @Param interval query string false "minute, hour (default), day, week or month"
+// @Param tag query []string false "key:value filters"
+// @Param groupBy query string false "Tag key to split by"
+// @Success 200 {array} dto.MetricBucket
+// @Failure 400 string BadRequest
+// @Failure 401 string Unauthorized
+// @Failure 502 string BadGateway
+// @Router /v1/admin/analytics/metrics [GET]
+func (h *AnalyticsHandler) Metrics(ctx *gin.Context) {
+	var q dto.MetricQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+	defaultRange(&q.AnalyticsRange)
+
+	buckets, err := h.analyticsService.MetricSeries(ctx, q)
+	h.respond(ctx, buckets, err)
+}
+
+// @Summary Get event counts
+// @Description Number of events per name and time bucket
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Param name query []string false "Event names, all if empty"
+// @Param from query string false "RFC 3339, defaults to 24h before to"
+// @Param to query string false "RFC 3339, defaults to now"
+// @Param interval query string false "minute, hour (default), day, week or month"
+// @Success 200 {array} dto.EventBucket
+// @Failure 400 string BadRequest
+// @Failure 401 string Unauthorized
+// @Failure 502 string BadGateway
+// @Router /v1/admin/analytics/events [GET]
+func (h *AnalyticsHandler) Events(ctx *gin.Context) {
+	var q dto.EventCountQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+	defaultRange(&q.AnalyticsRange)
+
+	buckets, err := h.analyticsService.EventCounts(ctx, q)
+	h.respond(ctx, buckets, err)
+}
+
+// @Summary Get distinct users
+// @Description Number of distinct users behind events per time bucket
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Param name query []string false "Event names, all if empty"
+// @Param from query string false "RFC 3339, defaults to 24h before to"
+// @Param to query string false "RFC 3339, defaults to now"
+// @Param interval query string false "minute, hour (default), day, week or month"
+// @Success 200 {array} dto.DistinctUsersBucket
+// @Failure 400 string BadRequest
+// @Failure 401 string Unauthorized
+// @Failure 502 string BadGateway
+// @Router /v1/admin/analytics/users [GET]
+func (h *AnalyticsHandler) Users(ctx *gin.Context) {
+	var q dto.DistinctUsersQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+	defaultRange(&q.AnalyticsRange)
+
+	buckets, err := h.analyticsService.DistinctUsers(ctx, q)
+	h.respond(ctx, buckets, err)
+}
+
+// @Summary Get metric rollups
+// @Description Hourly or daily downsampled metrics, one per bucket and set of tags, kept past the raw retention
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Param name query string true "Metric name, e.g. api_call_duration"
+// @Param resolution query string false "hour (default) or day"
+// @Param from query string false "RFC 3339, defaults to 7 days before to"
+// @Param to query string false "RFC 3339, defaults to now"
+// @Param tag query []string false "key:value filters"
+// @Success 200 {array} models.MetricRollup
+// @Failure 400 string BadRequest
+// @Failure 401 string Unauthorized
+// @Failure 502 string BadGateway
+// @Router /v1/admin/analytics/rollups [GET]
+func (h *AnalyticsHandler) Rollups(ctx *gin.Context) {
+	var q dto.RollupQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-7 * 24 * time.Hour)
+	}
+	if q.Resolution == "" {
+		q.Resolution = string(models.RollupHour)
+	}
+
+	rollups, err := h.analyticsService.MetricRollups(ctx, q)
+	h.respond(ctx, rollups, err)
+}
+
+// @Summary Get event catalog
+// @Description Every product event that is tracked, with the version and properties of its schema
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.EventSchema
+// @Failure 401 string Unauthorized
+// @Router /v1/admin/analytics/catalog [GET]
+func (h *AnalyticsHandler) Catalog(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, h.eventRegistry.Catalog())
+}
+
+// RegisterRoutes registers the admin analytics endpoints
+func (h *AnalyticsHandler) RegisterRoutes(rg *gin.RouterGroup, authMiddleware middlewares.AuthMiddleware) {
+	g := rg.Group("/admin/analytics", authMiddleware.RequireAdmin())
+	g.GET("/metrics", h.Metrics)
+	g.GET("/events", h.Events)
+	g.GET("/users", h.Users)
+	g.GET("/rollups", h.Rollups)
+	g.GET("/catalog", h.Catalog)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/middlewares"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CalibrationHandler struct {
+	calibrationService services.CalibrationService
+}
+
+func NewCalibrationHandler(calibrationService services.CalibrationService) CalibrationHandler {
+	return CalibrationHandler{
+		calibrationService: calibrationService,
+	}
+}
+
+// @Summary Get estimate calibration
+// @Description How much longer than estimated learners take on the nodes of each topic, "*" being every topic, most measured first
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.TopicCalibration
+// @Failure 401 string Unauthorized
+// @Failure 502 string BadGateway
+// @Router /v1/admin/analytics/calibration [GET]
+func (h *CalibrationHandler) Topics(ctx *gin.Context) {
+	calibrations, err := h.calibrationService.Topics(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, calibrations)
+}
+
+// RegisterRoutes registers calibration endpoints
+func (h *CalibrationHandler) RegisterRoutes(rg *gin.RouterGroup, authMiddleware middlewares.AuthMiddleware) {
+	g := rg.Group("/admin/analytics", authMiddleware.RequireAdmin())
+	g.GET("/calibration", h.Topics)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CreatorHandler struct {
+	creatorService services.CreatorService
+	roadmapService services.RoadmapService
+}
+
+func NewCreatorHandler(creatorService services.CreatorService, roadmapService services.RoadmapService) CreatorHandler {
+	return CreatorHandler{
+		creatorService: creatorService,
+		roadmapService: roadmapService,
+	}
+}
+
+// @Summary Get roadmap analytics
+// @Description Views, unique viewers, enrollments, completion rate, time per node against its estimate, upvotes and forks of one of the user's roadmaps
+// @Tags Me
+// @Produce json
+// @Param roadmapId path string true "Roadmap ID"
+// @Param email query string true "User Email"
+// @Param days query int false "Number of days of daily figures, up to 365, defaults to 30"
+// @Success 200 {object} dto.CreatorAnalytics
+// @Failure 400 string BadRequest
+// @Failure 403 string Forbidden
+// @Failure 404 string NotFound
+// @Failure 502 string BadGateway
+// @Router /v1/me/roadmaps/{roadmapId}/analytics [GET]
+func (h *CreatorHandler) Analytics(ctx *gin.Context) {
+	var q dto.CreatorAnalyticsQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+	if q.Days == 0 {
+		q.Days = 30
+	}
+
+	roadmap, err := h.roadmapService.Roadmap(ctx, ctx.Param("roadmapId"))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	if roadmap.UserEmail != q.Email {
+		ctx.String(http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	analytics, err := h.creatorService.Analytics(ctx, roadmap, q.Days)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, analytics)
+}
+
+// RegisterRoutes registers the endpoints of roadmap authors
+func (h *CreatorHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/me/roadmaps/:roadmapId/analytics", h.Analytics)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/middlewares"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type DedupHandler struct {
+	dedupService services.DedupService
+}
+
+func NewDedupHandler(dedupService services.DedupService) DedupHandler {
+	return DedupHandler{
+		dedupService: dedupService,
+	}
+}
+
+// @Summary Get roadmap duplicates
+// @Description Near-identical roadmaps, for the UI to point at an existing one
+// @Tags Roadmap
+// @Produce json
+// @Param roadmapId path string true "Roadmap ID"
+// @Success 200 {array} dto.DuplicateRoadmap
+// @Failure 400 string BadRequest
+// @Failure 502 string BadGateway
+// @Router /v1/roadmaps/{roadmapId}/duplicates [GET]
+func (h *DedupHandler) Duplicates(ctx *gin.Context) {
+	dups, err := h.dedupService.Duplicates(ctx, ctx.Param("roadmapId"))
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, dups)
+}
+
+// @Summary Get duplicate clusters
+// @Description Groups of near-identical roadmaps, largest first, to be merged or hidden
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} dto.DuplicateCluster
+// @Failure 401 string Unauthorized
+// @Failure 502 string BadGateway
+// @Router /v1/admin/duplicates [GET]
+func (h *DedupHandler) Clusters(ctx *gin.Context) {
+	clusters, err := h.dedupService.Clusters(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, clusters)
+}
+
+// RegisterRoutes registers duplicate detection endpoints
+func (h *DedupHandler) RegisterRoutes(rg *gin.RouterGroup, authMiddleware middlewares.AuthMiddleware) {
+	rg.GET("/roadmaps/:roadmapId/duplicates", h.Duplicates)
+	rg.GET("/admin/duplicates", authMiddleware.RequireAdmin(), h.Clusters)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/middlewares"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type FunnelHandler struct {
+	funnelService services.FunnelService
+}
+
+func NewFunnelHandler(funnelService services.FunnelService) FunnelHandler {
+	return FunnelHandler{
+		funnelService: funnelService,
+	}
+}
+
+// @Summary Get learning funnel
+// @Description How far the furthest learner of each recently generated roadmap went: generated, enrolled, first node done, module done, completed
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} models.Funnel
+// @Failure 401 string Unauthorized
+// @Failure 404 string NotFound
+// @Failure 502 string BadGateway
+// @Router /v1/admin/analytics/funnel [GET]
+func (h *FunnelHandler) Funnel(ctx *gin.Context) {
+	funnel, err := h.funnelService.Funnel(ctx)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, funnel)
+}
+
+// @Summary Get retention cohorts
+// @Description Users by week of first activity, and the share of them active each week after
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} models.CohortReport
+// @Failure 401 string Unauthorized
+// @Failure 404 string NotFound
+// @Failure 502 string BadGateway
+// @Router /v1/admin/analytics/cohorts [GET]
+func (h *FunnelHandler) Cohorts(ctx *gin.Context) {
+	report, err := h.funnelService.Cohorts(ctx)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// @Summary Get roadmap funnel
+// @Description How far the learners of a roadmap went, node by node
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Param roadmapId path string true "Roadmap ID"
+// @Success 200 {object} models.RoadmapFunnel
+// @Failure 401 string Unauthorized
+// @Failure 404 string NotFound
+// @Failure 502 string BadGateway
+// @Router /v1/admin/analytics/funnel/roadmaps/{roadmapId} [GET]
+func (h *FunnelHandler) AdminRoadmapFunnel(ctx *gin.Context) {
+	funnel, err := h.funnelService.RoadmapFunnel(ctx, ctx.Param("roadmapId"))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, funnel)
+}
+
+// @Summary Get roadmap funnel as its author
+// @Description How far the learners of one of the user's roadmaps went, node by node
+// @Tags Roadmap
+// @Produce json
+// @Param roadmapId path string true "Roadmap ID"
+// @Param email query string true "User Email"
+// @Success 200 {object} models.RoadmapFunnel
+// @Failure 400 string BadRequest
+// @Failure 403 string Forbidden
+// @Failure 404 string NotFound
+// @Failure 502 string BadGateway
+// @Router /v1/roadmaps/{roadmapId}/funnel [GET]
+func (h *FunnelHandler) RoadmapFunnel(ctx *gin.Context) {
+	email := ctx.Query("email")
+	if email == "" {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	funnel, err := h.funnelService.RoadmapFunnel(ctx, ctx.Param("roadmapId"))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	if funnel.AuthorEmail != email {
+		ctx.String(http.StatusForbidden, "Forbidden")
+		return
+	}
+	ctx.JSON(http.StatusOK, funnel)
+}
+
+// RegisterRoutes registers funnel and cohort endpoints
+func (h *FunnelHandler) RegisterRoutes(rg *gin.RouterGroup, authMiddleware middlewares.AuthMiddleware) {
+	rg.GET("/roadmaps/:roadmapId/funnel", h.RoadmapFunnel)
+
+	g := rg.Group("/admin/analytics", authMiddleware.RequireAdmin())
+	g.GET("/funnel", h.Funnel)
+	g.GET("/funnel/roadmaps/:roadmapId", h.AdminRoadmapFunnel)
+	g.GET("/cohorts", h.Cohorts)
+}
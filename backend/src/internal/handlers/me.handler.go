@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/middlewares"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type MeHandler struct {
+	recommendationService services.RecommendationService
+	userService           services.UserService
+	activityService       services.ActivityService
+}
+
+func NewMeHandler(recommendationService services.RecommendationService, userService services.UserService, activityService services.ActivityService) MeHandler {
+	return MeHandler{
+		recommendationService: recommendationService,
+		userService:           userService,
+		activityService:       activityService,
+	}
+}
+
+// @Summary Get personalized feed
+// @Description Roadmaps ranked from the user's enrollments, completed nodes, skill level and popularity, each with the reason it shows up
+// @Tags Me
+// @Produce json
+// @Param email query string true "User Email"
+// @Param size query int false "Number of roadmaps, up to 50"
+// @Success 200 {object} dto.Feed
+// @Failure 400 string BadRequest
+// @Failure 502 string BadGateway
+// @Router /v1/me/feed [GET]
+func (h *MeHandler) Feed(ctx *gin.Context) {
+	var q dto.FeedQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+	if q.Size == 0 {
+		q.Size = 20
+	}
+
+	feed, err := h.recommendationService.Feed(ctx, q)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, feed)
+}
+
+// @Summary Record feed click
+// @Tags Me
+// @Accept json
+// @Param email query string true "User Email"
+// @Param payload body dto.FeedClick true "Clicked item"
+// @Success 200 string OK
+// @Failure 400 string BadRequest
+// @Failure 502 string BadGateway
+// @Router /v1/me/feed/clicks [POST]
+func (h *MeHandler) FeedClick(ctx *gin.Context) {
+	email := ctx.Query("email")
+	var body dto.FeedClick
+	if err := ctx.ShouldBindJSON(&body); err != nil || email == "" {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	if err := h.recommendationService.RecordClick(ctx, email, body); err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.String(http.StatusOK, "OK")
+}
+
+// @Summary Set skill level
+// @Tags Me
+// @Accept json
+// @Param email query string true "User Email"
+// @Param payload body dto.SkillLevel true "Skill level"
+// @Success 200 string OK
+// @Failure 400 string BadRequest
+// @Failure 502 string BadGateway
+// @Router /v1/me/skill-level [PUT]
+func (h *MeHandler) SetSkillLevel(ctx *gin.Context) {
+	email := ctx.Query("email")
+	var body dto.SkillLevel
+	if err := ctx.ShouldBindJSON(&body); err != nil || email == "" {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	if err := h.userService.SetSkillLevel(ctx, email, body.SkillLevel); err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.String(http.StatusOK, "OK")
+}
+
+// @Summary Get activity
+// @Description The user's active days, with what they did, and their current streak
+// @Tags Me
+// @Produce json
+// @Param email query string true "User Email"
+// @Param days query int false "Number of days back, up to 365, defaults to 30"
+// @Success 200 {object} dto.ActivitySummary
+// @Failure 400 string BadRequest
+// @Failure 502 string BadGateway
+// @Router /v1/me/activity [GET]
+func (h *MeHandler) Activity(ctx *gin.Context) {
+	var q dto.ActivityQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+	if q.Days == 0 {
+		q.Days = 30
+	}
+
+	now := time.Now()
+	days, err := h.activityService.Days(ctx, q.Email, now.AddDate(0, 0, -q.Days))
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	activeDays := make([]time.Time, len(days))
+	for i, day := range days {
+		activeDays[i] = day.Day
+	}
+	ctx.JSON(http.StatusOK, dto.ActivitySummary{
+		Streak: services.ActivityStreak(activeDays, now),
+		Days:   days,
+	})
+}
+
+// RegisterRoutes registers the endpoints of the current user
+func (h *MeHandler) RegisterRoutes(rg *gin.RouterGroup, activityMiddleware middlewares.ActivityMiddleware) {
+	g := rg.Group("/me")
+	g.GET("/feed", activityMiddleware.Track(models.ActivityVisited), h.Feed)
+	g.POST("/feed/clicks", h.FeedClick)
+	g.PUT("/skill-level", activityMiddleware.Track(models.ActivityVisited), h.SetSkillLevel)
+	g.GET("/activity", h.Activity)
+}
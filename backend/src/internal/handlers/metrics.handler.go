@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type MetricsHandler struct {
+	handler http.Handler
+}
+
+func NewMetricsHandler(registry *prometheus.Registry) MetricsHandler {
+	return MetricsHandler{
+		handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}),
+	}
+}
+
+// @Summary Get metrics
+// @Description Process metrics in the Prometheus text format
+// @Tags Admin
+// @Produce plain
+// @Security Bearer
+// @Success 200 string Metrics
+// @Failure 401 string Unauthorized
+// @Router /metrics [GET]
+func (h *MetricsHandler) Metrics(ctx *gin.Context) {
+	h.handler.ServeHTTP(ctx.Writer, ctx.Request)
+}
+
+// RegisterRoutes registers the metrics endpoint
+func (h *MetricsHandler) RegisterRoutes(rg *gin.RouterGroup, authMiddleware middlewares.AuthMiddleware) {
+	rg.GET("/metrics", authMiddleware.RequireMetricsToken(), h.Metrics)
+}
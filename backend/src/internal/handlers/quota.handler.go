@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/middlewares"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type QuotaHandler struct {
+	quotaService services.QuotaService
+}
+
+func NewQuotaHandler(quotaService services.QuotaService) QuotaHandler {
+	return QuotaHandler{
+		quotaService: quotaService,
+	}
+}
+
+// @Summary Get quota override
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Param email path string true "User Email"
+// @Success 200 {object} models.QuotaOverride
+// @Failure 401 string Unauthorized
+// @Failure 404 string NotFound
+// @Failure 502 string BadGateway
+// @Router /v1/admin/quotas/{email} [GET]
+func (h *QuotaHandler) Override(ctx *gin.Context) {
+	override, err := h.quotaService.Override(ctx, ctx.Param("email"))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, override)
+}
+
+// @Summary Set quota override
+// @Description Replaces the plan limits of a user. A dailyLimit of 0 keeps the plan cap.
+// @Tags Admin
+// @Accept json
+// @Security Bearer
+// @Param email path string true "User Email"
+// @Param payload body dto.QuotaOverride true "Override"
+// @Success 200 string OK
+// @Failure 400 string BadRequest
+// @Failure 401 string Unauthorized
+// @Failure 502 string BadGateway
+// @Router /v1/admin/quotas/{email} [PUT]
+func (h *QuotaHandler) SetOverride(ctx *gin.Context) {
+	var body dto.QuotaOverride
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	err := h.quotaService.SetOverride(ctx, models.QuotaOverride{
+		Email:      ctx.Param("email"),
+		DailyLimit: body.DailyLimit,
+		Unlimited:  body.Unlimited,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.String(http.StatusOK, "OK")
+}
+
+// @Summary Delete quota override
+// @Tags Admin
+// @Security Bearer
+// @Param email path string true "User Email"
+// @Success 200 string OK
+// @Failure 401 string Unauthorized
+// @Failure 502 string BadGateway
+// @Router /v1/admin/quotas/{email} [DELETE]
+func (h *QuotaHandler) DeleteOverride(ctx *gin.Context) {
+	err := h.quotaService.DeleteOverride(ctx, ctx.Param("email"))
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.String(http.StatusOK, "OK")
+}
+
+// RegisterRoutes registers quota admin endpoints
+func (h *QuotaHandler) RegisterRoutes(rg *gin.RouterGroup, authMiddleware middlewares.AuthMiddleware) {
+	g := rg.Group("/admin/quotas", authMiddleware.RequireAdmin())
+	g.GET("/:email", h.Override)
+	g.PUT("/:email", h.SetOverride)
+	g.DELETE("/:email", h.DeleteOverride)
+}
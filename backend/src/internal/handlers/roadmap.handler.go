@@ -1,62 +1,94 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/middlewares"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/logger"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type RoadmapHandler struct {
-	roadmapService services.RoadmapService
-	genService     services.GenService
-	searchService  services.ElasticService
+	roadmapService      services.RoadmapService
+	genService          services.GenService
+	searchService       services.SearchService
+	promptScreenService services.PromptScreenService
+	enrollmentService   services.EnrollmentService
+	trendingService     services.TrendingService
+	tagService          services.TagService
+	dedupService        services.DedupService
+	calibrationService  services.CalibrationService
 }
 
-func NewRoadmapHandler(roadmapService services.RoadmapService, genService services.GenService, searchService services.ElasticService) RoadmapHandler {
+func NewRoadmapHandler(roadmapService services.RoadmapService, genService services.GenService, searchService services.SearchService, promptScreenService services.PromptScreenService, enrollmentService services.EnrollmentService, trendingService services.TrendingService, tagService services.TagService, dedupService services.DedupService, calibrationService services.CalibrationService) RoadmapHandler {
 	return RoadmapHandler{
-		roadmapService: roadmapService,
-		genService:     genService,
-		searchService:  searchService,
+		roadmapService:      roadmapService,
+		genService:          genService,
+		searchService:       searchService,
+		promptScreenService: promptScreenService,
+		enrollmentService:   enrollmentService,
+		trendingService:     trendingService,
+		tagService:          tagService,
+		dedupService:        dedupService,
+		calibrationService:  calibrationService,
+	}
+}
+
+func roadmapToDto(roadmap models.Roadmap) dto.Roadmap {
+	return dto.Roadmap{
+		ID:                    roadmap.ID.Hex(),
+		Upvotes:               roadmap.Upvotes,
+		UserEmail:             roadmap.UserEmail,
+		SchemaVersion:         roadmap.SchemaVersion,
+		Title:                 roadmap.Title,
+		Description:           roadmap.Description,
+		Difficulty:            roadmap.Difficulty,
+		EstimatedTotalMinutes: roadmap.EstimatedTotalMinutes,
+		Tags:                  roadmap.Tags,
+		Modules:               roadmap.Modules,
+		Nodes:                 roadmap.Nodes,
 	}
 }
 
 // @Summary Get all roadmaps
 // @Tags Roadmap
 // @Produce json
+// @Param sort query string false "Order: upvotes (default) or trending"
 // @Success 200 {array} dto.Roadmap
+// @Failure 400 string BadRequest
 // @Failure 502 string BadGateway
 // @Router /v1/roadmaps [GET]
 func (h *RoadmapHandler) Roadmaps(ctx *gin.Context) {
-	roadmaps, err := h.roadmapService.Roadmaps(ctx)
+	var q dto.ListQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	roadmaps, err := h.roadmapService.Roadmaps(ctx, q.Sort)
 	if err != nil {
 		ctx.String(http.StatusBadGateway, "BadGateway")
 		return
 	}
 	rets := []dto.Roadmap{}
 	for _, roadmap := range roadmaps {
-		rets = append(rets, dto.Roadmap{
-			ID:                    roadmap.ID.Hex(),
-			Upvotes:               roadmap.Upvotes,
-			UserEmail:             roadmap.UserEmail,
-			SchemaVersion:         roadmap.SchemaVersion,
-			Title:                 roadmap.Title,
-			Description:           roadmap.Description,
-			Difficulty:            roadmap.Difficulty,
-			EstimatedTotalMinutes: roadmap.EstimatedTotalMinutes,
-			Tags:                  roadmap.Tags,
-			Modules:               roadmap.Modules,
-			Nodes:                 roadmap.Nodes,
-		})
+		rets = append(rets, roadmapToDto(roadmap))
 	}
 	ctx.JSON(http.StatusOK, rets)
 }
 
 // @Summary Get roadmap by ID
+// @Description Includes the community-measured study time once learners went through some of its nodes
 // @Tags Roadmap
 // @Produce json
 // @Param roadmapId path string true "Roadmap ID"
@@ -71,115 +103,586 @@ func (h *RoadmapHandler) Roadmap(ctx *gin.Context) {
 		ctx.String(http.StatusNotFound, "NotFound")
 		return
 	}
-	ret := dto.Roadmap{
-		ID:                    roadmap.ID.Hex(),
-		Upvotes:               roadmap.Upvotes,
-		UserEmail:             roadmap.UserEmail,
-		SchemaVersion:         roadmap.SchemaVersion,
-		Title:                 roadmap.Title,
-		Description:           roadmap.Description,
-		Difficulty:            roadmap.Difficulty,
-		EstimatedTotalMinutes: roadmap.EstimatedTotalMinutes,
-		Tags:                  roadmap.Tags,
-		Modules:               roadmap.Modules,
-		Nodes:                 roadmap.Nodes,
+
+	// a lost view only slightly underestimates the trending score
+	if err := h.trendingService.RecordView(ctx, roadmapId); err != nil {
+		slog.ErrorContext(ctx, err.Error())
+	}
+
+	ret := roadmapToDto(roadmap)
+	// the estimates alone are still worth showing
+	ret.Measured, err = h.calibrationService.MeasuredTime(ctx, roadmap)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
 	}
 	ctx.JSON(http.StatusOK, ret)
 }
 
+// @Summary Get trending roadmaps
+// @Description Roadmaps ranked by votes, enrollments and views over the window, decayed by age
+// @Tags Roadmap
+// @Produce json
+// @Param window query string false "day, week (default) or month"
+// @Param size query int false "Number of roadmaps, up to 100"
+// @Success 200 {array} dto.Roadmap
+// @Failure 400 string BadRequest
+// @Failure 502 string BadGateway
+// @Router /v1/roadmaps/trending [GET]
+func (h *RoadmapHandler) Trending(ctx *gin.Context) {
+	var q dto.TrendingQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+	if q.Window == "" {
+		q.Window = models.TrendingWeek
+	}
+	if q.Size == 0 {
+		q.Size = 20
+	}
+
+	roadmaps, err := h.trendingService.Trending(ctx, q.Window, q.Size)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	rets := []dto.Roadmap{}
+	for _, roadmap := range roadmaps {
+		rets = append(rets, roadmapToDto(roadmap))
+	}
+	ctx.JSON(http.StatusOK, rets)
+}
+
 // @Summary Get roadmaps from user
 // @Tags Roadmap
 // @Produce json
 // @Param email query string true "User Email"
+// @Param sort query string false "Order: upvotes (default) or trending"
 // @Success 200 {array} dto.Roadmap
+// @Failure 400 string BadRequest
 // @Failure 502 string BadGateway
 // @Router /v1/roadmaps/user [GET]
 func (h *RoadmapHandler) RoadmapsFromUser(ctx *gin.Context) {
 	email := ctx.Query("email")
-	roadmaps, err := h.roadmapService.RoadmapsFromUser(ctx, email)
+	var q dto.ListQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	roadmaps, err := h.roadmapService.RoadmapsFromUser(ctx, email, q.Sort)
 	if err != nil {
 		ctx.String(http.StatusBadGateway, "BadGateway")
 		return
 	}
 	rets := []dto.Roadmap{}
 	for _, roadmap := range roadmaps {
-		rets = append(rets, dto.Roadmap{
-			ID:                    roadmap.ID.Hex(),
-			Upvotes:               roadmap.Upvotes,
-			UserEmail:             roadmap.UserEmail,
-			SchemaVersion:         roadmap.SchemaVersion,
-			Title:                 roadmap.Title,
-			Description:           roadmap.Description,
-			Difficulty:            roadmap.Difficulty,
-			EstimatedTotalMinutes: roadmap.EstimatedTotalMinutes,
-			Tags:                  roadmap.Tags,
-			Modules:               roadmap.Modules,
-			Nodes:                 roadmap.Nodes,
-		})
+		rets = append(rets, roadmapToDto(roadmap))
 	}
 	ctx.JSON(http.StatusOK, rets)
 }
 
 // @Summary Insert Roadmap
 // @Tags Roadmap
+// @Accept json
 // @Produce json
 // @Param email query string true "User Email"
-// @Param prompt query string true "The Prompt"
+// @Param force query bool false "Generate even if a roadmap for the same, or a near-identical, prompt and options already exists"
+// @Param payload body dto.GenerateRoadmapRequest true "Prompt and generation options"
 // @Success 200 string RoadmapID
+// @Header 200 {string} X-Similar-Roadmap "ID of the most similar existing roadmap, if any"
+// @Failure 400 string BadRequest
+// @Failure 409 {object} dto.Roadmap "Existing roadmap for the same, or a near-identical, prompt and options"
+// @Failure 422 {object} dto.PromptRejection
+// @Failure 429 string TooManyRequests
 // @Failure 502 string BadGateway
 // @Router /v1/roadmaps [POST]
 func (h *RoadmapHandler) Insert(ctx *gin.Context) {
 	email := ctx.Query("email")
-	prompt := ctx.Query("prompt")
+	force := ctx.Query("force") == "true"
 
-	slog.Info(fmt.Sprintf("insert: %s: %s", email, prompt))
+	var body dto.GenerateRoadmapRequest
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+	prompt := body.Prompt
 
-	roadmap, err := h.genService.GenerateRoadmap(ctx, prompt)
-	if err != nil {
-		slog.Error(err.Error())
-		ctx.String(http.StatusBadGateway, "BadGateway")
+	slog.InfoContext(ctx, fmt.Sprintf("insert: %s: %s", logger.RedactPII(email), logger.RedactPII(prompt)))
+
+	if !h.screen(ctx, prompt) {
 		return
 	}
 
-	rd, err := h.roadmapService.Insert(ctx, email, roadmap)
+	// a forced generation is a new one, not the cached copy of the existing roadmap
+	body.Refresh = force
+	if !force {
+		existing, err := h.roadmapService.RoadmapByPrompt(ctx, body)
+		if err == nil {
+			ctx.JSON(http.StatusConflict, roadmapToDto(existing))
+			return
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			slog.ErrorContext(ctx, err.Error())
+		}
+	}
+
+	roadmap, err := h.genService.GenerateRoadmap(ctx, body)
 	if err != nil {
-		slog.Error(err.Error())
+		slog.ErrorContext(ctx, err.Error())
 		ctx.String(http.StatusBadGateway, "BadGateway")
 		return
 	}
 
-	err = h.searchService.InsertRoadmap(ctx, rd, prompt)
+	roadmap.Tags = h.normalizeTags(ctx, roadmap.Tags)
+	rd, err := h.roadmapService.Insert(ctx, email, body, roadmap)
 	if err != nil {
-		slog.Error(err.Error())
+		slog.ErrorContext(ctx, err.Error())
 		ctx.String(http.StatusBadGateway, "BadGateway")
 		return
 	}
-	ctx.String(http.StatusOK, roadmap.ID)
+	h.updateTagUsage(ctx, nil, rd.Tags)
+	if dups := h.flagDuplicates(ctx, rd); len(dups) > 0 {
+		ctx.Header("X-Similar-Roadmap", dups[0].RoadmapID.Hex())
+	}
+
+	// the search index picks the roadmap up from the sync pipeline
+	ctx.String(http.StatusOK, rd.ID.Hex())
 }
 
 // @Summary Searches Roadmap
 // @Tags Roadmap
 // @Produce json
 // @Param query path string true "Search query"
-// @Success 200 string RoadmapID
+// @Param page query int false "Page, starting at 1"
+// @Param pageSize query int false "Results per page, up to 100"
+// @Param difficulty query []string false "Difficulty filter" collectionFormat(multi)
+// @Param tag query []string false "Tag filter" collectionFormat(multi)
+// @Param time query []string false "Estimated time filter: under-5h, 5-20h, 20-50h, over-50h" collectionFormat(multi)
+// @Param author query string false "Author email filter"
+// @Success 200 {object} dto.SearchResults
+// @Failure 400 string BadRequest
 // @Failure 502 string BadGateway
 // @Router /v1/roadmaps/search/{query} [GET]
 func (h *RoadmapHandler) Search(ctx *gin.Context) {
 	query := ctx.Param("query")
-	s, err := h.searchService.SearchRoadmaps(ctx, query)
+
+	var q dto.SearchQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.PageSize == 0 {
+		q.PageSize = 20
+	}
+
+	results, err := h.searchService.SearchRoadmaps(ctx, query, q)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, results)
+}
+
+// @Summary Suggest Roadmaps
+// @Description Completes a partial query into roadmap titles and tags, most upvoted first
+// @Tags Roadmap
+// @Produce json
+// @Param q query string true "Partial query"
+// @Param size query int false "Suggestions per kind, up to 20"
+// @Success 200 {object} dto.Suggestions
+// @Failure 400 string BadRequest
+// @Failure 502 string BadGateway
+// @Router /v1/roadmaps/suggest [GET]
+func (h *RoadmapHandler) Suggest(ctx *gin.Context) {
+	var q dto.SuggestQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+	if q.Size == 0 {
+		q.Size = 5
+	}
+
+	searchCtx, cancel := context.WithTimeout(ctx, constants.SuggestTimeout)
+	defer cancel()
+	suggestions, err := h.searchService.SuggestRoadmaps(searchCtx, q.Q, q.Size)
+	if err == nil {
+		ctx.JSON(http.StatusOK, suggestions)
+		return
+	}
+	slog.WarnContext(ctx, fmt.Sprintf("search suggest failed, falling back to mongo: %s", err.Error()))
+
+	fallbackCtx, cancelFallback := context.WithTimeout(ctx, constants.SuggestFallbackTimeout)
+	defer cancelFallback()
+	suggestions, err = h.roadmapService.SuggestRoadmaps(fallbackCtx, q.Q, q.Size)
 	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
 		ctx.String(http.StatusBadGateway, "BadGateway")
 		return
 	}
-	ctx.String(200, s)
+	ctx.JSON(http.StatusOK, suggestions)
+}
+
+// @Summary Similar Roadmaps
+// @Description Related roadmaps by title, description, tags and node titles, leaving out the user's own and enrolled ones
+// @Tags Roadmap
+// @Produce json
+// @Param roadmapId path string true "Roadmap ID"
+// @Param email query string false "User Email"
+// @Param size query int false "Number of roadmaps, up to 20"
+// @Success 200 {array} dto.SearchResult
+// @Failure 400 string BadRequest
+// @Failure 404 string NotFound
+// @Failure 502 string BadGateway
+// @Router /v1/roadmaps/{roadmapId}/similar [GET]
+func (h *RoadmapHandler) Similar(ctx *gin.Context) {
+	var q dto.SimilarQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+	if q.Size == 0 {
+		q.Size = 6
+	}
+
+	roadmap, err := h.roadmapService.Roadmap(ctx, ctx.Param("roadmapId"))
+	if err != nil {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+
+	excludedIds := []string{}
+	if q.Email != "" {
+		excludedIds, err = h.enrollmentService.EnrolledRoadmapIds(ctx, q.Email)
+		if err != nil {
+			slog.ErrorContext(ctx, err.Error())
+			ctx.String(http.StatusBadGateway, "BadGateway")
+			return
+		}
+	}
+
+	searchCtx, cancel := context.WithTimeout(ctx, constants.SimilarTimeout)
+	defer cancel()
+	similar, err := h.searchService.SimilarRoadmaps(searchCtx, roadmap, q, excludedIds)
+	if err == nil {
+		ctx.JSON(http.StatusOK, similar)
+		return
+	}
+	slog.WarnContext(ctx, fmt.Sprintf("search similar failed, falling back to tf-idf: %s", err.Error()))
+
+	fallbackCtx, cancelFallback := context.WithTimeout(ctx, constants.SimilarFallbackTimeout)
+	defer cancelFallback()
+	similar, err = h.roadmapService.SimilarRoadmaps(fallbackCtx, roadmap, q, excludedIds)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, similar)
+}
+
+// @Summary Enroll in Roadmap
+// @Tags Roadmap
+// @Param roadmapId path string true "Roadmap ID"
+// @Param email query string true "User Email"
+// @Success 200 string OK
+// @Failure 400 string BadRequest
+// @Failure 404 string NotFound
+// @Failure 502 string BadGateway
+// @Router /v1/roadmaps/{roadmapId}/enroll [POST]
+func (h *RoadmapHandler) Enroll(ctx *gin.Context) {
+	email := ctx.Query("email")
+	if email == "" {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	roadmapId := ctx.Param("roadmapId")
+	if _, err := h.roadmapService.Roadmap(ctx, roadmapId); err != nil {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+
+	if err := h.enrollmentService.Enroll(ctx, email, roadmapId); err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.String(http.StatusOK, "OK")
+}
+
+// @Summary Fork Roadmap
+// @Description Copies a roadmap for the user to follow and edit as their own
+// @Tags Roadmap
+// @Produce json
+// @Param roadmapId path string true "Roadmap ID"
+// @Param email query string true "User Email"
+// @Success 200 {object} dto.Roadmap
+// @Failure 400 string BadRequest
+// @Failure 404 string NotFound
+// @Failure 502 string BadGateway
+// @Router /v1/roadmaps/{roadmapId}/fork [POST]
+func (h *RoadmapHandler) Fork(ctx *gin.Context) {
+	email := ctx.Query("email")
+	if email == "" {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	fork, err := h.roadmapService.Fork(ctx, ctx.Param("roadmapId"), email)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	h.updateTagUsage(ctx, nil, fork.Tags)
+	ctx.JSON(http.StatusOK, roadmapToDto(fork))
+}
+
+// @Summary Complete Node
+// @Description Marks a node as completed, enrolling the user in the roadmap if needed
+// @Tags Roadmap
+// @Param roadmapId path string true "Roadmap ID"
+// @Param nodeId path string true "Node ID"
+// @Param email query string true "User Email"
+// @Success 200 string OK
+// @Failure 400 string BadRequest
+// @Failure 404 string NotFound
+// @Failure 502 string BadGateway
+// @Router /v1/roadmaps/{roadmapId}/nodes/{nodeId}/complete [POST]
+func (h *RoadmapHandler) CompleteNode(ctx *gin.Context) {
+	email := ctx.Query("email")
+	if email == "" {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	roadmapId := ctx.Param("roadmapId")
+	nodeId := ctx.Param("nodeId")
+	roadmap, err := h.roadmapService.Roadmap(ctx, roadmapId)
+	if err != nil || !slices.ContainsFunc(roadmap.Nodes, func(n models.Nodes) bool { return n.ID == nodeId }) {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+
+	if err := h.enrollmentService.CompleteNode(ctx, email, roadmapId, nodeId); err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.String(http.StatusOK, "OK")
+}
+
+// @Summary Refine Roadmap
+// @Description Applies a free-text instruction (e.g. "make it more hands-on") to the whole roadmap
+// @Tags Roadmap
+// @Accept json
+// @Produce json
+// @Param roadmapId path string true "Roadmap ID"
+// @Param email query string true "User Email"
+// @Param payload body dto.RefineRoadmap true "Instruction"
+// @Success 200 {object} dto.Roadmap
+// @Failure 400 string BadRequest
+// @Failure 403 string Forbidden
+// @Failure 404 string NotFound
+// @Failure 422 {object} dto.PromptRejection
+// @Failure 429 string TooManyRequests
+// @Failure 502 string BadGateway
+// @Router /v1/roadmaps/{roadmapId}/refine [POST]
+func (h *RoadmapHandler) Refine(ctx *gin.Context) {
+	var body dto.RefineRoadmap
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	h.refine(ctx, dto.Refinement{
+		Scope:       dto.RefinementScopeRoadmap,
+		Instruction: body.Instruction,
+	})
+}
+
+// @Summary Regenerate Module
+// @Description Regenerates a single module, keeping the rest of the roadmap untouched
+// @Tags Roadmap
+// @Accept json
+// @Produce json
+// @Param roadmapId path string true "Roadmap ID"
+// @Param moduleId path string true "Module ID"
+// @Param email query string true "User Email"
+// @Param payload body dto.RefineTarget false "Optional instruction"
+// @Success 200 {object} dto.Roadmap
+// @Failure 400 string BadRequest
+// @Failure 403 string Forbidden
+// @Failure 404 string NotFound
+// @Failure 422 {object} dto.PromptRejection
+// @Failure 429 string TooManyRequests
+// @Failure 502 string BadGateway
+// @Router /v1/roadmaps/{roadmapId}/modules/{moduleId}/regenerate [POST]
+func (h *RoadmapHandler) RegenerateModule(ctx *gin.Context) {
+	var body dto.RefineTarget
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&body); err != nil {
+			ctx.String(http.StatusBadRequest, "BadRequest")
+			return
+		}
+	}
+
+	h.refine(ctx, dto.Refinement{
+		Scope:       dto.RefinementScopeModule,
+		TargetID:    ctx.Param("moduleId"),
+		Instruction: body.Instruction,
+	})
+}
+
+// @Summary Expand Node
+// @Description Expands a node into sub-nodes, inserted right after it
+// @Tags Roadmap
+// @Accept json
+// @Produce json
+// @Param roadmapId path string true "Roadmap ID"
+// @Param nodeId path string true "Node ID"
+// @Param email query string true "User Email"
+// @Param payload body dto.RefineTarget false "Optional instruction"
+// @Success 200 {object} dto.Roadmap
+// @Failure 400 string BadRequest
+// @Failure 403 string Forbidden
+// @Failure 404 string NotFound
+// @Failure 422 {object} dto.PromptRejection
+// @Failure 429 string TooManyRequests
+// @Failure 502 string BadGateway
+// @Router /v1/roadmaps/{roadmapId}/nodes/{nodeId}/expand [POST]
+func (h *RoadmapHandler) ExpandNode(ctx *gin.Context) {
+	var body dto.RefineTarget
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&body); err != nil {
+			ctx.String(http.StatusBadRequest, "BadRequest")
+			return
+		}
+	}
+
+	h.refine(ctx, dto.Refinement{
+		Scope:       dto.RefinementScopeNode,
+		TargetID:    ctx.Param("nodeId"),
+		Instruction: body.Instruction,
+	})
+}
+
+func (h *RoadmapHandler) refine(ctx *gin.Context, refinement dto.Refinement) {
+	email := ctx.Query("email")
+	roadmapId := ctx.Param("roadmapId")
+	// an empty email would match the roadmaps created without one
+	if email == "" {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	if refinement.Instruction != "" && !h.screen(ctx, refinement.Instruction) {
+		return
+	}
+
+	roadmap, err := h.roadmapService.Roadmap(ctx, roadmapId)
+	if err != nil {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+	if roadmap.UserEmail != email {
+		ctx.String(http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	refined, err := h.genService.RefineRoadmap(ctx, roadmapToDto(roadmap), refinement)
+	if errors.Is(err, constants.ErrRefinementTarget) {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+
+	refined.Tags = h.normalizeTags(ctx, refined.Tags)
+	rd, err := h.roadmapService.Update(ctx, roadmapId, refined)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	h.updateTagUsage(ctx, roadmap.Tags, rd.Tags)
+	h.flagDuplicates(ctx, rd)
+	ctx.JSON(http.StatusOK, roadmapToDto(rd))
+}
+
+// normalizeTags rewrites generated tags to their canonical form. On failure the
+// raw tags are kept, the tag backfill rewrites them later.
+func (h *RoadmapHandler) normalizeTags(ctx context.Context, raw []string) []string {
+	tags, err := h.tagService.Normalize(ctx, raw)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		return raw
+	}
+	return tags
+}
+
+// updateTagUsage adjusts tag counts, a failure is corrected by the next backfill.
+func (h *RoadmapHandler) updateTagUsage(ctx context.Context, before []string, after []string) {
+	if err := h.tagService.UpdateUsage(ctx, before, after); err != nil {
+		slog.ErrorContext(ctx, err.Error())
+	}
+}
+
+// flagDuplicates fingerprints a stored roadmap, a failure is retried by the
+// dedup backfill.
+func (h *RoadmapHandler) flagDuplicates(ctx context.Context, roadmap models.Roadmap) []models.DuplicateMatch {
+	dups, err := h.dedupService.Flag(ctx, roadmap)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+	}
+	return dups
+}
+
+// screen runs prompt through the screening pipeline, answering the request
+// and returning false if it was rejected.
+func (h *RoadmapHandler) screen(ctx *gin.Context, prompt string) bool {
+	verdict, err := h.promptScreenService.Screen(ctx, prompt)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return false
+	}
+	if !verdict.Allowed {
+		ctx.JSON(http.StatusUnprocessableEntity, dto.PromptRejection{Reasons: verdict.Reasons})
+		return false
+	}
+	return true
 }
 
 // RegisterRoutes registers roadmap endpoints
-func (h *RoadmapHandler) RegisterRoutes(rg *gin.RouterGroup, telemetryMiddleware middlewares.TelemetryMiddleware) {
+func (h *RoadmapHandler) RegisterRoutes(rg *gin.RouterGroup, activityMiddleware middlewares.ActivityMiddleware, quotaMiddleware middlewares.QuotaMiddleware) {
 	g := rg.Group("/roadmaps")
-	g.GET("", telemetryMiddleware.LogUser(), h.Roadmaps)
-	g.GET("/:roadmapId", telemetryMiddleware.LogUser(), h.Roadmap)
-	g.GET("/user", telemetryMiddleware.LogUser(), h.RoadmapsFromUser)
-	g.POST("", telemetryMiddleware.LogUser(), h.Insert)
-	g.GET("/search/:query", telemetryMiddleware.LogUser(), h.Search)
+	g.GET("", activityMiddleware.Track(models.ActivityVisited), h.Roadmaps)
+	g.GET("/:roadmapId", activityMiddleware.Track(models.ActivityViewedRoadmap), h.Roadmap)
+	g.GET("/user", activityMiddleware.Track(models.ActivityVisited), h.RoadmapsFromUser)
+	g.GET("/trending", h.Trending)
+	g.POST("", activityMiddleware.Track(models.ActivityGenerated), quotaMiddleware.LimitGenerations(), h.Insert)
+	g.GET("/search/:query", activityMiddleware.Track(models.ActivitySearched), h.Search)
+	g.GET("/suggest", h.Suggest)
+	g.GET("/:roadmapId/similar", activityMiddleware.Track(models.ActivityVisited), h.Similar)
+	g.POST("/:roadmapId/enroll", activityMiddleware.Track(models.ActivityEnrolled), h.Enroll)
+	g.POST("/:roadmapId/fork", activityMiddleware.Track(models.ActivityForked), h.Fork)
+	g.POST("/:roadmapId/nodes/:nodeId/complete", activityMiddleware.Track(models.ActivityCompletedNode), h.CompleteNode)
+	g.POST("/:roadmapId/refine", activityMiddleware.Track(models.ActivityGenerated), quotaMiddleware.LimitGenerations(), h.Refine)
+	g.POST("/:roadmapId/modules/:moduleId/regenerate", activityMiddleware.Track(models.ActivityGenerated), quotaMiddleware.LimitGenerations(), h.RegenerateModule)
+	g.POST("/:roadmapId/nodes/:nodeId/expand", activityMiddleware.Track(models.ActivityGenerated), quotaMiddleware.LimitGenerations(), h.ExpandNode)
 }
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/middlewares"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	searchSyncService services.SearchSyncService
+}
+
+func NewSearchHandler(searchSyncService services.SearchSyncService) SearchHandler {
+	return SearchHandler{
+		searchSyncService: searchSyncService,
+	}
+}
+
+// @Summary Reindex roadmaps
+// @Description Rebuilds the search index from Mongo and swaps it in without downtime
+// @Tags Admin
+// @Security Bearer
+// @Success 200 string OK
+// @Failure 401 string Unauthorized
+// @Failure 409 string Conflict
+// @Failure 502 string BadGateway
+// @Router /v1/admin/search/reindex [POST]
+func (h *SearchHandler) Reindex(ctx *gin.Context) {
+	err := h.searchSyncService.Reindex(ctx)
+	if errors.Is(err, constants.ErrReindexRunning) {
+		ctx.String(http.StatusConflict, "Conflict")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.String(http.StatusOK, "OK")
+}
+
+// @Summary Get search index drift
+// @Description Lists the roadmaps missing from, extra in or stale in the search index
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} dto.IndexDrift
+// @Failure 401 string Unauthorized
+// @Failure 502 string BadGateway
+// @Router /v1/admin/search/drift [GET]
+func (h *SearchHandler) Drift(ctx *gin.Context) {
+	h.checkConsistency(ctx, false)
+}
+
+// @Summary Repair search index drift
+// @Description Reindexes the missing and stale roadmaps and deletes the extra documents
+// @Tags Admin
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} dto.IndexDrift
+// @Failure 401 string Unauthorized
+// @Failure 502 string BadGateway
+// @Router /v1/admin/search/drift/repair [POST]
+func (h *SearchHandler) RepairDrift(ctx *gin.Context) {
+	h.checkConsistency(ctx, true)
+}
+
+func (h *SearchHandler) checkConsistency(ctx *gin.Context, repair bool) {
+	drift, err := h.searchSyncService.CheckConsistency(ctx, repair)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, drift)
+}
+
+// RegisterRoutes registers search admin endpoints
+func (h *SearchHandler) RegisterRoutes(rg *gin.RouterGroup, authMiddleware middlewares.AuthMiddleware) {
+	g := rg.Group("/admin/search", authMiddleware.RequireAdmin())
+	g.POST("/reindex", h.Reindex)
+	g.GET("/drift", h.Drift)
+	g.POST("/drift/repair", h.RepairDrift)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/middlewares"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type TagHandler struct {
+	tagService     services.TagService
+	roadmapService services.RoadmapService
+}
+
+func NewTagHandler(tagService services.TagService, roadmapService services.RoadmapService) TagHandler {
+	return TagHandler{
+		tagService:     tagService,
+		roadmapService: roadmapService,
+	}
+}
+
+// @Summary Get tags
+// @Description Canonical tags with their synonyms, most used first
+// @Tags Tag
+// @Produce json
+// @Success 200 {array} models.Tag
+// @Failure 502 string BadGateway
+// @Router /v1/tags [GET]
+func (h *TagHandler) Tags(ctx *gin.Context) {
+	tags, err := h.tagService.Tags(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, tags)
+}
+
+// @Summary Get roadmaps by tag
+// @Description Synonyms resolve to their canonical tag
+// @Tags Tag
+// @Produce json
+// @Param slug path string true "Tag slug or synonym"
+// @Param sort query string false "Order: upvotes (default) or trending"
+// @Success 200 {array} dto.Roadmap
+// @Failure 400 string BadRequest
+// @Failure 404 string NotFound
+// @Failure 502 string BadGateway
+// @Router /v1/tags/{slug}/roadmaps [GET]
+func (h *TagHandler) Roadmaps(ctx *gin.Context) {
+	var q dto.ListQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	tag, err := h.tagService.Tag(ctx, ctx.Param("slug"))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		ctx.String(http.StatusNotFound, "NotFound")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+
+	roadmaps, err := h.roadmapService.RoadmapsByTag(ctx, tag, q.Sort)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	rets := []dto.Roadmap{}
+	for _, roadmap := range roadmaps {
+		rets = append(rets, roadmapToDto(roadmap))
+	}
+	ctx.JSON(http.StatusOK, rets)
+}
+
+// @Summary Set tag synonyms
+// @Description Replaces the synonyms of a tag, merging tags listed as synonyms. Run the backfill to rewrite existing roadmaps.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param slug path string true "Canonical tag slug"
+// @Param payload body dto.TagSynonyms true "Synonyms"
+// @Success 200 {object} models.Tag
+// @Failure 400 string BadRequest
+// @Failure 401 string Unauthorized
+// @Failure 502 string BadGateway
+// @Router /v1/admin/tags/{slug}/synonyms [PUT]
+func (h *TagHandler) SetSynonyms(ctx *gin.Context) {
+	var body dto.TagSynonyms
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.String(http.StatusBadRequest, "BadRequest")
+		return
+	}
+
+	tag, err := h.tagService.SetSynonyms(ctx, ctx.Param("slug"), body.Synonyms)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.JSON(http.StatusOK, tag)
+}
+
+// @Summary Backfill tags
+// @Description Rewrites the tags of every roadmap to their canonical form and recounts usage
+// @Tags Admin
+// @Security Bearer
+// @Success 200 string OK
+// @Failure 401 string Unauthorized
+// @Failure 502 string BadGateway
+// @Router /v1/admin/tags/backfill [POST]
+func (h *TagHandler) Backfill(ctx *gin.Context) {
+	if err := h.tagService.Backfill(); err != nil {
+		slog.ErrorContext(ctx, err.Error())
+		ctx.String(http.StatusBadGateway, "BadGateway")
+		return
+	}
+	ctx.String(http.StatusOK, "OK")
+}
+
+// RegisterRoutes registers tag endpoints
+func (h *TagHandler) RegisterRoutes(rg *gin.RouterGroup, authMiddleware middlewares.AuthMiddleware) {
+	g := rg.Group("/tags")
+	g.GET("", h.Tags)
+	g.GET("/:slug/roadmaps", h.Roadmaps)
+
+	admin := rg.Group("/admin/tags", authMiddleware.RequireAdmin())
+	admin.PUT("/:slug/synonyms", h.SetSynonyms)
+	admin.POST("/backfill", h.Backfill)
+}
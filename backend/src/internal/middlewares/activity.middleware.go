@@ -0,0 +1,13 @@
+package middlewares
+
+import (
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ActivityMiddleware defines an interface for user activity tracking middleware.
+type ActivityMiddleware interface {
+	// Track returns a middleware handler function that records an activity of
+	// the given kind for the requesting user once the request succeeded.
+	Track(kind models.ActivityKind) gin.HandlerFunc
+}
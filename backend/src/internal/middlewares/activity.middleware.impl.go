@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ActivityMiddlewareImpl struct {
+	activityService services.ActivityService
+}
+
+func NewActivityMiddleware(activityService services.ActivityService) ActivityMiddleware {
+	return &ActivityMiddlewareImpl{
+		activityService: activityService,
+	}
+}
+
+func (m *ActivityMiddlewareImpl) Track(kind models.ActivityKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		email := c.Query("email")
+		if email == "" || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		err := m.activityService.Record(c.Request.Context(), models.Activity{
+			UserEmail: email,
+			Kind:      kind,
+			RoadmapID: c.Param("roadmapId"),
+			NodeID:    c.Param("nodeId"),
+			Query:     c.Param("query"),
+			Ts:        time.Now(),
+		})
+		if err != nil {
+			slog.ErrorContext(c, err.Error())
+		}
+	}
+}
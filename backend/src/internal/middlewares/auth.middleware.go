@@ -0,0 +1,14 @@
+package middlewares
+
+import "github.com/gin-gonic/gin"
+
+// AuthMiddleware defines an interface for authorization middleware.
+type AuthMiddleware interface {
+	// RequireAdmin returns a middleware handler function that aborts requests
+	// not carrying the admin token as a bearer token.
+	RequireAdmin() gin.HandlerFunc
+
+	// RequireMetricsToken returns a middleware handler function that aborts
+	// requests not carrying the metrics token as a bearer token.
+	RequireMetricsToken() gin.HandlerFunc
+}
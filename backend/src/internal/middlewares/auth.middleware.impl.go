@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthMiddlewareImpl struct {
+	adminToken   string
+	metricsToken string
+}
+
+func NewAuthMiddleware(adminToken string, metricsToken string) AuthMiddleware {
+	return &AuthMiddlewareImpl{
+		adminToken:   adminToken,
+		metricsToken: metricsToken,
+	}
+}
+
+func (m *AuthMiddlewareImpl) RequireAdmin() gin.HandlerFunc {
+	return requireBearer(m.adminToken)
+}
+
+func (m *AuthMiddlewareImpl) RequireMetricsToken() gin.HandlerFunc {
+	return requireBearer(m.metricsToken)
+}
+
+func requireBearer(expected string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		// an empty token disables the routes altogether
+		if expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			c.String(http.StatusUnauthorized, "Unauthorized")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
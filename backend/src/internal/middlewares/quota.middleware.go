@@ -0,0 +1,11 @@
+package middlewares
+
+import "github.com/gin-gonic/gin"
+
+// QuotaMiddleware defines an interface for quota enforcement middleware.
+type QuotaMiddleware interface {
+	// LimitGenerations returns a middleware handler function that consumes one
+	// generation from the caller's quota, answering 429 when it is exhausted.
+	// The generation is refunded when the handler answers with an error.
+	LimitGenerations() gin.HandlerFunc
+}
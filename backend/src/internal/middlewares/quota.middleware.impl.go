@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type QuotaMiddlewareImpl struct {
+	quotaService services.QuotaService
+}
+
+func NewQuotaMiddleware(quotaService services.QuotaService) QuotaMiddleware {
+	return &QuotaMiddlewareImpl{
+		quotaService: quotaService,
+	}
+}
+
+func (m *QuotaMiddlewareImpl) LimitGenerations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email := c.Query("email")
+		if email == "" {
+			c.String(http.StatusUnauthorized, "Unauthorized")
+			c.Abort()
+			return
+		}
+
+		// only as trustworthy as the router's trusted proxies, see TRUSTED_PROXIES
+		decision, err := m.quotaService.AllowGeneration(c.Request.Context(), email, c.ClientIP())
+		if err != nil {
+			// fail open: an unavailable quota store must not take generation down with it
+			slog.Error(err.Error())
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(decision.Reset.Unix(), 10))
+
+		if !decision.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(decision.RetryAfter.Seconds()))))
+			c.String(http.StatusTooManyRequests, "TooManyRequests")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+
+		// rejected requests, and failed generations, do not count
+		if c.Writer.Status() >= http.StatusBadRequest {
+			if err := m.quotaService.RefundGeneration(context.WithoutCancel(c.Request.Context()), decision); err != nil {
+				slog.Error(err.Error())
+			}
+		}
+	}
+}
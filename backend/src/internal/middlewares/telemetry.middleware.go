@@ -9,5 +9,7 @@ type TelemetryMiddleware interface {
 	// telemetry data for API calls, such as request metrics or logging.
 	CollectApiCalls() gin.HandlerFunc
 
-	LogUser() gin.HandlerFunc
+	// Trace returns a middleware handler function that wraps each request in a
+	// server span, continuing the trace of the caller if any.
+	Trace() gin.HandlerFunc
 }
@@ -2,28 +2,59 @@ package middlewares
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/tracing"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type TelemetryMiddlewareImpl struct {
 	telemetryService services.TelemetryService
+	requestDuration  *prometheus.HistogramVec
+	inFlight         prometheus.Gauge
 }
 
-func NewTelemetryMiddleware(telemetryService services.TelemetryService) TelemetryMiddleware {
+func NewTelemetryMiddleware(telemetryService services.TelemetryService, reg prometheus.Registerer) TelemetryMiddleware {
 	return &TelemetryMiddlewareImpl{
 		telemetryService: telemetryService,
+		requestDuration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of API requests, by route template.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		inFlight: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "API requests being served.",
+		}),
 	}
 }
 
 func (m *TelemetryMiddlewareImpl) CollectApiCalls() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
+		m.inFlight.Inc()
+		// handler panics are recovered by gin, the request is over anyway
+		defer m.inFlight.Dec()
 		c.Next()
 		duration := time.Since(start)
 
+		// the route template keeps IDs out of the label values
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := fmt.Sprintf("%d", c.Writer.Status())
+		m.requestDuration.WithLabelValues(c.Request.Method, route, status).Observe(duration.Seconds())
+
 		m.telemetryService.RecordMetric(
 			c.Request.Context(),
 			"api_call_duration",
@@ -31,28 +62,44 @@ func (m *TelemetryMiddlewareImpl) CollectApiCalls() gin.HandlerFunc {
 			map[string]string{
 				"method": c.Request.Method,
 				"path":   c.Request.URL.Path,
-				"status": fmt.Sprintf("%d", c.Writer.Status()),
+				"route":  route,
+				"status": status,
 			},
 		)
 	}
 }
 
-func (m *TelemetryMiddlewareImpl) LogUser() gin.HandlerFunc {
+func (m *TelemetryMiddlewareImpl) Trace() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		email, ok := c.Params.Get("email")
-		if !ok {
-			c.Next()
-			return
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
 		}
 
-		m.telemetryService.RecordEvent(
-			c.Request.Context(),
-			"user_log",
-			map[string]any{
-				"logged": true,
-				"email":  email,
-			},
-			map[string]string{},
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracing.Tracer().Start(
+			ctx,
+			c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+			),
 		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
 	}
 }
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// ActivityKind is what a user did.
+type ActivityKind string
+
+const (
+	ActivityVisited       ActivityKind = "visited" // any other tracked request
+	ActivityViewedRoadmap ActivityKind = "viewed_roadmap"
+	ActivityCompletedNode ActivityKind = "completed_node"
+	ActivitySearched      ActivityKind = "searched"
+	ActivityEnrolled      ActivityKind = "enrolled"
+	ActivityGenerated     ActivityKind = "generated"
+	ActivityForked        ActivityKind = "forked"
+)
+
+// Activity is a single action of a user.
+type Activity struct {
+	UserEmail string       `json:"userEmail" bson:"userEmail"`
+	Kind      ActivityKind `json:"kind" bson:"kind"`
+	RoadmapID string       `json:"roadmapId,omitempty" bson:"roadmapId,omitempty"`
+	NodeID    string       `json:"nodeId,omitempty" bson:"nodeId,omitempty"`
+	Query     string       `json:"query,omitempty" bson:"query,omitempty"`
+	Ts        time.Time    `json:"ts" bson:"ts"`
+}
+
+// ActivityDay sums up the activities of a user over a UTC day, so "was the
+// user active" never has to scan the activities themselves.
+type ActivityDay struct {
+	UserEmail string               `json:"-" bson:"userEmail"`
+	Day       time.Time            `json:"day" bson:"day"`
+	Counts    map[ActivityKind]int `json:"counts" bson:"counts"`
+	Total     int                  `json:"total" bson:"total"`
+	FirstAt   time.Time            `json:"firstAt" bson:"firstAt"`
+	LastAt    time.Time            `json:"lastAt" bson:"lastAt"`
+}
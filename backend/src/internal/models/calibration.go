@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// AllTopics is the Tag of the calibration over every measured node.
+const AllTopics = "*"
+
+// TopicCalibration is how much longer, or shorter, learners take on the nodes
+// of a topic than estimated. A Factor of 1.5 means the estimates are 50% short.
+type TopicCalibration struct {
+	Tag       string    `json:"tag" bson:"_id"`
+	Factor    float64   `json:"factor" bson:"factor"`
+	Nodes     int       `json:"nodes" bson:"nodes"` // measured nodes the factor is the median of
+	Samples   int       `json:"samples" bson:"samples"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+}
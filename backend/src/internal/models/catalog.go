@@ -0,0 +1,36 @@
+package models
+
+// Names of the product events, see the catalog for their properties.
+const (
+	EventPromptRejected = "prompt_rejected"
+	EventFeedImpression = "feed_impression"
+	EventFeedClick      = "feed_click"
+	EventUserActivity   = "user_activity"
+)
+
+// PropertyType is the type of an event property.
+type PropertyType string
+
+const (
+	PropertyString  PropertyType = "string"
+	PropertyStrings PropertyType = "string[]"
+	PropertyInt     PropertyType = "int"
+	PropertyNumber  PropertyType = "number"
+	PropertyBool    PropertyType = "bool"
+)
+
+type PropertySchema struct {
+	Name        string       `json:"name"`
+	Type        PropertyType `json:"type"`
+	Required    bool         `json:"required"`
+	Description string       `json:"description"`
+}
+
+// EventSchema defines an event: its metadata may only hold the listed
+// properties. Changing them means bumping the version.
+type EventSchema struct {
+	Name        string           `json:"name"`
+	Version     int              `json:"version"`
+	Description string           `json:"description"`
+	Properties  []PropertySchema `json:"properties"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RoadmapDay materializes what happened around a roadmap over a UTC day.
+type RoadmapDay struct {
+	RoadmapID     primitive.ObjectID `json:"-" bson:"roadmapId"`
+	Day           time.Time          `json:"day" bson:"day"`
+	Views         int                `json:"views" bson:"views"`
+	UniqueViewers int                `json:"uniqueViewers" bson:"uniqueViewers"` // signed-in viewers only
+	Enrollments   int                `json:"enrollments" bson:"enrollments"`
+	Forks         int                `json:"forks" bson:"forks"`
+	Upvotes       int                `json:"upvotes" bson:"upvotes"` // as of the end of the day
+}
+
+// NodeTiming compares the time learners took on a node with its estimate.
+type NodeTiming struct {
+	NodeID           string  `json:"nodeId" bson:"nodeId"`
+	Title            string  `json:"title" bson:"title"`
+	EstimatedMinutes int     `json:"estimatedMinutes" bson:"estimatedMinutes"`
+	MedianMinutes    float64 `json:"medianMinutes" bson:"medianMinutes"` // 0 without samples
+	Samples          int     `json:"samples" bson:"samples"`
+}
+
+// RoadmapStats are the all-time figures of a roadmap, recomputed periodically.
+type RoadmapStats struct {
+	RoadmapID      primitive.ObjectID `json:"-" bson:"_id"`
+	UniqueViewers  int                `json:"uniqueViewers" bson:"uniqueViewers"` // over the activity retention
+	Enrollments    int                `json:"enrollments" bson:"enrollments"`
+	Completed      int                `json:"completed" bson:"completed"`
+	CompletionRate float64            `json:"completionRate" bson:"completionRate"`
+	Forks          int                `json:"forks" bson:"forks"`
+	Nodes          []NodeTiming       `json:"nodes" bson:"nodes"`
+	UpdatedAt      time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
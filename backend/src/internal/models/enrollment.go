@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Enrollment is a user following a roadmap, along with their progress.
+type Enrollment struct {
+	UserEmail        string             `json:"userEmail" bson:"userEmail"`
+	RoadmapID        primitive.ObjectID `json:"roadmapId" bson:"roadmapId"`
+	EnrolledAt       time.Time          `json:"enrolledAt" bson:"enrolledAt"`
+	CompletedNodeIds []string           `json:"completedNodeIds" bson:"completedNodeIds"`
+}
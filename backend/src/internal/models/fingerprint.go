@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Fingerprint is the MinHash signature of a roadmap, along with the
+// near-duplicates found when it was taken.
+type Fingerprint struct {
+	RoadmapID  primitive.ObjectID `bson:"_id"`
+	Signature  []uint32           `bson:"signature"`
+	Bands      []string           `bson:"bands"`
+	Duplicates []DuplicateMatch   `bson:"duplicates"`
+	UpdatedAt  time.Time          `bson:"updatedAt"`
+}
+
+type DuplicateMatch struct {
+	RoadmapID  primitive.ObjectID `bson:"roadmapId"`
+	Similarity float64            `bson:"similarity"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FunnelStage is how far a learner went in a roadmap.
+type FunnelStage string
+
+const (
+	StageGenerated  FunnelStage = "generated"
+	StageEnrolled   FunnelStage = "enrolled"
+	StageStarted    FunnelStage = "first_node_done"
+	StageModuleDone FunnelStage = "module_done"
+	StageCompleted  FunnelStage = "completed"
+)
+
+// FunnelStages are the stages in order, each implying the previous ones.
+var FunnelStages = []FunnelStage{StageGenerated, StageEnrolled, StageStarted, StageModuleDone, StageCompleted}
+
+type FunnelStep struct {
+	Stage      FunnelStage `json:"stage" bson:"stage"`
+	Count      int         `json:"count" bson:"count"`
+	Conversion float64     `json:"conversion" bson:"conversion"` // share of the previous step, 1 for the first
+}
+
+// Funnel follows the roadmaps generated since a time through the progress of
+// their learners, each roadmap counted at the furthest stage one reached.
+type Funnel struct {
+	Steps      []FunnelStep `json:"steps" bson:"steps"`
+	Since      time.Time    `json:"since" bson:"since"`
+	ComputedAt time.Time    `json:"computedAt" bson:"computedAt"`
+}
+
+// NodeFunnelStep is the number of learners of a roadmap who completed a node.
+type NodeFunnelStep struct {
+	NodeID    string  `json:"nodeId" bson:"nodeId"`
+	ModuleID  string  `json:"moduleId" bson:"moduleId"`
+	Title     string  `json:"title" bson:"title"`
+	Completed int     `json:"completed" bson:"completed"`
+	Rate      float64 `json:"rate" bson:"rate"` // share of the enrolled learners
+}
+
+// RoadmapFunnel follows every learner of a roadmap, node by node in roadmap
+// order, so its author sees where people stop.
+type RoadmapFunnel struct {
+	RoadmapID   primitive.ObjectID `json:"roadmapId" bson:"_id"`
+	AuthorEmail string             `json:"-" bson:"authorEmail"`
+	Steps       []FunnelStep       `json:"steps" bson:"steps"` // from enrolled on
+	Nodes       []NodeFunnelStep   `json:"nodes" bson:"nodes"`
+	ComputedAt  time.Time          `json:"computedAt" bson:"computedAt"`
+}
+
+// RetentionCohort is the users first active in a week, and how many of them
+// were active each week after.
+type RetentionCohort struct {
+	Week      time.Time `json:"week" bson:"week"`
+	Users     int       `json:"users" bson:"users"`
+	Active    []int     `json:"active" bson:"active"`       // by week since the cohort week, from 0
+	Retention []float64 `json:"retention" bson:"retention"` // Active over Users
+}
+
+type CohortReport struct {
+	Cohorts    []RetentionCohort `json:"cohorts" bson:"cohorts"` // oldest first
+	ComputedAt time.Time         `json:"computedAt" bson:"computedAt"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// Reasons a prompt can be rejected for before generation.
+const (
+	PromptTooShort   string = "prompt_too_short"
+	PromptTooLong    string = "prompt_too_long"
+	PromptDeniedTerm string = "prompt_denied_term"
+	PromptInjection  string = "prompt_injection"
+	PromptFlagged    string = "prompt_flagged"
+)
+
+// PromptVerdict is the outcome of screening a prompt.
+type PromptVerdict struct {
+	Allowed bool
+	Reasons []string
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// PlanTier is the subscription tier of a user, it defines the daily generation cap.
+type PlanTier string
+
+const (
+	PlanFree PlanTier = "free"
+	PlanPro  PlanTier = "pro"
+)
+
+// QuotaOverride replaces the plan limits of a single user.
+type QuotaOverride struct {
+	Email      string    `json:"email" bson:"_id"`
+	DailyLimit int       `json:"dailyLimit" bson:"dailyLimit"`
+	Unlimited  bool      `json:"unlimited" bson:"unlimited"`
+	UpdatedAt  time.Time `json:"updatedAt" bson:"updatedAt"`
+}
+
+// QuotaDecision is the outcome of consuming one unit of quota.
+type QuotaDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Time
+	RetryAfter time.Duration
+
+	// the counters consumed, for a refund
+	Buckets []string
+	Usage   []string
+}
@@ -3,17 +3,23 @@ package models
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
 type Roadmap struct {
-	ID                    primitive.ObjectID `json:"id" bson:"_id"`
-	Upvotes               int                `json:"upvotes"`
-	UserEmail             string             `json:"userEmail"`
-	SchemaVersion         int                `json:"schemaVersion"`
-	Title                 string             `json:"title"`
-	Description           string             `json:"description"`
-	Difficulty            string             `json:"difficulty"`
-	EstimatedTotalMinutes int                `json:"estimatedTotalMinutes"`
-	Tags                  []string           `json:"tags"`
-	Modules               []Modules          `json:"modules"`
-	Nodes                 []Nodes            `json:"nodes"`
+	ID                    primitive.ObjectID  `json:"id" bson:"_id"`
+	Upvotes               int                 `json:"upvotes"`
+	UserEmail             string              `json:"userEmail"`
+	SchemaVersion         int                 `json:"schemaVersion"`
+	Title                 string              `json:"title"`
+	Description           string              `json:"description"`
+	Difficulty            string              `json:"difficulty"`
+	EstimatedTotalMinutes int                 `json:"estimatedTotalMinutes"`
+	Tags                  []string            `json:"tags"`
+	Modules               []Modules           `json:"modules"`
+	Nodes                 []Nodes             `json:"nodes"`
+	PromptKey             string              `json:"-" bson:"promptKey,omitempty"`
+	PromptSignature       []uint32            `json:"-" bson:"promptSignature,omitempty"`
+	PromptBands           []string            `json:"-" bson:"promptBands,omitempty"`
+	OptionsKey            string              `json:"-" bson:"optionsKey,omitempty"` // generation options, empty for none
+	ForkedFrom            *primitive.ObjectID `json:"forkedFrom,omitempty" bson:"forkedFrom,omitempty"`
+	Trending              TrendingScores      `json:"-" bson:"trending"`
 }
 
 type Modules struct {
@@ -1,10 +1,47 @@
 package models
 
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Search is the document indexed for each roadmap, keyed by the roadmap ID.
 type Search struct {
-	Title         string
-	Prompt        string
-	Category      string
-	Dificulty     string
-	Modules       []string
-	EstimatedTime string `json:"estimatedTime"`
+	RoadmapID             string     `json:"roadmapId"`
+	UserEmail             string     `json:"userEmail"`
+	Title                 string     `json:"title"`
+	Prompt                string     `json:"prompt"`
+	Description           string     `json:"description"`
+	Difficulty            string     `json:"difficulty"`
+	Tags                  []string   `json:"tags"`
+	Modules               []string   `json:"modules"`
+	Nodes                 []string   `json:"nodes"`
+	EstimatedTotalMinutes int        `json:"estimatedTotalMinutes"`
+	Upvotes               int        `json:"upvotes"`
+	TitleSuggest          Completion `json:"titleSuggest"`
+	TagSuggest            Completion `json:"tagSuggest"`
+	Hash                  string     `json:"hash"` // content hash, compared against Mongo to detect drift
+}
+
+// Completion is the value of a completion field, Weight ranks its suggestions.
+type Completion struct {
+	Input  []string `json:"input"`
+	Weight int      `json:"weight"`
+}
+
+// SearchOutbox records a roadmap write that still has to reach the search
+// index, used when change streams are unavailable.
+type SearchOutbox struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	RoadmapID primitive.ObjectID `bson:"roadmapId"`
+	Ts        time.Time          `bson:"ts"`
+}
+
+// SearchSyncState is the resume point of the change stream feeding the index.
+type SearchSyncState struct {
+	ID          string    `bson:"_id"`
+	ResumeToken bson.Raw  `bson:"resumeToken"`
+	UpdatedAt   time.Time `bson:"updatedAt"`
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Tag is a canonical tag. Raw tags are slugified, then resolved to a canonical
+// tag by slug, by synonym or by compact key, in that order.
+type Tag struct {
+	Slug      string    `json:"slug" bson:"_id"`
+	Name      string    `json:"name" bson:"name"`
+	Compact   string    `json:"-" bson:"compact"` // slug without hyphens, "dev-ops" and "devops" share it
+	Synonyms  []string  `json:"synonyms" bson:"synonyms"`
+	Count     int       `json:"count" bson:"count"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+}
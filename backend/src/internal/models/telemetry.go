@@ -17,3 +17,35 @@ type Event struct {
 	Metadata map[string]any    `json:"metadata" bson:"metadata"`
 	Ts       time.Time         `json:"ts" bson:"ts"`
 }
+
+// QuarantinedEvent is an event that did not match its schema, kept aside for
+// inspection instead of polluting the events.
+type QuarantinedEvent struct {
+	Event `bson:",inline"`
+	Error string `json:"error" bson:"error"`
+}
+
+// RollupResolution is the bucket width of a MetricRollup.
+type RollupResolution string
+
+const (
+	RollupHour RollupResolution = "hour"
+	RollupDay  RollupResolution = "day"
+)
+
+// MetricRollup is a metric downsampled into a time bucket, for one set of tags.
+// Rollups outlive the raw metrics they are computed from.
+type MetricRollup struct {
+	Name       string            `json:"name" bson:"name"`
+	Resolution RollupResolution  `json:"resolution" bson:"resolution"`
+	Tags       map[string]string `json:"tags" bson:"tags"`
+	Ts         time.Time         `json:"ts" bson:"ts"`
+	Count      int               `json:"count" bson:"count"`
+	Sum        float64           `json:"sum" bson:"sum"`
+	Min        float64           `json:"min" bson:"min"`
+	Max        float64           `json:"max" bson:"max"`
+	Avg        float64           `json:"avg" bson:"avg"`
+	P50        float64           `json:"p50" bson:"p50"`
+	P95        float64           `json:"p95" bson:"p95"`
+	P99        float64           `json:"p99" bson:"p99"`
+}
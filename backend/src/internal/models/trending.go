@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// TrendingWindow is the period a trending score counts activity over.
+type TrendingWindow string
+
+const (
+	TrendingDay   TrendingWindow = "day"
+	TrendingWeek  TrendingWindow = "week"
+	TrendingMonth TrendingWindow = "month"
+)
+
+// RoadmapSort is the order of a roadmap listing.
+type RoadmapSort string
+
+const (
+	SortUpvotes  RoadmapSort = "upvotes"
+	SortTrending RoadmapSort = "trending"
+)
+
+// TrendingScores are the time-decayed popularity of a roadmap per window,
+// recomputed periodically.
+type TrendingScores struct {
+	Day       float64   `json:"day" bson:"day"`
+	Week      float64   `json:"week" bson:"week"`
+	Month     float64   `json:"month" bson:"month"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+}
+
+// RoadmapViews counts the views of a roadmap on a day.
+type RoadmapViews struct {
+	RoadmapID string    `bson:"roadmapId"`
+	Day       time.Time `bson:"day"`
+	Count     int       `bson:"count"`
+}
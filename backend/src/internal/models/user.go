@@ -1,5 +1,16 @@
 package models
 
+// SkillLevel is the level a user says they are at, matched against roadmap difficulty.
+type SkillLevel string
+
+const (
+	SkillBeginner     SkillLevel = "beginner"
+	SkillIntermediate SkillLevel = "intermediate"
+	SkillAdvanced     SkillLevel = "advanced"
+)
+
 type User struct {
-	Email string `json:"email"`
+	Email      string     `json:"email"`
+	Plan       PlanTier   `json:"plan" bson:"plan,omitempty"`
+	SkillLevel SkillLevel `json:"skillLevel" bson:"skillLevel,omitempty"`
 }
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+)
+
+type ActivityService interface {
+	// Record enqueues an activity, to be stored and counted in the user's day.
+	Record(ctx context.Context, activity models.Activity) error
+
+	// Upload stores the enqueued activities as they come. This method blocks,
+	// it should be called in a separate goroutine.
+	Upload() error
+
+	// Flush stores everything enqueued so far, for a graceful shutdown.
+	Flush(ctx context.Context) error
+
+	// ActiveUsers returns the emails of the users with an activity since a time.
+	ActiveUsers(ctx context.Context, since time.Time) ([]string, error)
+
+	// Days returns the days email was active since a time, most recent first.
+	Days(ctx context.Context, email string, since time.Time) ([]models.ActivityDay, error)
+}
+
+// ActivityDay returns the start of the UTC day of t, the key of the activity
+// index.
+func ActivityDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// ActivityStreak returns the number of consecutive days, among the active days
+// given most recent first, ending today or yesterday: a streak is not broken
+// before the day is over.
+func ActivityStreak(days []time.Time, now time.Time) int {
+	expected := ActivityDay(now)
+	if len(days) > 0 && ActivityDay(days[0]).Before(expected) {
+		expected = expected.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for _, day := range days {
+		day = ActivityDay(day)
+		if day.After(expected) {
+			continue
+		}
+		if !day.Equal(expected) {
+			break
+		}
+		streak++
+		expected = expected.AddDate(0, 0, -1)
+	}
+	return streak
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ActivityServiceMongoAsyncImpl struct {
+	activitiesCol    *mongo.Collection
+	activityDaysCol  *mongo.Collection
+	telemetryService TelemetryService
+	cfg              TelemetryConfig
+	queue            *TelemetryQueue[models.Activity]
+	batchReady       chan struct{}
+	flushMu          sync.Mutex
+	lastDropped      uint64 // guarded by flushMu
+}
+
+// NewActivityServiceMongoAsyncImpl creates an ActivityService whose Record
+// never blocks the request: activities go to a bounded queue tuned like the
+// telemetry ones, flushed by Upload.
+func NewActivityServiceMongoAsyncImpl(activitiesCol, activityDaysCol *mongo.Collection, telemetryService TelemetryService, cfg TelemetryConfig) ActivityService {
+	return &ActivityServiceMongoAsyncImpl{
+		activitiesCol:    activitiesCol,
+		activityDaysCol:  activityDaysCol,
+		telemetryService: telemetryService,
+		cfg:              cfg,
+		queue:            NewTelemetryQueue[models.Activity](cfg.QueueSize, cfg.Overflow),
+		batchReady:       make(chan struct{}, 1),
+	}
+}
+
+func (s *ActivityServiceMongoAsyncImpl) Record(ctx context.Context, activity models.Activity) error {
+	if activity.Ts.IsZero() {
+		activity.Ts = time.Now()
+	}
+	s.queue.Push(activity)
+	if s.queue.Len() >= s.cfg.BatchSize {
+		select {
+		case s.batchReady <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Upload flushes the queue whenever a batch fills up or the flush interval
+// elapses. It only returns on a write error, for the task runner to log it.
+func (s *ActivityServiceMongoAsyncImpl) Upload() error {
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.batchReady:
+		}
+		if err := s.Flush(context.Background()); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *ActivityServiceMongoAsyncImpl) Flush(ctx context.Context) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	for {
+		// a batch that fails to be written is dropped, as telemetry batches are
+		batch := s.queue.Drain(s.cfg.BatchSize)
+		if len(batch) == 0 {
+			break
+		}
+		if err := s.write(ctx, batch); err != nil {
+			return errors.Join(err, errors.New("could not flush activities"))
+		}
+	}
+
+	if _, dropped := s.queue.Counters(); dropped > s.lastDropped {
+		slog.Warn(fmt.Sprintf(
+			"activity queue overflowed (%s), %d activities dropped since last flush",
+			s.cfg.Overflow, dropped-s.lastDropped,
+		))
+		s.lastDropped = dropped
+	}
+	return nil
+}
+
+// write stores a batch of activities and counts them in their users' days,
+// with a single update per day.
+func (s *ActivityServiceMongoAsyncImpl) write(ctx context.Context, batch []models.Activity) error {
+	docs := make([]any, len(batch))
+	for i, activity := range batch {
+		docs[i] = activity
+	}
+	if _, err := s.activitiesCol.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false)); err != nil {
+		return errors.Join(err, errors.New("could not insert activities"))
+	}
+
+	type dayKey struct {
+		email string
+		day   time.Time
+	}
+	type dayCounts struct {
+		counts  map[models.ActivityKind]int
+		total   int
+		firstAt time.Time
+		lastAt  time.Time
+	}
+	keys := []dayKey{}
+	days := map[dayKey]*dayCounts{}
+	for _, activity := range batch {
+		key := dayKey{email: activity.UserEmail, day: ActivityDay(activity.Ts)}
+		d, ok := days[key]
+		if !ok {
+			d = &dayCounts{counts: map[models.ActivityKind]int{}, firstAt: activity.Ts, lastAt: activity.Ts}
+			days[key] = d
+			keys = append(keys, key)
+		}
+		d.counts[activity.Kind]++
+		d.total++
+		if activity.Ts.Before(d.firstAt) {
+			d.firstAt = activity.Ts
+		}
+		if activity.Ts.After(d.lastAt) {
+			d.lastAt = activity.Ts
+		}
+	}
+
+	writes := make([]mongo.WriteModel, len(keys))
+	for i, key := range keys {
+		d := days[key]
+		inc := bson.M{"total": d.total}
+		for kind, n := range d.counts {
+			inc["counts."+string(kind)] = n
+		}
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"userEmail": key.email, "day": key.day}).
+			SetUpdate(bson.M{
+				"$inc": inc,
+				"$min": bson.M{"firstAt": d.firstAt},
+				"$max": bson.M{"lastAt": d.lastAt},
+			}).
+			SetUpsert(true)
+	}
+	if _, err := s.activityDaysCol.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return errors.Join(err, errors.New("could not update activity days"))
+	}
+
+	// also product events, for the analytics over every user
+	for _, activity := range batch {
+		err := s.telemetryService.RecordEvent(ctx, models.EventUserActivity,
+			map[string]any{
+				"email":     activity.UserEmail,
+				"kind":      string(activity.Kind),
+				"roadmapId": activity.RoadmapID,
+			},
+			map[string]string{"kind": string(activity.Kind)},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ActivityServiceMongoAsyncImpl) ActiveUsers(ctx context.Context, since time.Time) ([]string, error) {
+	// the day index narrows it down, lastAt drops the users done before since
+	values, err := s.activityDaysCol.Distinct(ctx, "userEmail", bson.M{
+		"day":    bson.M{"$gte": ActivityDay(since)},
+		"lastAt": bson.M{"$gte": since},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	emails := []string{}
+	for _, v := range values {
+		if email, ok := v.(string); ok {
+			emails = append(emails, email)
+		}
+	}
+	return emails, nil
+}
+
+func (s *ActivityServiceMongoAsyncImpl) Days(ctx context.Context, email string, since time.Time) ([]models.ActivityDay, error) {
+	cur, err := s.activityDaysCol.Find(
+		ctx,
+		bson.M{"userEmail": email, "day": bson.M{"$gte": ActivityDay(since)}},
+		options.Find().SetSort(bson.M{"day": -1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	days := []models.ActivityDay{}
+	if err := cur.All(ctx, &days); err != nil {
+		return nil, err
+	}
+	return days, nil
+}
@@ -0,0 +1,54 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+)
+
+func TestActivityStreak(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		days []time.Time
+		want int
+	}{
+		{
+			name: "no activity",
+			days: nil,
+			want: 0,
+		},
+		{
+			name: "active today",
+			days: []time.Time{day(10), day(9), day(8), day(6)},
+			want: 3,
+		},
+		{
+			name: "not yet active today keeps yesterday's streak",
+			days: []time.Time{day(9), day(8)},
+			want: 2,
+		},
+		{
+			name: "broken streak",
+			days: []time.Time{day(8), day(7)},
+			want: 0,
+		},
+		{
+			name: "times within a day count as that day",
+			days: []time.Time{day(10).Add(9 * time.Hour), day(9).Add(23 * time.Hour)},
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := services.ActivityStreak(tt.days, now); got != tt.want {
+				t.Errorf("ActivityStreak() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
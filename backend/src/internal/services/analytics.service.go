@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
+)
+
+// AnalyticsService aggregates the recorded telemetry into time series.
+type AnalyticsService interface {
+	// MetricSeries aggregates a metric into time buckets, optionally split by
+	// the value of a tag.
+	MetricSeries(ctx context.Context, q dto.MetricQuery) ([]dto.MetricBucket, error)
+
+	// EventCounts counts events by name into time buckets.
+	EventCounts(ctx context.Context, q dto.EventCountQuery) ([]dto.EventBucket, error)
+
+	// DistinctUsers counts the users behind events into time buckets.
+	DistinctUsers(ctx context.Context, q dto.DistinctUsersQuery) ([]dto.DistinctUsersBucket, error)
+
+	// MetricRollups returns the hourly or daily rollups of a metric, one per
+	// bucket and set of tags, kept past the raw metrics retention.
+	MetricRollups(ctx context.Context, q dto.RollupQuery) ([]models.MetricRollup, error)
+}
+
+var intervalDurations = map[dto.AnalyticsInterval]time.Duration{
+	dto.IntervalMinute: time.Minute,
+	dto.IntervalHour:   time.Hour,
+	dto.IntervalDay:    24 * time.Hour,
+	dto.IntervalWeek:   7 * 24 * time.Hour,
+	dto.IntervalMonth:  30 * 24 * time.Hour,
+}
+
+// tagKeyRe keeps tag keys from reaching into other fields or operators.
+var tagKeyRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ValidateAnalyticsRange checks that r is not empty and does not span more than
+// maxBuckets buckets.
+func ValidateAnalyticsRange(r dto.AnalyticsRange, maxBuckets int) error {
+	d, ok := intervalDurations[r.Interval]
+	if !ok {
+		return errors.Join(constants.ErrAnalyticsQuery, fmt.Errorf("unknown interval %q", r.Interval))
+	}
+	if !r.From.Before(r.To) {
+		return errors.Join(constants.ErrAnalyticsQuery, errors.New("from must be before to"))
+	}
+	if buckets := int(r.To.Sub(r.From)/d) + 1; buckets > maxBuckets {
+		return errors.Join(constants.ErrAnalyticsQuery, fmt.Errorf("%d buckets, at most %d allowed", buckets, maxBuckets))
+	}
+	return nil
+}
+
+// ValidateTagKey checks that key can be used as a tag filter or group.
+func ValidateTagKey(key string) error {
+	if !tagKeyRe.MatchString(key) {
+		return errors.Join(constants.ErrAnalyticsQuery, fmt.Errorf("invalid tag key %q", key))
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AnalyticsServiceMongoImpl struct {
+	metricsCol *mongo.Collection
+	eventsCol  *mongo.Collection
+	rollupsCol *mongo.Collection
+	maxBuckets int
+}
+
+// NewAnalyticsServiceMongoImpl creates an AnalyticsService over the telemetry
+// collections, refusing queries of more than maxBuckets buckets.
+func NewAnalyticsServiceMongoImpl(metricsCol, eventsCol, rollupsCol *mongo.Collection, maxBuckets int) AnalyticsService {
+	return &AnalyticsServiceMongoImpl{
+		metricsCol: metricsCol,
+		eventsCol:  eventsCol,
+		rollupsCol: rollupsCol,
+		maxBuckets: maxBuckets,
+	}
+}
+
+// bucketTs truncates the event time to the start of its bucket, in UTC.
+func bucketTs(interval dto.AnalyticsInterval) bson.M {
+	return bson.M{"$dateTrunc": bson.M{"date": "$ts", "unit": string(interval)}}
+}
+
+func rangeFilter(r dto.AnalyticsRange) bson.M {
+	return bson.M{"$gte": r.From, "$lt": r.To}
+}
+
+func (s *AnalyticsServiceMongoImpl) MetricSeries(ctx context.Context, q dto.MetricQuery) ([]dto.MetricBucket, error) {
+	if err := ValidateAnalyticsRange(q.AnalyticsRange, s.maxBuckets); err != nil {
+		return nil, err
+	}
+
+	match := bson.M{"name": q.Name, "ts": rangeFilter(q.AnalyticsRange)}
+	for _, tag := range q.Tags {
+		key, value, _ := strings.Cut(tag, ":")
+		if err := ValidateTagKey(key); err != nil {
+			return nil, err
+		}
+		match["tags."+key] = value
+	}
+	id := bson.M{"ts": bucketTs(q.Interval)}
+	if q.GroupBy != "" {
+		if err := ValidateTagKey(q.GroupBy); err != nil {
+			return nil, err
+		}
+		id["group"] = "$tags." + q.GroupBy
+	}
+
+	cur, err := s.metricsCol.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   id,
+			"count": bson.M{"$sum": 1},
+			"sum":   bson.M{"$sum": "$value"},
+			"avg":   bson.M{"$avg": "$value"},
+			"pcts": bson.M{"$percentile": bson.M{
+				"input":  "$value",
+				"p":      bson.A{0.5, 0.95, 0.99},
+				"method": "approximate",
+			}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.ts", Value: 1}, {Key: "_id.group", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, errors.Join(err, errors.New("could not aggregate metrics"))
+	}
+	defer cur.Close(ctx)
+
+	buckets := []dto.MetricBucket{}
+	for cur.Next(ctx) {
+		var row struct {
+			ID struct {
+				Ts    time.Time `bson:"ts"`
+				Group string    `bson:"group"`
+			} `bson:"_id"`
+			Count int       `bson:"count"`
+			Sum   float64   `bson:"sum"`
+			Avg   float64   `bson:"avg"`
+			Pcts  []float64 `bson:"pcts"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, err
+		}
+		bucket := dto.MetricBucket{
+			Ts:    row.ID.Ts,
+			Group: row.ID.Group,
+			Count: row.Count,
+			Sum:   row.Sum,
+			Avg:   row.Avg,
+		}
+		if len(row.Pcts) == 3 {
+			bucket.P50, bucket.P95, bucket.P99 = row.Pcts[0], row.Pcts[1], row.Pcts[2]
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, cur.Err()
+}
+
+func (s *AnalyticsServiceMongoImpl) EventCounts(ctx context.Context, q dto.EventCountQuery) ([]dto.EventBucket, error) {
+	if err := ValidateAnalyticsRange(q.AnalyticsRange, s.maxBuckets); err != nil {
+		return nil, err
+	}
+
+	match := bson.M{"ts": rangeFilter(q.AnalyticsRange)}
+	if len(q.Names) > 0 {
+		match["name"] = bson.M{"$in": q.Names}
+	}
+	cur, err := s.eventsCol.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"ts": bucketTs(q.Interval), "name": "$name"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.ts", Value: 1}, {Key: "_id.name", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, errors.Join(err, errors.New("could not aggregate events"))
+	}
+	defer cur.Close(ctx)
+
+	buckets := []dto.EventBucket{}
+	for cur.Next(ctx) {
+		var row struct {
+			ID struct {
+				Ts   time.Time `bson:"ts"`
+				Name string    `bson:"name"`
+			} `bson:"_id"`
+			Count int `bson:"count"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, dto.EventBucket{Ts: row.ID.Ts, Name: row.ID.Name, Count: row.Count})
+	}
+	return buckets, cur.Err()
+}
+
+func (s *AnalyticsServiceMongoImpl) DistinctUsers(ctx context.Context, q dto.DistinctUsersQuery) ([]dto.DistinctUsersBucket, error) {
+	if err := ValidateAnalyticsRange(q.AnalyticsRange, s.maxBuckets); err != nil {
+		return nil, err
+	}
+
+	match := bson.M{"ts": rangeFilter(q.AnalyticsRange), "metadata.email": bson.M{"$nin": bson.A{nil, ""}}}
+	if len(q.Names) > 0 {
+		match["name"] = bson.M{"$in": q.Names}
+	}
+	cur, err := s.eventsCol.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{"_id": bson.M{"ts": bucketTs(q.Interval), "email": "$metadata.email"}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$_id.ts", "users": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	})
+	if err != nil {
+		return nil, errors.Join(err, errors.New("could not aggregate users"))
+	}
+	defer cur.Close(ctx)
+
+	buckets := []dto.DistinctUsersBucket{}
+	for cur.Next(ctx) {
+		var row struct {
+			Ts    time.Time `bson:"_id"`
+			Users int       `bson:"users"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, dto.DistinctUsersBucket{Ts: row.Ts, Users: row.Users})
+	}
+	return buckets, cur.Err()
+}
+
+func (s *AnalyticsServiceMongoImpl) MetricRollups(ctx context.Context, q dto.RollupQuery) ([]models.MetricRollup, error) {
+	r := dto.AnalyticsRange{From: q.From, To: q.To, Interval: dto.AnalyticsInterval(q.Resolution)}
+	if err := ValidateAnalyticsRange(r, s.maxBuckets); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{
+		"name":       q.Name,
+		"resolution": q.Resolution,
+		"ts":         rangeFilter(r),
+	}
+	for _, tag := range q.Tags {
+		key, value, _ := strings.Cut(tag, ":")
+		if err := ValidateTagKey(key); err != nil {
+			return nil, err
+		}
+		filter["tags."+key] = value
+	}
+	cur, err := s.rollupsCol.Find(ctx, filter, options.Find().SetSort(bson.M{"ts": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	rollups := []models.MetricRollup{}
+	if err := cur.All(ctx, &rollups); err != nil {
+		return nil, err
+	}
+	return rollups, nil
+}
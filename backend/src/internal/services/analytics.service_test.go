@@ -0,0 +1,81 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
+)
+
+func TestValidateAnalyticsRange(t *testing.T) {
+	to := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		r       dto.AnalyticsRange
+		wantErr bool
+	}{
+		{
+			name: "a day by hour",
+			r:    dto.AnalyticsRange{From: to.Add(-24 * time.Hour), To: to, Interval: dto.IntervalHour},
+		},
+		{
+			name: "a year by week",
+			r:    dto.AnalyticsRange{From: to.AddDate(-1, 0, 0), To: to, Interval: dto.IntervalWeek},
+		},
+		{
+			name:    "from after to",
+			r:       dto.AnalyticsRange{From: to, To: to.Add(-time.Hour), Interval: dto.IntervalHour},
+			wantErr: true,
+		},
+		{
+			name:    "empty range",
+			r:       dto.AnalyticsRange{From: to, To: to, Interval: dto.IntervalHour},
+			wantErr: true,
+		},
+		{
+			name:    "unknown interval",
+			r:       dto.AnalyticsRange{From: to.Add(-time.Hour), To: to, Interval: "second"},
+			wantErr: true,
+		},
+		{
+			name:    "too many buckets",
+			r:       dto.AnalyticsRange{From: to.AddDate(0, -1, 0), To: to, Interval: dto.IntervalMinute},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := services.ValidateAnalyticsRange(tt.r, 2000)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateAnalyticsRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, constants.ErrAnalyticsQuery) {
+				t.Errorf("ValidateAnalyticsRange() error = %v, want ErrAnalyticsQuery", err)
+			}
+		})
+	}
+}
+
+func TestValidateTagKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{key: "route"},
+		{key: "status_code"},
+		{key: "", wantErr: true},
+		{key: "a.b", wantErr: true},
+		{key: "$where", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if err := services.ValidateTagKey(tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTagKey(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
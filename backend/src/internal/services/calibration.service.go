@@ -0,0 +1,196 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/common"
+)
+
+// CalibrationService compares the generated time estimates with the time
+// learners actually take, from the node timings of the creator stats.
+type CalibrationService interface {
+	// Recompute recomputes the calibration of every topic.
+	Recompute() error
+
+	// Topics returns the calibration of every topic, most measured first.
+	Topics(ctx context.Context) ([]models.TopicCalibration, error)
+
+	// MeasuredTime returns the community-measured time of roadmap, nil if none
+	// of its nodes was measured yet.
+	MeasuredTime(ctx context.Context, roadmap models.Roadmap) (*dto.MeasuredTime, error)
+
+	// Hints returns the calibration hints for a generation focused on focusTags.
+	Hints(ctx context.Context, focusTags []string) (dto.CalibrationHints, error)
+}
+
+// CalibrationPolicy is how much measuring a calibration factor takes. Factors
+// are clamped to [1/MaxFactor, MaxFactor], beyond that the estimate or the
+// samples are broken rather than off.
+type CalibrationPolicy struct {
+	MinSamples int // study times for a node to be measured
+	MinNodes   int // measured nodes for a topic to be calibrated
+	MaxFactor  float64
+}
+
+func (p CalibrationPolicy) clamp(factor float64) float64 {
+	return min(max(factor, 1/p.MaxFactor), p.MaxFactor)
+}
+
+// NodeFactor returns how much longer than estimated learners took on a node,
+// false if it has no estimate or too few samples.
+func (p CalibrationPolicy) NodeFactor(timing models.NodeTiming) (float64, bool) {
+	if timing.EstimatedMinutes <= 0 || timing.Samples < p.MinSamples || timing.MedianMinutes <= 0 {
+		return 0, false
+	}
+	return p.clamp(timing.MedianMinutes / float64(timing.EstimatedMinutes)), true
+}
+
+// TopicCalibrations calibrates every tag of roadmaps, and AllTopics, as the
+// median factor of their measured nodes. stats holds the node timings of
+// roadmaps by ID hex; roadmaps without any, and removed nodes, are skipped.
+func TopicCalibrations(roadmaps []models.Roadmap, stats map[string]models.RoadmapStats, policy CalibrationPolicy, now time.Time) []models.TopicCalibration {
+	factors := map[string][]float64{}
+	samples := map[string]int{}
+	for _, roadmap := range roadmaps {
+		estimates := make(map[string]int, len(roadmap.Nodes))
+		for _, node := range roadmap.Nodes {
+			estimates[node.ID] = node.EstimatedMinutes
+		}
+		for _, timing := range stats[roadmap.ID.Hex()].Nodes {
+			estimate, exists := estimates[timing.NodeID]
+			if !exists {
+				continue
+			}
+			// the timing may predate an edit of the estimate
+			timing.EstimatedMinutes = estimate
+			factor, ok := policy.NodeFactor(timing)
+			if !ok {
+				continue
+			}
+			for _, tag := range append([]string{models.AllTopics}, roadmap.Tags...) {
+				factors[tag] = append(factors[tag], factor)
+				samples[tag] += timing.Samples
+			}
+		}
+	}
+
+	calibrations := []models.TopicCalibration{}
+	for tag, fs := range factors {
+		if len(fs) < policy.MinNodes {
+			continue
+		}
+		calibrations = append(calibrations, models.TopicCalibration{
+			Tag:       tag,
+			Factor:    roundFactor(Median(fs)),
+			Nodes:     len(fs),
+			Samples:   samples[tag],
+			UpdatedAt: now,
+		})
+	}
+	SortCalibrations(calibrations)
+	return calibrations
+}
+
+// SortCalibrations sorts calibrations most measured nodes first, then by tag.
+func SortCalibrations(calibrations []models.TopicCalibration) {
+	slices.SortFunc(calibrations, func(a, b models.TopicCalibration) int {
+		return cmp.Or(cmp.Compare(b.Nodes, a.Nodes), cmp.Compare(a.Tag, b.Tag))
+	})
+}
+
+// RoadmapFactor returns the factor for the unmeasured nodes of a roadmap tagged
+// tags: the median calibration of its calibrated tags, else the overall one,
+// else 1.
+func RoadmapFactor(tags []string, topics map[string]models.TopicCalibration) float64 {
+	factors := []float64{}
+	for _, tag := range tags {
+		if c, ok := topics[tag]; ok {
+			factors = append(factors, c.Factor)
+		}
+	}
+	if len(factors) > 0 {
+		return Median(factors)
+	}
+	if c, ok := topics[models.AllTopics]; ok {
+		return c.Factor
+	}
+	return 1
+}
+
+// MeasureRoadmap returns the community-measured time of roadmap from its node
+// timings: the median study time of measured nodes, the estimate corrected by
+// the topics of the roadmap for the others. Nil if no node is measured.
+func MeasureRoadmap(roadmap models.Roadmap, timings []models.NodeTiming, topics map[string]models.TopicCalibration, policy CalibrationPolicy) *dto.MeasuredTime {
+	byNode := make(map[string]models.NodeTiming, len(timings))
+	for _, timing := range timings {
+		byNode[timing.NodeID] = timing
+	}
+
+	fallback := RoadmapFactor(roadmap.Tags, topics)
+	measured := dto.MeasuredTime{Nodes: map[string]dto.MeasuredNode{}}
+	total := 0.0
+	for _, node := range roadmap.Nodes {
+		timing := byNode[node.ID]
+		// the timing may predate an edit of the estimate
+		timing.EstimatedMinutes = node.EstimatedMinutes
+		factor, ok := policy.NodeFactor(timing)
+		if !ok {
+			total += float64(node.EstimatedMinutes) * fallback
+			continue
+		}
+		total += timing.MedianMinutes
+		measured.Nodes[node.ID] = dto.MeasuredNode{
+			MedianMinutes: timing.MedianMinutes,
+			Samples:       timing.Samples,
+			Factor:        roundFactor(factor),
+		}
+	}
+	if len(measured.Nodes) == 0 {
+		return nil
+	}
+
+	measured.TotalMinutes = int(math.Round(total))
+	measured.Coverage = ratio(len(measured.Nodes), len(roadmap.Nodes))
+	return &measured
+}
+
+// BuildCalibrationHints picks the hints for a generation: the overall factor,
+// the calibrated focus tags, then the most measured topics up to limit topics.
+func BuildCalibrationHints(calibrations []models.TopicCalibration, focusTags []string, limit int) dto.CalibrationHints {
+	byTag := make(map[string]models.TopicCalibration, len(calibrations))
+	for _, c := range calibrations {
+		byTag[c.Tag] = c
+	}
+
+	hints := dto.CalibrationHints{Topics: map[string]float64{}}
+	if c, ok := byTag[models.AllTopics]; ok {
+		hints.Overall = c.Factor
+	}
+	for _, tag := range focusTags {
+		if c, ok := byTag[common.Slugify(tag)]; ok && len(hints.Topics) < limit {
+			hints.Topics[c.Tag] = c.Factor
+		}
+	}
+
+	sorted := slices.Clone(calibrations)
+	SortCalibrations(sorted)
+	for _, c := range sorted {
+		if len(hints.Topics) >= limit {
+			break
+		}
+		if c.Tag != models.AllTopics {
+			hints.Topics[c.Tag] = c.Factor
+		}
+	}
+	return hints
+}
+
+func roundFactor(factor float64) float64 {
+	return math.Round(factor*100) / 100
+}
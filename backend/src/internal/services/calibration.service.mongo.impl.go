@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CalibrationServiceMongoImpl struct {
+	roadmapsCol     *mongo.Collection
+	roadmapStatsCol *mongo.Collection
+	calibrationsCol *mongo.Collection
+	policy          CalibrationPolicy
+	hintTopics      int
+}
+
+// NewCalibrationServiceMongoImpl creates a CalibrationService reading the node
+// timings from roadmapStatsCol and storing the topic calibrations into
+// calibrationsCol. Generation hints carry up to hintTopics topics.
+func NewCalibrationServiceMongoImpl(
+	roadmapsCol *mongo.Collection,
+	roadmapStatsCol *mongo.Collection,
+	calibrationsCol *mongo.Collection,
+	policy CalibrationPolicy,
+	hintTopics int,
+) CalibrationService {
+	return &CalibrationServiceMongoImpl{
+		roadmapsCol:     roadmapsCol,
+		roadmapStatsCol: roadmapStatsCol,
+		calibrationsCol: calibrationsCol,
+		policy:          policy,
+		hintTopics:      hintTopics,
+	}
+}
+
+func (s *CalibrationServiceMongoImpl) Recompute() error {
+	ctx := context.Background()
+
+	cur, err := s.roadmapStatsCol.Find(ctx, bson.M{"nodes.samples": bson.M{"$gte": s.policy.MinSamples}})
+	if err != nil {
+		return err
+	}
+	var statsList []models.RoadmapStats
+	if err := cur.All(ctx, &statsList); err != nil {
+		return err
+	}
+	stats := make(map[string]models.RoadmapStats, len(statsList))
+	ids := make([]any, len(statsList))
+	for i, st := range statsList {
+		stats[st.RoadmapID.Hex()] = st
+		ids[i] = st.RoadmapID
+	}
+
+	opts := options.Find().SetProjection(bson.M{"tags": 1, "nodes": 1})
+	cur, err = s.roadmapsCol.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		return err
+	}
+	var roadmaps []models.Roadmap
+	if err := cur.All(ctx, &roadmaps); err != nil {
+		return err
+	}
+
+	calibrations := TopicCalibrations(roadmaps, stats, s.policy, time.Now())
+	writes := []mongo.WriteModel{}
+	tags := make([]string, len(calibrations))
+	for i, c := range calibrations {
+		tags[i] = c.Tag
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": c.Tag}).
+			SetReplacement(c).
+			SetUpsert(true))
+	}
+	// topics no longer measured enough are not calibrated anymore
+	writes = append(writes, mongo.NewDeleteManyModel().SetFilter(bson.M{"_id": bson.M{"$nin": tags}}))
+
+	_, err = s.calibrationsCol.BulkWrite(ctx, writes)
+	if err != nil {
+		return errors.Join(err, errors.New("could not write topic calibrations"))
+	}
+	return nil
+}
+
+func (s *CalibrationServiceMongoImpl) Topics(ctx context.Context) ([]models.TopicCalibration, error) {
+	cur, err := s.calibrationsCol.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	calibrations := []models.TopicCalibration{}
+	if err := cur.All(ctx, &calibrations); err != nil {
+		return nil, err
+	}
+	SortCalibrations(calibrations)
+	return calibrations, nil
+}
+
+func (s *CalibrationServiceMongoImpl) MeasuredTime(ctx context.Context, roadmap models.Roadmap) (*dto.MeasuredTime, error) {
+	var stats models.RoadmapStats
+	err := s.roadmapStatsCol.FindOne(ctx, bson.M{"_id": roadmap.ID}).Decode(&stats)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := s.calibrationsCol.Find(ctx, bson.M{"_id": bson.M{"$in": append([]string{models.AllTopics}, roadmap.Tags...)}})
+	if err != nil {
+		return nil, err
+	}
+	var calibrations []models.TopicCalibration
+	if err := cur.All(ctx, &calibrations); err != nil {
+		return nil, err
+	}
+	topics := make(map[string]models.TopicCalibration, len(calibrations))
+	for _, c := range calibrations {
+		topics[c.Tag] = c
+	}
+
+	return MeasureRoadmap(roadmap, stats.Nodes, topics, s.policy), nil
+}
+
+func (s *CalibrationServiceMongoImpl) Hints(ctx context.Context, focusTags []string) (dto.CalibrationHints, error) {
+	calibrations, err := s.Topics(ctx)
+	if err != nil {
+		return dto.CalibrationHints{}, err
+	}
+	return BuildCalibrationHints(calibrations, focusTags, s.hintTopics), nil
+}
@@ -0,0 +1,187 @@
+package services_test
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var calibrationPolicy = services.CalibrationPolicy{MinSamples: 3, MinNodes: 2, MaxFactor: 4}
+
+func TestCalibrationPolicy_NodeFactor(t *testing.T) {
+	tests := []struct {
+		name   string
+		timing models.NodeTiming
+		want   float64
+		wantOk bool
+	}{
+		{
+			name:   "measured",
+			timing: models.NodeTiming{EstimatedMinutes: 30, MedianMinutes: 45, Samples: 3},
+			want:   1.5,
+			wantOk: true,
+		},
+		{
+			name:   "too few samples",
+			timing: models.NodeTiming{EstimatedMinutes: 30, MedianMinutes: 45, Samples: 2},
+		},
+		{
+			name:   "no estimate",
+			timing: models.NodeTiming{MedianMinutes: 45, Samples: 10},
+		},
+		{
+			name:   "clamped up",
+			timing: models.NodeTiming{EstimatedMinutes: 10, MedianMinutes: 600, Samples: 5},
+			want:   4,
+			wantOk: true,
+		},
+		{
+			name:   "clamped down",
+			timing: models.NodeTiming{EstimatedMinutes: 600, MedianMinutes: 10, Samples: 5},
+			want:   0.25,
+			wantOk: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := calibrationPolicy.NodeFactor(tt.timing)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("NodeFactor() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestTopicCalibrations(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	goRoadmap := models.Roadmap{
+		ID:   primitive.NewObjectID(),
+		Tags: []string{"go", "backend"},
+		Nodes: []models.Nodes{
+			{ID: "a", EstimatedMinutes: 30},
+			{ID: "b", EstimatedMinutes: 60},
+			{ID: "c", EstimatedMinutes: 20},
+		},
+	}
+	reactRoadmap := models.Roadmap{
+		ID:    primitive.NewObjectID(),
+		Tags:  []string{"react"},
+		Nodes: []models.Nodes{{ID: "a", EstimatedMinutes: 40}},
+	}
+	stats := map[string]models.RoadmapStats{
+		goRoadmap.ID.Hex(): {Nodes: []models.NodeTiming{
+			// measured against an older estimate, the current one wins
+			{NodeID: "a", EstimatedMinutes: 10, MedianMinutes: 60, Samples: 4},
+			{NodeID: "b", MedianMinutes: 60, Samples: 3},
+			{NodeID: "c", MedianMinutes: 60, Samples: 1},       // too few samples
+			{NodeID: "removed", MedianMinutes: 60, Samples: 9}, // no longer in the roadmap
+		}},
+		reactRoadmap.ID.Hex(): {Nodes: []models.NodeTiming{
+			{NodeID: "a", MedianMinutes: 20, Samples: 5},
+		}},
+	}
+
+	got := services.TopicCalibrations([]models.Roadmap{goRoadmap, reactRoadmap}, stats, calibrationPolicy, now)
+	want := []models.TopicCalibration{
+		{Tag: models.AllTopics, Factor: 1, Nodes: 3, Samples: 12, UpdatedAt: now},
+		{Tag: "backend", Factor: 1.5, Nodes: 2, Samples: 7, UpdatedAt: now},
+		{Tag: "go", Factor: 1.5, Nodes: 2, Samples: 7, UpdatedAt: now},
+		// react has a single measured node
+	}
+	if len(got) != len(want) {
+		t.Fatalf("TopicCalibrations() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("TopicCalibrations()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMeasureRoadmap(t *testing.T) {
+	roadmap := models.Roadmap{
+		Tags: []string{"go"},
+		Nodes: []models.Nodes{
+			{ID: "a", EstimatedMinutes: 30},
+			{ID: "b", EstimatedMinutes: 60},
+			{ID: "c", EstimatedMinutes: 20},
+		},
+	}
+	timings := []models.NodeTiming{
+		{NodeID: "a", EstimatedMinutes: 30, MedianMinutes: 45, Samples: 3},
+		{NodeID: "b", EstimatedMinutes: 60, MedianMinutes: 10, Samples: 1},
+	}
+	topics := map[string]models.TopicCalibration{
+		models.AllTopics: {Tag: models.AllTopics, Factor: 3},
+		"go":             {Tag: "go", Factor: 2},
+	}
+
+	got := services.MeasureRoadmap(roadmap, timings, topics, calibrationPolicy)
+	if got == nil {
+		t.Fatal("MeasureRoadmap() = nil")
+	}
+	// a is measured, b and c are estimated through the go calibration
+	if got.TotalMinutes != 45+120+40 {
+		t.Errorf("TotalMinutes = %d, want %d", got.TotalMinutes, 45+120+40)
+	}
+	if got.Coverage != 1.0/3 {
+		t.Errorf("Coverage = %v, want %v", got.Coverage, 1.0/3)
+	}
+	if len(got.Nodes) != 1 || got.Nodes["a"].Factor != 1.5 || got.Nodes["a"].Samples != 3 {
+		t.Errorf("Nodes = %+v", got.Nodes)
+	}
+
+	if got := services.MeasureRoadmap(roadmap, nil, topics, calibrationPolicy); got != nil {
+		t.Errorf("MeasureRoadmap() without timings = %+v, want nil", got)
+	}
+}
+
+func TestRoadmapFactor(t *testing.T) {
+	topics := map[string]models.TopicCalibration{
+		models.AllTopics: {Factor: 1.2},
+		"go":             {Factor: 2},
+		"docker":         {Factor: 1},
+	}
+	tests := []struct {
+		name string
+		tags []string
+		want float64
+	}{
+		{name: "calibrated tags", tags: []string{"go", "docker", "unknown"}, want: 1.5},
+		{name: "overall", tags: []string{"unknown"}, want: 1.2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := services.RoadmapFactor(tt.tags, topics); got != tt.want {
+				t.Errorf("RoadmapFactor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := services.RoadmapFactor([]string{"go"}, nil); got != 1 {
+		t.Errorf("RoadmapFactor() uncalibrated = %v, want 1", got)
+	}
+}
+
+func TestBuildCalibrationHints(t *testing.T) {
+	calibrations := []models.TopicCalibration{
+		{Tag: models.AllTopics, Factor: 1.3, Nodes: 50},
+		{Tag: "python", Factor: 1.1, Nodes: 20},
+		{Tag: "react", Factor: 1.6, Nodes: 10},
+		{Tag: "kubernetes", Factor: 2.2, Nodes: 3},
+	}
+
+	got := services.BuildCalibrationHints(calibrations, []string{"Kubernetes", "rust"}, 2)
+	if got.Overall != 1.3 {
+		t.Errorf("Overall = %v, want 1.3", got.Overall)
+	}
+	// the focus tag first, then the most measured topic
+	want := map[string]float64{"kubernetes": 2.2, "python": 1.1}
+	if !maps.Equal(got.Topics, want) {
+		t.Errorf("Topics = %v, want %v", got.Topics, want)
+	}
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+)
+
+// CreatorService tells roadmap authors how their roadmaps are doing.
+type CreatorService interface {
+	// MaterializeDays aggregates today and yesterday into the daily figures of
+	// every roadmap.
+	MaterializeDays() error
+
+	// RecomputeStats recomputes the all-time figures of every roadmap with learners.
+	RecomputeStats() error
+
+	// Analytics returns the figures of a roadmap over the last days days.
+	Analytics(ctx context.Context, roadmap models.Roadmap, days int) (dto.CreatorAnalytics, error)
+}
+
+// NodeStudyTimes returns the time learners spent on each node of a roadmap:
+// from their previous completion, or their enrollment, to the completion of
+// the node. Samples over maxGap are dropped, the learner was away. Completing
+// a node again is idempotent but still tracked, only the first completion of
+// each node counts.
+func NodeStudyTimes(completions []models.Activity, enrolledAt map[string]time.Time, maxGap time.Duration) map[string][]time.Duration {
+	byUser := map[string][]models.Activity{}
+	for _, c := range completions {
+		byUser[c.UserEmail] = append(byUser[c.UserEmail], c)
+	}
+
+	samples := map[string][]time.Duration{}
+	for email, activities := range byUser {
+		slices.SortFunc(activities, func(a, b models.Activity) int { return a.Ts.Compare(b.Ts) })
+		prev, ok := enrolledAt[email]
+		completed := map[string]bool{}
+		for _, a := range activities {
+			if completed[a.NodeID] {
+				continue
+			}
+			completed[a.NodeID] = true
+			if ok {
+				if d := a.Ts.Sub(prev); d > 0 && d <= maxGap {
+					samples[a.NodeID] = append(samples[a.NodeID], d)
+				}
+			}
+			prev, ok = a.Ts, true
+		}
+	}
+	return samples
+}
+
+// Median returns the median of values, 0 if there are none.
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// NodeTimings pairs the estimate of every node of a roadmap with the median of
+// its study times.
+func NodeTimings(roadmap models.Roadmap, studyTimes map[string][]time.Duration) []models.NodeTiming {
+	timings := make([]models.NodeTiming, len(roadmap.Nodes))
+	for i, node := range roadmap.Nodes {
+		minutes := make([]float64, len(studyTimes[node.ID]))
+		for j, d := range studyTimes[node.ID] {
+			minutes[j] = d.Minutes()
+		}
+		timings[i] = models.NodeTiming{
+			NodeID:           node.ID,
+			Title:            node.Title,
+			EstimatedMinutes: node.EstimatedMinutes,
+			MedianMinutes:    Median(minutes),
+			Samples:          len(minutes),
+		}
+	}
+	return timings
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CreatorServiceMongoImpl struct {
+	roadmapsCol     *mongo.Collection
+	viewsCol        *mongo.Collection
+	enrollmentsCol  *mongo.Collection
+	activitiesCol   *mongo.Collection
+	roadmapDaysCol  *mongo.Collection
+	roadmapStatsCol *mongo.Collection
+	maxStudyGap     time.Duration
+}
+
+// NewCreatorServiceMongoImpl creates a CreatorService materializing into
+// roadmapDaysCol and roadmapStatsCol. Study times over maxStudyGap are not
+// counted towards the node timings.
+func NewCreatorServiceMongoImpl(
+	roadmapsCol *mongo.Collection,
+	viewsCol *mongo.Collection,
+	enrollmentsCol *mongo.Collection,
+	activitiesCol *mongo.Collection,
+	roadmapDaysCol *mongo.Collection,
+	roadmapStatsCol *mongo.Collection,
+	maxStudyGap time.Duration,
+) CreatorService {
+	return &CreatorServiceMongoImpl{
+		roadmapsCol:     roadmapsCol,
+		viewsCol:        viewsCol,
+		enrollmentsCol:  enrollmentsCol,
+		activitiesCol:   activitiesCol,
+		roadmapDaysCol:  roadmapDaysCol,
+		roadmapStatsCol: roadmapStatsCol,
+		maxStudyGap:     maxStudyGap,
+	}
+}
+
+func (s *CreatorServiceMongoImpl) MaterializeDays() error {
+	ctx := context.Background()
+	today := ActivityDay(time.Now())
+
+	// yesterday is redone once more, for what came in after its last run
+	if err := s.materializeDay(ctx, today.AddDate(0, 0, -1), false); err != nil {
+		return errors.Join(err, errors.New("could not materialize yesterday"))
+	}
+	if err := s.materializeDay(ctx, today, true); err != nil {
+		return errors.Join(err, errors.New("could not materialize today"))
+	}
+	return nil
+}
+
+// materializeDay upserts the figures of day for every roadmap with some. The
+// upvotes can only be snapshotted as they are now, so only for today.
+func (s *CreatorServiceMongoImpl) materializeDay(ctx context.Context, day time.Time, today bool) error {
+	end := day.Add(24 * time.Hour)
+	figures := map[primitive.ObjectID]bson.M{}
+	set := func(id primitive.ObjectID, key string, value int) {
+		if figures[id] == nil {
+			figures[id] = bson.M{"views": 0, "uniqueViewers": 0, "enrollments": 0, "forks": 0}
+		}
+		figures[id][key] = value
+	}
+
+	views, err := s.viewCounts(ctx, day)
+	if err != nil {
+		return err
+	}
+	for id, n := range views {
+		set(id, "views", n)
+	}
+
+	viewers, err := countByRoadmap(ctx, s.activitiesCol, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"kind": models.ActivityViewedRoadmap,
+			"ts":   bson.M{"$gte": day, "$lt": end},
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": "$roadmapId", "users": bson.M{"$addToSet": "$userEmail"}}}},
+		{{Key: "$project", Value: bson.M{"count": bson.M{"$size": "$users"}}}},
+	})
+	if err != nil {
+		return err
+	}
+	for id, n := range viewers {
+		set(id, "uniqueViewers", n)
+	}
+
+	enrollments, err := countByRoadmap(ctx, s.enrollmentsCol, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"enrolledAt": bson.M{"$gte": day, "$lt": end}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$roadmapId", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return err
+	}
+	for id, n := range enrollments {
+		set(id, "enrollments", n)
+	}
+
+	forks, err := countByRoadmap(ctx, s.roadmapsCol, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"forkedFrom": bson.M{"$exists": true},
+			"_id": bson.M{
+				"$gte": primitive.NewObjectIDFromTimestamp(day),
+				"$lt":  primitive.NewObjectIDFromTimestamp(end),
+			},
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": "$forkedFrom", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return err
+	}
+	for id, n := range forks {
+		set(id, "forks", n)
+	}
+
+	if today {
+		cur, err := s.roadmapsCol.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"upvotes": 1}))
+		if err != nil {
+			return err
+		}
+		var roadmaps []models.Roadmap
+		if err := cur.All(ctx, &roadmaps); err != nil {
+			return err
+		}
+		for _, rm := range roadmaps {
+			set(rm.ID, "upvotes", rm.Upvotes)
+		}
+	}
+
+	writes := []mongo.WriteModel{}
+	for id, fields := range figures {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"roadmapId": id, "day": day}).
+			SetUpdate(bson.M{"$set": fields}).
+			SetUpsert(true))
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+	_, err = s.roadmapDaysCol.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// viewCounts returns the views recorded for the trending scores on day.
+func (s *CreatorServiceMongoImpl) viewCounts(ctx context.Context, day time.Time) (map[primitive.ObjectID]int, error) {
+	cur, err := s.viewsCol.Find(ctx, bson.M{"day": day})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		RoadmapID string `bson:"roadmapId"`
+		Count     int    `bson:"count"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[primitive.ObjectID]int, len(rows))
+	for _, r := range rows {
+		if id, err := primitive.ObjectIDFromHex(r.RoadmapID); err == nil {
+			counts[id] += r.Count
+		}
+	}
+	return counts, nil
+}
+
+// countByRoadmap runs a pipeline yielding {_id: roadmap ID, count}, the ID
+// either an ObjectID or its hex.
+func countByRoadmap(ctx context.Context, col *mongo.Collection, pipeline mongo.Pipeline) (map[primitive.ObjectID]int, error) {
+	cur, err := col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ID    any `bson:"_id"`
+		Count int `bson:"count"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[primitive.ObjectID]int, len(rows))
+	for _, r := range rows {
+		switch id := r.ID.(type) {
+		case primitive.ObjectID:
+			counts[id] += r.Count
+		case string:
+			if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+				counts[objID] += r.Count
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (s *CreatorServiceMongoImpl) RecomputeStats() error {
+	ctx := context.Background()
+	now := time.Now()
+
+	enrollments := map[primitive.ObjectID][]models.Enrollment{}
+	cur, err := s.enrollmentsCol.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	for cur.Next(ctx) {
+		var e models.Enrollment
+		if err := cur.Decode(&e); err != nil {
+			cur.Close(ctx)
+			return err
+		}
+		enrollments[e.RoadmapID] = append(enrollments[e.RoadmapID], e)
+	}
+	cur.Close(ctx)
+	if err := cur.Err(); err != nil {
+		return err
+	}
+
+	completions := map[primitive.ObjectID][]models.Activity{}
+	cur, err = s.activitiesCol.Find(ctx, bson.M{"kind": models.ActivityCompletedNode})
+	if err != nil {
+		return err
+	}
+	for cur.Next(ctx) {
+		var a models.Activity
+		if err := cur.Decode(&a); err != nil {
+			cur.Close(ctx)
+			return err
+		}
+		if id, err := primitive.ObjectIDFromHex(a.RoadmapID); err == nil {
+			completions[id] = append(completions[id], a)
+		}
+	}
+	cur.Close(ctx)
+	if err := cur.Err(); err != nil {
+		return err
+	}
+
+	viewers, err := countByRoadmap(ctx, s.activitiesCol, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"kind": models.ActivityViewedRoadmap}}},
+		{{Key: "$group", Value: bson.M{"_id": "$roadmapId", "users": bson.M{"$addToSet": "$userEmail"}}}},
+		{{Key: "$project", Value: bson.M{"count": bson.M{"$size": "$users"}}}},
+	})
+	if err != nil {
+		return err
+	}
+	forks, err := countByRoadmap(ctx, s.roadmapsCol, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"forkedFrom": bson.M{"$exists": true}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$forkedFrom", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	opts := options.Find().SetProjection(bson.M{"modules": 1, "nodes": 1})
+	cur, err = s.roadmapsCol.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	writes := []mongo.WriteModel{}
+	for cur.Next(ctx) {
+		var roadmap models.Roadmap
+		if err := cur.Decode(&roadmap); err != nil {
+			return err
+		}
+		learners := enrollments[roadmap.ID]
+		if len(learners) == 0 && viewers[roadmap.ID] == 0 && forks[roadmap.ID] == 0 {
+			continue
+		}
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": roadmap.ID}).
+			SetReplacement(s.stats(roadmap, learners, completions[roadmap.ID], viewers[roadmap.ID], forks[roadmap.ID], now)).
+			SetUpsert(true))
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+	_, err = s.roadmapStatsCol.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (s *CreatorServiceMongoImpl) stats(
+	roadmap models.Roadmap,
+	learners []models.Enrollment,
+	completions []models.Activity,
+	viewers int,
+	forks int,
+	now time.Time,
+) models.RoadmapStats {
+	completed := 0
+	enrolledAt := make(map[string]time.Time, len(learners))
+	for _, e := range learners {
+		if LearnerStage(roadmap, e.CompletedNodeIds) == models.StageCompleted {
+			completed++
+		}
+		enrolledAt[e.UserEmail] = e.EnrolledAt
+	}
+	return models.RoadmapStats{
+		RoadmapID:      roadmap.ID,
+		UniqueViewers:  viewers,
+		Enrollments:    len(learners),
+		Completed:      completed,
+		CompletionRate: ratio(completed, len(learners)),
+		Forks:          forks,
+		Nodes:          NodeTimings(roadmap, NodeStudyTimes(completions, enrolledAt, s.maxStudyGap)),
+		UpdatedAt:      now,
+	}
+}
+
+func (s *CreatorServiceMongoImpl) Analytics(ctx context.Context, roadmap models.Roadmap, days int) (dto.CreatorAnalytics, error) {
+	var stats models.RoadmapStats
+	err := s.roadmapStatsCol.FindOne(ctx, bson.M{"_id": roadmap.ID}).Decode(&stats)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// not recomputed since it was published
+		stats = models.RoadmapStats{RoadmapID: roadmap.ID, Nodes: NodeTimings(roadmap, nil)}
+	} else if err != nil {
+		return dto.CreatorAnalytics{}, err
+	}
+
+	since := ActivityDay(time.Now()).AddDate(0, 0, -(days - 1))
+	cur, err := s.roadmapDaysCol.Find(
+		ctx,
+		bson.M{"roadmapId": roadmap.ID, "day": bson.M{"$gte": since}},
+		options.Find().SetSort(bson.M{"day": 1}),
+	)
+	if err != nil {
+		return dto.CreatorAnalytics{}, err
+	}
+	daily := []models.RoadmapDay{}
+	if err := cur.All(ctx, &daily); err != nil {
+		return dto.CreatorAnalytics{}, err
+	}
+
+	views := 0
+	for _, d := range daily {
+		views += d.Views
+	}
+	return dto.CreatorAnalytics{
+		RoadmapID:    roadmap.ID.Hex(),
+		Views:        views,
+		Upvotes:      roadmap.Upvotes,
+		RoadmapStats: stats,
+		Daily:        daily,
+	}, nil
+}
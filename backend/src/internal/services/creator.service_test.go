@@ -0,0 +1,81 @@
+package services_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+)
+
+func TestNodeStudyTimes(t *testing.T) {
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	completions := []models.Activity{
+		// listed out of order, sorted by time per user
+		{UserEmail: "a", NodeID: "n2", Ts: at(50)},
+		{UserEmail: "a", NodeID: "n1", Ts: at(20)},
+		{UserEmail: "a", NodeID: "n1", Ts: at(20).Add(3 * time.Second)}, // re-posted
+		{UserEmail: "a", NodeID: "n1", Ts: at(55)},                      // completed again later
+		{UserEmail: "b", NodeID: "n1", Ts: at(40)},
+		{UserEmail: "b", NodeID: "n2", Ts: at(40 + 24*60)}, // away for a day
+		{UserEmail: "c", NodeID: "n1", Ts: at(5)},          // enrollment unknown
+		{UserEmail: "c", NodeID: "n2", Ts: at(15)},
+	}
+	enrolledAt := map[string]time.Time{"a": start, "b": start}
+
+	got := services.NodeStudyTimes(completions, enrolledAt, 12*time.Hour)
+	want := map[string][]time.Duration{
+		"n1": {20 * time.Minute, 40 * time.Minute},
+		"n2": {30 * time.Minute, 10 * time.Minute},
+	}
+	if len(got) != len(want) {
+		t.Errorf("NodeStudyTimes() has %d nodes, want %d", len(got), len(want))
+	}
+	for node, durations := range want {
+		g := slices.Clone(got[node])
+		slices.Sort(g)
+		slices.Sort(durations)
+		if !slices.Equal(g, durations) {
+			t.Errorf("NodeStudyTimes()[%s] = %v, want %v", node, g, durations)
+		}
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   float64
+	}{
+		{values: nil, want: 0},
+		{values: []float64{7}, want: 7},
+		{values: []float64{9, 1, 5}, want: 5},
+		{values: []float64{4, 1, 3, 2}, want: 2.5},
+	}
+	for _, tt := range tests {
+		if got := services.Median(tt.values); got != tt.want {
+			t.Errorf("Median(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestNodeTimings(t *testing.T) {
+	roadmap := models.Roadmap{Nodes: []models.Nodes{
+		{ID: "n1", Title: "Basics", EstimatedMinutes: 30},
+		{ID: "n2", Title: "Advanced", EstimatedMinutes: 60},
+	}}
+	timings := services.NodeTimings(roadmap, map[string][]time.Duration{
+		"n1": {20 * time.Minute, 40 * time.Minute, 90 * time.Minute},
+	})
+
+	want := []models.NodeTiming{
+		{NodeID: "n1", Title: "Basics", EstimatedMinutes: 30, MedianMinutes: 40, Samples: 3},
+		{NodeID: "n2", Title: "Advanced", EstimatedMinutes: 60, MedianMinutes: 0, Samples: 0},
+	}
+	if !slices.Equal(timings, want) {
+		t.Errorf("NodeTimings() = %+v, want %+v", timings, want)
+	}
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+)
+
+type DedupService interface {
+	// Flag fingerprints a roadmap and records the near-duplicates already stored,
+	// most similar first. Flagging again replaces the previous fingerprint, and
+	// the matches other fingerprints recorded against it.
+	Flag(ctx context.Context, roadmap models.Roadmap) ([]models.DuplicateMatch, error)
+
+	// Duplicates returns the near-duplicates recorded for a roadmap, most similar first.
+	Duplicates(ctx context.Context, roadmapId string) ([]dto.DuplicateRoadmap, error)
+
+	// Clusters groups the near-duplicates of every roadmap, largest clusters first.
+	Clusters(ctx context.Context) ([]dto.DuplicateCluster, error)
+
+	// Backfill flags the roadmaps without a fingerprint, and fingerprints the
+	// prompts of those generated before prompts were.
+	Backfill() error
+}
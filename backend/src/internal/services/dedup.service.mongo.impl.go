@@ -0,0 +1,290 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type DedupServiceMongoImpl struct {
+	roadmapsCol     *mongo.Collection
+	fingerprintsCol *mongo.Collection
+	threshold       float64
+	maxCandidates   int64
+}
+
+// NewDedupServiceMongoImpl creates a DedupService flagging roadmaps whose
+// estimated Jaccard similarity reaches threshold, out of at most maxCandidates
+// sharing an LSH band.
+func NewDedupServiceMongoImpl(roadmapsCol, fingerprintsCol *mongo.Collection, threshold float64, maxCandidates int64) DedupService {
+	return &DedupServiceMongoImpl{
+		roadmapsCol:     roadmapsCol,
+		fingerprintsCol: fingerprintsCol,
+		threshold:       threshold,
+		maxCandidates:   maxCandidates,
+	}
+}
+
+func (s *DedupServiceMongoImpl) Flag(ctx context.Context, roadmap models.Roadmap) ([]models.DuplicateMatch, error) {
+	signature, bands := RoadmapFingerprint(roadmap)
+
+	opts := options.Find().SetLimit(s.maxCandidates).SetProjection(bson.M{"signature": 1})
+	cur, err := s.fingerprintsCol.Find(ctx, bson.M{"_id": bson.M{"$ne": roadmap.ID}, "bands": bson.M{"$in": bands}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []models.Fingerprint
+	if err := cur.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+
+	matches := []models.DuplicateMatch{}
+	for _, c := range candidates {
+		if sim := SignatureSimilarity(signature, c.Signature); sim >= s.threshold {
+			matches = append(matches, models.DuplicateMatch{RoadmapID: c.RoadmapID, Similarity: sim})
+		}
+	}
+	slices.SortFunc(matches, func(a, b models.DuplicateMatch) int { return cmp.Compare(b.Similarity, a.Similarity) })
+
+	// Duplicates looks both ways, the matches newer roadmaps recorded against
+	// the previous content are stale, the ones still similar are in matches
+	_, err = s.fingerprintsCol.UpdateMany(
+		ctx,
+		bson.M{"_id": bson.M{"$ne": roadmap.ID}, "duplicates.roadmapId": roadmap.ID},
+		bson.M{"$pull": bson.M{"duplicates": bson.M{"roadmapId": roadmap.ID}}},
+	)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("could not clear stale matches"))
+	}
+
+	_, err = s.fingerprintsCol.ReplaceOne(
+		ctx,
+		bson.M{"_id": roadmap.ID},
+		models.Fingerprint{
+			RoadmapID:  roadmap.ID,
+			Signature:  signature,
+			Bands:      bands,
+			Duplicates: matches,
+			UpdatedAt:  time.Now(),
+		},
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("could not store fingerprint"))
+	}
+	return matches, nil
+}
+
+func (s *DedupServiceMongoImpl) Duplicates(ctx context.Context, roadmapId string) ([]dto.DuplicateRoadmap, error) {
+	objID, err := primitive.ObjectIDFromHex(roadmapId)
+	if err != nil {
+		return nil, err
+	}
+
+	// matches are recorded on the newer roadmap, look both ways
+	cur, err := s.fingerprintsCol.Find(
+		ctx,
+		bson.M{"$or": bson.A{bson.M{"_id": objID}, bson.M{"duplicates.roadmapId": objID}}},
+		options.Find().SetProjection(bson.M{"duplicates": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var fingerprints []models.Fingerprint
+	if err := cur.All(ctx, &fingerprints); err != nil {
+		return nil, err
+	}
+
+	similarity := map[primitive.ObjectID]float64{}
+	for _, fp := range fingerprints {
+		for _, d := range fp.Duplicates {
+			if fp.RoadmapID == objID {
+				similarity[d.RoadmapID] = d.Similarity
+			} else if d.RoadmapID == objID {
+				similarity[fp.RoadmapID] = d.Similarity
+			}
+		}
+	}
+
+	roadmaps, err := s.duplicateRoadmaps(ctx, slices.Collect(maps.Keys(similarity)))
+	if err != nil {
+		return nil, err
+	}
+	dups := []dto.DuplicateRoadmap{}
+	for id, rm := range roadmaps {
+		rm.Similarity = similarity[id]
+		dups = append(dups, rm)
+	}
+	slices.SortFunc(dups, func(a, b dto.DuplicateRoadmap) int {
+		return cmp.Or(cmp.Compare(b.Similarity, a.Similarity), cmp.Compare(a.ID, b.ID))
+	})
+	return dups, nil
+}
+
+func (s *DedupServiceMongoImpl) Clusters(ctx context.Context) ([]dto.DuplicateCluster, error) {
+	cur, err := s.fingerprintsCol.Find(
+		ctx,
+		bson.M{"duplicates.0": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"duplicates": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var fingerprints []models.Fingerprint
+	if err := cur.All(ctx, &fingerprints); err != nil {
+		return nil, err
+	}
+
+	groups := DuplicateClusters(fingerprints)
+	ids := []primitive.ObjectID{}
+	for _, g := range groups {
+		ids = append(ids, g...)
+	}
+	roadmaps, err := s.duplicateRoadmaps(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := []dto.DuplicateCluster{}
+	for _, g := range groups {
+		cluster := dto.DuplicateCluster{Roadmaps: []dto.DuplicateRoadmap{}}
+		for _, id := range g {
+			// roadmaps deleted since they were flagged are left out
+			if rm, ok := roadmaps[id]; ok {
+				cluster.Roadmaps = append(cluster.Roadmaps, rm)
+			}
+		}
+		if len(cluster.Roadmaps) < 2 {
+			continue
+		}
+		slices.SortStableFunc(cluster.Roadmaps, func(a, b dto.DuplicateRoadmap) int { return b.Upvotes - a.Upvotes })
+		cluster.Size = len(cluster.Roadmaps)
+		clusters = append(clusters, cluster)
+	}
+	slices.SortStableFunc(clusters, func(a, b dto.DuplicateCluster) int { return b.Size - a.Size })
+	return clusters, nil
+}
+
+func (s *DedupServiceMongoImpl) Backfill() error {
+	ctx := context.Background()
+
+	cur, err := s.fingerprintsCol.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	var fingerprinted []models.Fingerprint
+	if err := cur.All(ctx, &fingerprinted); err != nil {
+		return err
+	}
+	done := map[primitive.ObjectID]bool{}
+	for _, fp := range fingerprinted {
+		done[fp.RoadmapID] = true
+	}
+
+	// forks are copies on purpose, not duplicates to merge
+	cur, err = s.roadmapsCol.Find(
+		ctx,
+		bson.M{"forkedFrom": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"title": 1, "nodes.title": 1, "tags": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	flagged := 0
+	for cur.Next(ctx) {
+		var roadmap models.Roadmap
+		if err := cur.Decode(&roadmap); err != nil {
+			return err
+		}
+		if done[roadmap.ID] {
+			continue
+		}
+		if _, err := s.Flag(ctx, roadmap); err != nil {
+			return errors.Join(err, fmt.Errorf("could not flag roadmap %s", roadmap.ID.Hex()))
+		}
+		flagged++
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+
+	if flagged > 0 {
+		slog.Info(fmt.Sprintf("dedup backfill: fingerprinted %d roadmaps", flagged))
+	}
+	return s.backfillPrompts(ctx)
+}
+
+// backfillPrompts fingerprints the prompts of roadmaps generated before prompts
+// were, from their normalized prompt. Prompts without words get null bands, so
+// that they are not tried again.
+func (s *DedupServiceMongoImpl) backfillPrompts(ctx context.Context) error {
+	cur, err := s.roadmapsCol.Find(
+		ctx,
+		bson.M{"promptKey": bson.M{"$exists": true}, "promptBands": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"promptKey": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	var roadmaps []models.Roadmap
+	if err := cur.All(ctx, &roadmaps); err != nil {
+		return err
+	}
+
+	writes := make([]mongo.WriteModel, len(roadmaps))
+	for i, rm := range roadmaps {
+		signature, bands := PromptFingerprint(rm.PromptKey)
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": rm.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"promptSignature": signature, "promptBands": bands}})
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+	if _, err := s.roadmapsCol.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return errors.Join(err, errors.New("could not fingerprint prompts"))
+	}
+	slog.Info(fmt.Sprintf("dedup backfill: fingerprinted %d prompts", len(writes)))
+	return nil
+}
+
+// duplicateRoadmaps loads the roadmaps with the given IDs, by ID.
+func (s *DedupServiceMongoImpl) duplicateRoadmaps(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]dto.DuplicateRoadmap, error) {
+	roadmaps := map[primitive.ObjectID]dto.DuplicateRoadmap{}
+	if len(ids) == 0 {
+		return roadmaps, nil
+	}
+
+	opts := options.Find().SetProjection(bson.M{"title": 1, "useremail": 1, "upvotes": 1})
+	cur, err := s.roadmapsCol.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var rms []models.Roadmap
+	if err := cur.All(ctx, &rms); err != nil {
+		return nil, err
+	}
+	for _, rm := range rms {
+		roadmaps[rm.ID] = dto.DuplicateRoadmap{
+			ID:        rm.ID.Hex(),
+			Title:     rm.Title,
+			UserEmail: rm.UserEmail,
+			Upvotes:   rm.Upvotes,
+		}
+	}
+	return roadmaps, nil
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// EmailServiceMeteredImpl wraps an EmailService, counting sends by template
+// and outcome.
+type EmailServiceMeteredImpl struct {
+	next  EmailService
+	sends *prometheus.CounterVec
+}
+
+func NewEmailServiceMeteredImpl(next EmailService, reg prometheus.Registerer) EmailService {
+	return &EmailServiceMeteredImpl{
+		next: next,
+		sends: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "email_sends_total",
+			Help: "Emails sent, by template and outcome.",
+		}, []string{"template", "outcome"}),
+	}
+}
+
+func (s *EmailServiceMeteredImpl) SendAccountCreated(email string, name string) error {
+	return s.record("account_created", s.next.SendAccountCreated(email, name))
+}
+
+func (s *EmailServiceMeteredImpl) SendReminder(email string, roadmapName string, roadmapId string) error {
+	return s.record("reminder", s.next.SendReminder(email, roadmapName, roadmapId))
+}
+
+func (s *EmailServiceMeteredImpl) record(template string, err error) error {
+	outcome := "sent"
+	if err != nil {
+		outcome = "failed"
+	}
+	s.sends.WithLabelValues(template, outcome).Inc()
+	return err
+}
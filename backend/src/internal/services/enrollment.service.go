@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type EnrollmentService interface {
+	// Enroll enrolls email in a roadmap, enrolling twice is a no-op.
+	Enroll(ctx context.Context, email string, roadmapId string) error
+
+	// EnrolledRoadmapIds returns the IDs of the roadmaps email is enrolled in.
+	EnrolledRoadmapIds(ctx context.Context, email string) ([]string, error)
+
+	// Enrollments returns the enrollments of email, most recent first.
+	Enrollments(ctx context.Context, email string) ([]models.Enrollment, error)
+
+	// CompleteNode marks a node as completed, enrolling email if needed.
+	CompleteNode(ctx context.Context, email string, roadmapId string, nodeId string) error
+
+	// EnrollmentCounts returns the number of enrollments since a time, by roadmap ID.
+	EnrollmentCounts(ctx context.Context, since time.Time) (map[string]int, error)
+}
+
+type EnrollmentServiceImpl struct {
+	mongoClient    *mongo.Client
+	enrollmentsCol *mongo.Collection
+}
+
+func NewEnrollmentServiceImpl(mongoClient *mongo.Client, enrollmentsCol *mongo.Collection) EnrollmentService {
+	return &EnrollmentServiceImpl{
+		mongoClient:    mongoClient,
+		enrollmentsCol: enrollmentsCol,
+	}
+}
+
+func (s *EnrollmentServiceImpl) Enroll(ctx context.Context, email string, roadmapId string) error {
+	objID, err := primitive.ObjectIDFromHex(roadmapId)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.enrollmentsCol.UpdateOne(
+		ctx,
+		bson.M{"userEmail": email, "roadmapId": objID},
+		bson.M{"$setOnInsert": models.Enrollment{
+			UserEmail:        email,
+			RoadmapID:        objID,
+			EnrolledAt:       time.Now(),
+			CompletedNodeIds: []string{},
+		}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (s *EnrollmentServiceImpl) EnrolledRoadmapIds(ctx context.Context, email string) ([]string, error) {
+	cur, err := s.enrollmentsCol.Find(ctx, bson.M{"userEmail": email})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	ids := []string{}
+	for cur.Next(ctx) {
+		var enrollment models.Enrollment
+		if err := cur.Decode(&enrollment); err != nil {
+			return nil, err
+		}
+		ids = append(ids, enrollment.RoadmapID.Hex())
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (s *EnrollmentServiceImpl) Enrollments(ctx context.Context, email string) ([]models.Enrollment, error) {
+	opts := options.Find().SetSort(bson.M{"enrolledAt": -1})
+	cur, err := s.enrollmentsCol.Find(ctx, bson.M{"userEmail": email}, opts)
+	if err != nil {
+		return nil, err
+	}
+	enrollments := []models.Enrollment{}
+	if err := cur.All(ctx, &enrollments); err != nil {
+		return nil, err
+	}
+	return enrollments, nil
+}
+
+func (s *EnrollmentServiceImpl) CompleteNode(ctx context.Context, email string, roadmapId string, nodeId string) error {
+	objID, err := primitive.ObjectIDFromHex(roadmapId)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.enrollmentsCol.UpdateOne(
+		ctx,
+		bson.M{"userEmail": email, "roadmapId": objID},
+		bson.M{
+			"$addToSet":    bson.M{"completedNodeIds": nodeId},
+			"$setOnInsert": bson.M{"enrolledAt": time.Now()},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (s *EnrollmentServiceImpl) EnrollmentCounts(ctx context.Context, since time.Time) (map[string]int, error) {
+	cur, err := s.enrollmentsCol.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"enrolledAt": bson.M{"$gte": since}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$roadmapId", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int                `bson:"count"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, r := range rows {
+		counts[r.ID.Hex()] = r.Count
+	}
+	return counts, nil
+}
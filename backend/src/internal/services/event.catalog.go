@@ -0,0 +1,48 @@
+package services
+
+import "github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+
+// ProductEvents are the definitions of every product event. An event missing
+// here is rejected or quarantined when recorded.
+var ProductEvents = []models.EventSchema{
+	{
+		Name:        models.EventPromptRejected,
+		Version:     1,
+		Description: "A generation prompt was refused by the prompt screen.",
+		Properties: []models.PropertySchema{
+			{Name: "prompt", Type: models.PropertyString, Required: true, Description: "The prompt, PII redacted"},
+			{Name: "reasons", Type: models.PropertyStrings, Required: true, Description: "Why it was refused, e.g. deny-list:bomb"},
+		},
+	},
+	{
+		Name:        models.EventFeedImpression,
+		Version:     1,
+		Description: "A personalized feed was served.",
+		Properties: []models.PropertySchema{
+			{Name: "email", Type: models.PropertyString, Required: true, Description: "The user"},
+			{Name: "feedId", Type: models.PropertyString, Required: true, Description: "ID of the feed, to join with its clicks"},
+			{Name: "roadmapIds", Type: models.PropertyStrings, Required: true, Description: "The roadmaps shown, in order"},
+		},
+	},
+	{
+		Name:        models.EventFeedClick,
+		Version:     1,
+		Description: "A roadmap of a personalized feed was opened.",
+		Properties: []models.PropertySchema{
+			{Name: "email", Type: models.PropertyString, Required: true, Description: "The user"},
+			{Name: "feedId", Type: models.PropertyString, Required: true, Description: "ID of the feed the roadmap was in"},
+			{Name: "roadmapId", Type: models.PropertyString, Required: true, Description: "The roadmap opened"},
+			{Name: "position", Type: models.PropertyInt, Required: true, Description: "Its position in the feed, from 0"},
+		},
+	},
+	{
+		Name:        models.EventUserActivity,
+		Version:     1,
+		Description: "A user did something, see the activity kinds.",
+		Properties: []models.PropertySchema{
+			{Name: "email", Type: models.PropertyString, Required: true, Description: "The user"},
+			{Name: "kind", Type: models.PropertyString, Required: true, Description: "viewed_roadmap, completed_node, searched, enrolled, generated, forked or visited"},
+			{Name: "roadmapId", Type: models.PropertyString, Description: "The roadmap acted on, if any"},
+		},
+	},
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
+)
+
+// EventRegistry holds the schema of every event that may be recorded.
+type EventRegistry struct {
+	schemas map[string]models.EventSchema
+}
+
+// NewEventRegistry creates a registry of the given schemas, panicking on a
+// duplicate name: the schemas are defined in code, that is a programming error.
+func NewEventRegistry(schemas ...models.EventSchema) *EventRegistry {
+	r := &EventRegistry{schemas: make(map[string]models.EventSchema, len(schemas))}
+	for _, schema := range schemas {
+		if _, ok := r.schemas[schema.Name]; ok {
+			panic(fmt.Sprintf("event %q registered twice", schema.Name))
+		}
+		r.schemas[schema.Name] = schema
+	}
+	return r
+}
+
+// Schema returns the schema of the event called name, if registered.
+func (r *EventRegistry) Schema(name string) (models.EventSchema, bool) {
+	schema, ok := r.schemas[name]
+	return schema, ok
+}
+
+// Catalog returns every schema, by name.
+func (r *EventRegistry) Catalog() []models.EventSchema {
+	catalog := make([]models.EventSchema, 0, len(r.schemas))
+	for _, schema := range r.schemas {
+		catalog = append(catalog, schema)
+	}
+	slices.SortFunc(catalog, func(a, b models.EventSchema) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return catalog
+}
+
+// Validate checks metadata against the schema of the event called name: the
+// required properties must be there, and every property must be declared and
+// of its declared type.
+func (r *EventRegistry) Validate(name string, metadata map[string]any) error {
+	schema, ok := r.schemas[name]
+	if !ok {
+		return errors.Join(constants.ErrUnknownEvent, fmt.Errorf("event %q is not registered", name))
+	}
+
+	problems := []string{}
+	declared := make(map[string]bool, len(schema.Properties))
+	for _, prop := range schema.Properties {
+		declared[prop.Name] = true
+		value, ok := metadata[prop.Name]
+		if !ok || value == nil {
+			if prop.Required {
+				problems = append(problems, fmt.Sprintf("missing %q", prop.Name))
+			}
+			continue
+		}
+		if !matchesType(value, prop.Type) {
+			problems = append(problems, fmt.Sprintf("%q is %T, expected %s", prop.Name, value, prop.Type))
+		}
+	}
+	for key := range metadata {
+		if !declared[key] {
+			problems = append(problems, fmt.Sprintf("undeclared %q", key))
+		}
+	}
+
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return errors.Join(constants.ErrInvalidEvent, fmt.Errorf("%s v%d: %s", name, schema.Version, strings.Join(problems, ", ")))
+	}
+	return nil
+}
+
+// matchesType goes by kind, so named types such as models.ActivityKind pass as
+// their underlying type.
+func matchesType(value any, t models.PropertyType) bool {
+	v := reflect.ValueOf(value)
+	switch t {
+	case models.PropertyString:
+		return v.Kind() == reflect.String
+	case models.PropertyStrings:
+		return (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() == reflect.String
+	case models.PropertyInt:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return true
+		}
+		return false
+	case models.PropertyNumber:
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return true
+		}
+		return matchesType(value, models.PropertyInt)
+	case models.PropertyBool:
+		return v.Kind() == reflect.Bool
+	default:
+		return false
+	}
+}
@@ -0,0 +1,87 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
+)
+
+func TestEventRegistry_Validate(t *testing.T) {
+	registry := services.NewEventRegistry(services.ProductEvents...)
+
+	tests := []struct {
+		name     string
+		event    string
+		metadata map[string]any
+		wantErr  error
+	}{
+		{
+			name:  "valid click",
+			event: models.EventFeedClick,
+			metadata: map[string]any{
+				"email": "a@b.c", "feedId": "f", "roadmapId": "r", "position": 3,
+			},
+		},
+		{
+			name:  "named string types pass as strings",
+			event: models.EventUserActivity,
+			metadata: map[string]any{
+				"email": "a@b.c", "kind": models.ActivityViewedRoadmap,
+			},
+		},
+		{
+			name:     "unknown event",
+			event:    "user_log",
+			metadata: map[string]any{"email": "a@b.c"},
+			wantErr:  constants.ErrUnknownEvent,
+		},
+		{
+			name:     "missing required property",
+			event:    models.EventFeedImpression,
+			metadata: map[string]any{"email": "a@b.c", "feedId": "f"},
+			wantErr:  constants.ErrInvalidEvent,
+		},
+		{
+			name:  "wrong type",
+			event: models.EventFeedClick,
+			metadata: map[string]any{
+				"email": "a@b.c", "feedId": "f", "roadmapId": "r", "position": "3",
+			},
+			wantErr: constants.ErrInvalidEvent,
+		},
+		{
+			name:  "undeclared property",
+			event: models.EventPromptRejected,
+			metadata: map[string]any{
+				"prompt": "p", "reasons": []string{"deny-list:bomb"}, "ip": "127.0.0.1",
+			},
+			wantErr: constants.ErrInvalidEvent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Validate(tt.event, tt.metadata)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Validate() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEventRegistry_Catalog(t *testing.T) {
+	catalog := services.NewEventRegistry(services.ProductEvents...).Catalog()
+	if len(catalog) != len(services.ProductEvents) {
+		t.Fatalf("Catalog() has %d events, want %d", len(catalog), len(services.ProductEvents))
+	}
+	for i := 1; i < len(catalog); i++ {
+		if catalog[i-1].Name >= catalog[i].Name {
+			t.Errorf("Catalog() not sorted by name: %q before %q", catalog[i-1].Name, catalog[i].Name)
+		}
+	}
+}
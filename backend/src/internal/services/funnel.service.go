@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+)
+
+// FunnelService computes where learners drop off, and how many come back.
+type FunnelService interface {
+	// Recompute computes and stores every report.
+	Recompute() error
+
+	// Funnel returns the last computed funnel of the recent roadmaps, each at
+	// the furthest stage any of its learners reached.
+	Funnel(ctx context.Context) (models.Funnel, error)
+
+	// RoadmapFunnel returns the last computed funnel of a roadmap's learners.
+	RoadmapFunnel(ctx context.Context, roadmapId string) (models.RoadmapFunnel, error)
+
+	// Cohorts returns the last computed weekly retention cohorts.
+	Cohorts(ctx context.Context) (models.CohortReport, error)
+}
+
+// LearnerStage returns how far a learner enrolled in a roadmap went, given the
+// nodes they completed.
+func LearnerStage(roadmap models.Roadmap, completedNodeIds []string) models.FunnelStage {
+	completed := make(map[string]bool, len(completedNodeIds))
+	done := 0
+	for _, id := range completedNodeIds {
+		completed[id] = true
+	}
+	for _, node := range roadmap.Nodes {
+		if completed[node.ID] {
+			done++
+		}
+	}
+
+	switch {
+	case done > 0 && done == len(roadmap.Nodes):
+		return models.StageCompleted
+	case done == 0:
+		return models.StageEnrolled
+	}
+	for _, module := range roadmap.Modules {
+		if len(module.NodeIds) > 0 && !slices.ContainsFunc(module.NodeIds, func(id string) bool { return !completed[id] }) {
+			return models.StageModuleDone
+		}
+	}
+	return models.StageStarted
+}
+
+// RoadmapStage returns the furthest stage any learner of a roadmap reached,
+// StageGenerated if it has none.
+func RoadmapStage(roadmap models.Roadmap, enrollments []models.Enrollment) models.FunnelStage {
+	stage := models.StageGenerated
+	for _, e := range enrollments {
+		if s := LearnerStage(roadmap, e.CompletedNodeIds); slices.Index(models.FunnelStages, s) > slices.Index(models.FunnelStages, stage) {
+			stage = s
+		}
+	}
+	return stage
+}
+
+// FunnelSteps turns the number of learners who stopped at each stage into the
+// number who reached it, for the given stages in order.
+func FunnelSteps(stoppedAt map[models.FunnelStage]int, stages []models.FunnelStage) []models.FunnelStep {
+	steps := make([]models.FunnelStep, len(stages))
+	reached := 0
+	for i := len(stages) - 1; i >= 0; i-- {
+		reached += stoppedAt[stages[i]]
+		steps[i] = models.FunnelStep{Stage: stages[i], Count: reached}
+	}
+	for i := range steps {
+		steps[i].Conversion = 1
+		if i > 0 {
+			steps[i].Conversion = ratio(steps[i].Count, steps[i-1].Count)
+		}
+	}
+	return steps
+}
+
+// BuildRoadmapFunnel computes the funnel of a roadmap's learners, with the
+// nodes in roadmap order: by module, then as listed in the module.
+func BuildRoadmapFunnel(roadmap models.Roadmap, enrollments []models.Enrollment, now time.Time) models.RoadmapFunnel {
+	stoppedAt := map[models.FunnelStage]int{}
+	completions := map[string]int{}
+	for _, e := range enrollments {
+		stoppedAt[LearnerStage(roadmap, e.CompletedNodeIds)]++
+		for _, id := range e.CompletedNodeIds {
+			completions[id]++
+		}
+	}
+
+	nodes := make(map[string]models.Nodes, len(roadmap.Nodes))
+	for _, node := range roadmap.Nodes {
+		nodes[node.ID] = node
+	}
+	modules := slices.Clone(roadmap.Modules)
+	slices.SortStableFunc(modules, func(a, b models.Modules) int { return a.Order - b.Order })
+	ordered := []models.Nodes{}
+	seen := map[string]bool{}
+	for _, module := range modules {
+		for _, id := range module.NodeIds {
+			if node, ok := nodes[id]; ok && !seen[id] {
+				ordered = append(ordered, node)
+				seen[id] = true
+			}
+		}
+	}
+	for _, node := range roadmap.Nodes {
+		if !seen[node.ID] {
+			ordered = append(ordered, node)
+		}
+	}
+
+	steps := make([]models.NodeFunnelStep, len(ordered))
+	for i, node := range ordered {
+		steps[i] = models.NodeFunnelStep{
+			NodeID:    node.ID,
+			ModuleID:  node.ModuleID,
+			Title:     node.Title,
+			Completed: completions[node.ID],
+			Rate:      ratio(completions[node.ID], len(enrollments)),
+		}
+	}
+	return models.RoadmapFunnel{
+		RoadmapID:   roadmap.ID,
+		AuthorEmail: roadmap.UserEmail,
+		Steps:       FunnelSteps(stoppedAt, models.FunnelStages[1:]),
+		Nodes:       steps,
+		ComputedAt:  now,
+	}
+}
+
+// CohortWeek returns the start of the week of t, on Monday UTC.
+func CohortWeek(t time.Time) time.Time {
+	day := ActivityDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// CohortMember is a user by their first active day ever and their active days
+// since the oldest cohort.
+type CohortMember struct {
+	First time.Time
+	Days  []time.Time
+}
+
+// RetentionCohorts groups members by the week they were first active, over
+// the last weeks weeks, and counts how many were active each following week.
+func RetentionCohorts(members []CohortMember, now time.Time, weeks int) []models.RetentionCohort {
+	const week = 7 * 24 * time.Hour
+	current := CohortWeek(now)
+	oldest := current.AddDate(0, 0, -7*(weeks-1))
+
+	cohorts := make([]models.RetentionCohort, weeks)
+	for i := range cohorts {
+		cohorts[i] = models.RetentionCohort{
+			Week:   oldest.AddDate(0, 0, 7*i),
+			Active: make([]int, weeks-i),
+		}
+	}
+	for _, m := range members {
+		first := CohortWeek(m.First)
+		if first.Before(oldest) || first.After(current) {
+			continue
+		}
+		c := &cohorts[int(first.Sub(oldest)/week)]
+		c.Users++
+
+		active := map[int]bool{}
+		for _, day := range m.Days {
+			k := int(CohortWeek(day).Sub(first) / week)
+			if k >= 0 && k < len(c.Active) && !active[k] {
+				active[k] = true
+				c.Active[k]++
+			}
+		}
+	}
+	for i := range cohorts {
+		cohorts[i].Retention = make([]float64, len(cohorts[i].Active))
+		for k, n := range cohorts[i].Active {
+			cohorts[i].Retention[k] = ratio(n, cohorts[i].Users)
+		}
+	}
+	return cohorts
+}
+
+func ratio(n, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return float64(n) / float64(of)
+}
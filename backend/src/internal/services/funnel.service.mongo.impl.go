@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	funnelReportId = "funnel"
+	cohortReportId = "cohorts"
+)
+
+type FunnelServiceMongoImpl struct {
+	roadmapsCol       *mongo.Collection
+	enrollmentsCol    *mongo.Collection
+	activityDaysCol   *mongo.Collection
+	reportsCol        *mongo.Collection
+	roadmapFunnelsCol *mongo.Collection
+	window            time.Duration
+	weeks             int
+}
+
+// NewFunnelServiceMongoImpl creates a FunnelService. The funnel covers the
+// roadmaps generated over window, the cohorts the last weeks weeks.
+func NewFunnelServiceMongoImpl(
+	roadmapsCol *mongo.Collection,
+	enrollmentsCol *mongo.Collection,
+	activityDaysCol *mongo.Collection,
+	reportsCol *mongo.Collection,
+	roadmapFunnelsCol *mongo.Collection,
+	window time.Duration,
+	weeks int,
+) FunnelService {
+	return &FunnelServiceMongoImpl{
+		roadmapsCol:       roadmapsCol,
+		enrollmentsCol:    enrollmentsCol,
+		activityDaysCol:   activityDaysCol,
+		reportsCol:        reportsCol,
+		roadmapFunnelsCol: roadmapFunnelsCol,
+		window:            window,
+		weeks:             weeks,
+	}
+}
+
+func (s *FunnelServiceMongoImpl) Recompute() error {
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := s.recomputeFunnels(ctx, now); err != nil {
+		return errors.Join(err, errors.New("could not recompute funnels"))
+	}
+	if err := s.recomputeCohorts(ctx, now); err != nil {
+		return errors.Join(err, errors.New("could not recompute cohorts"))
+	}
+	return nil
+}
+
+func (s *FunnelServiceMongoImpl) recomputeFunnels(ctx context.Context, now time.Time) error {
+	cur, err := s.enrollmentsCol.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	enrollments := map[primitive.ObjectID][]models.Enrollment{}
+	for cur.Next(ctx) {
+		var e models.Enrollment
+		if err := cur.Decode(&e); err != nil {
+			cur.Close(ctx)
+			return err
+		}
+		enrollments[e.RoadmapID] = append(enrollments[e.RoadmapID], e)
+	}
+	cur.Close(ctx)
+	if err := cur.Err(); err != nil {
+		return err
+	}
+
+	opts := options.Find().SetProjection(bson.M{"useremail": 1, "modules": 1, "nodes": 1})
+	cur, err = s.roadmapsCol.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	since := now.Add(-s.window)
+	stoppedAt := map[models.FunnelStage]int{}
+	writes := []mongo.WriteModel{}
+	for cur.Next(ctx) {
+		var roadmap models.Roadmap
+		if err := cur.Decode(&roadmap); err != nil {
+			return err
+		}
+		learners := enrollments[roadmap.ID]
+
+		if !roadmap.ID.Timestamp().Before(since) {
+			stoppedAt[RoadmapStage(roadmap, learners)]++
+		}
+
+		if len(learners) == 0 {
+			continue
+		}
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": roadmap.ID}).
+			SetReplacement(BuildRoadmapFunnel(roadmap, learners, now)).
+			SetUpsert(true))
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+
+	if len(writes) > 0 {
+		_, err := s.roadmapFunnelsCol.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return err
+		}
+	}
+	return s.saveReport(ctx, funnelReportId, models.Funnel{
+		Steps:      FunnelSteps(stoppedAt, models.FunnelStages),
+		Since:      since,
+		ComputedAt: now,
+	})
+}
+
+func (s *FunnelServiceMongoImpl) recomputeCohorts(ctx context.Context, now time.Time) error {
+	oldest := CohortWeek(now).AddDate(0, 0, -7*(s.weeks-1))
+	cur, err := s.activityDaysCol.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$userEmail",
+			"first": bson.M{"$min": "$day"},
+			"days": bson.M{"$push": bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{"$day", oldest}}, "$day", "$$REMOVE",
+			}}},
+		}}},
+		{{Key: "$match", Value: bson.M{"first": bson.M{"$gte": oldest}}}},
+	})
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	members := []CohortMember{}
+	for cur.Next(ctx) {
+		var row struct {
+			First time.Time   `bson:"first"`
+			Days  []time.Time `bson:"days"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return err
+		}
+		members = append(members, CohortMember{First: row.First, Days: row.Days})
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+
+	return s.saveReport(ctx, cohortReportId, models.CohortReport{
+		Cohorts:    RetentionCohorts(members, now, s.weeks),
+		ComputedAt: now,
+	})
+}
+
+func (s *FunnelServiceMongoImpl) saveReport(ctx context.Context, id string, report any) error {
+	_, err := s.reportsCol.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"report": report}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (s *FunnelServiceMongoImpl) loadReport(ctx context.Context, id string, report any) error {
+	var doc struct {
+		Report bson.Raw `bson:"report"`
+	}
+	if err := s.reportsCol.FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+		return err
+	}
+	return bson.Unmarshal(doc.Report, report)
+}
+
+func (s *FunnelServiceMongoImpl) Funnel(ctx context.Context) (models.Funnel, error) {
+	var funnel models.Funnel
+	err := s.loadReport(ctx, funnelReportId, &funnel)
+	return funnel, err
+}
+
+func (s *FunnelServiceMongoImpl) RoadmapFunnel(ctx context.Context, roadmapId string) (models.RoadmapFunnel, error) {
+	objID, err := primitive.ObjectIDFromHex(roadmapId)
+	if err != nil {
+		return models.RoadmapFunnel{}, err
+	}
+
+	var funnel models.RoadmapFunnel
+	err = s.roadmapFunnelsCol.FindOne(ctx, bson.M{"_id": objID}).Decode(&funnel)
+	return funnel, err
+}
+
+func (s *FunnelServiceMongoImpl) Cohorts(ctx context.Context) (models.CohortReport, error) {
+	var report models.CohortReport
+	err := s.loadReport(ctx, cohortReportId, &report)
+	return report, err
+}
@@ -0,0 +1,150 @@
+package services_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+)
+
+// funnelRoadmap has two modules listed out of order: m1 holds n1 and n2, m2 holds n3.
+var funnelRoadmap = models.Roadmap{
+	UserEmail: "author@roady.dev",
+	Modules: []models.Modules{
+		{ID: "m2", Order: 2, NodeIds: []string{"n3"}},
+		{ID: "m1", Order: 1, NodeIds: []string{"n1", "n2"}},
+	},
+	Nodes: []models.Nodes{
+		{ID: "n3", ModuleID: "m2"},
+		{ID: "n1", ModuleID: "m1"},
+		{ID: "n2", ModuleID: "m1"},
+	},
+}
+
+func TestLearnerStage(t *testing.T) {
+	tests := []struct {
+		name      string
+		completed []string
+		want      models.FunnelStage
+	}{
+		{name: "nothing done", completed: nil, want: models.StageEnrolled},
+		{name: "unknown node", completed: []string{"x"}, want: models.StageEnrolled},
+		{name: "one node", completed: []string{"n1"}, want: models.StageStarted},
+		{name: "one module", completed: []string{"n3"}, want: models.StageModuleDone},
+		{name: "every node", completed: []string{"n1", "n2", "n3"}, want: models.StageCompleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := services.LearnerStage(funnelRoadmap, tt.completed); got != tt.want {
+				t.Errorf("LearnerStage() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoadmapStage(t *testing.T) {
+	tests := []struct {
+		name        string
+		enrollments []models.Enrollment
+		want        models.FunnelStage
+	}{
+		{name: "no learners", enrollments: nil, want: models.StageGenerated},
+		{
+			name:        "author not enrolled",
+			enrollments: []models.Enrollment{{UserEmail: "learner", CompletedNodeIds: []string{"n1"}}},
+			want:        models.StageStarted,
+		},
+		{
+			name: "furthest learner",
+			enrollments: []models.Enrollment{
+				{UserEmail: "author", CompletedNodeIds: []string{}},
+				{UserEmail: "b", CompletedNodeIds: []string{"n3"}},
+				{UserEmail: "c", CompletedNodeIds: []string{"n1"}},
+			},
+			want: models.StageModuleDone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := services.RoadmapStage(funnelRoadmap, tt.enrollments); got != tt.want {
+				t.Errorf("RoadmapStage() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRoadmapFunnel(t *testing.T) {
+	enrollments := []models.Enrollment{
+		{CompletedNodeIds: []string{}},
+		{CompletedNodeIds: []string{"n1"}},
+		{CompletedNodeIds: []string{"n1", "n2"}},
+		{CompletedNodeIds: []string{"n1", "n2", "n3"}},
+	}
+	funnel := services.BuildRoadmapFunnel(funnelRoadmap, enrollments, time.Now())
+
+	wantSteps := []int{4, 3, 2, 1}
+	gotSteps := []int{}
+	for _, step := range funnel.Steps {
+		gotSteps = append(gotSteps, step.Count)
+	}
+	if !slices.Equal(gotSteps, wantSteps) {
+		t.Errorf("BuildRoadmapFunnel() steps = %v, want %v", gotSteps, wantSteps)
+	}
+	if funnel.Steps[1].Conversion != 0.75 {
+		t.Errorf("BuildRoadmapFunnel() conversion = %v, want 0.75", funnel.Steps[1].Conversion)
+	}
+
+	wantNodes := []string{"n1", "n2", "n3"}
+	wantCompleted := []int{3, 2, 1}
+	gotNodes, gotCompleted := []string{}, []int{}
+	for _, node := range funnel.Nodes {
+		gotNodes = append(gotNodes, node.NodeID)
+		gotCompleted = append(gotCompleted, node.Completed)
+	}
+	if !slices.Equal(gotNodes, wantNodes) {
+		t.Errorf("BuildRoadmapFunnel() nodes = %v, want %v", gotNodes, wantNodes)
+	}
+	if !slices.Equal(gotCompleted, wantCompleted) {
+		t.Errorf("BuildRoadmapFunnel() completed = %v, want %v", gotCompleted, wantCompleted)
+	}
+}
+
+func TestRetentionCohorts(t *testing.T) {
+	// a Wednesday; weeks start on Monday the 3rd, 10th and 17th
+	now := time.Date(2025, 3, 19, 12, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+	members := []services.CohortMember{
+		{First: day(3), Days: []time.Time{day(3), day(4), day(12), day(18)}},
+		{First: day(5), Days: []time.Time{day(5), day(18)}},
+		{First: day(9), Days: []time.Time{day(9)}},
+		{First: day(17), Days: []time.Time{day(17)}},
+		{First: day(1), Days: []time.Time{day(12)}}, // before the oldest cohort
+	}
+	cohorts := services.RetentionCohorts(members, now, 3)
+
+	want := []struct {
+		week   time.Time
+		users  int
+		active []int
+	}{
+		{week: day(3), users: 3, active: []int{3, 1, 2}},
+		{week: day(10), users: 0, active: []int{0, 0}},
+		{week: day(17), users: 1, active: []int{1}},
+	}
+	if len(cohorts) != len(want) {
+		t.Fatalf("RetentionCohorts() = %d cohorts, want %d", len(cohorts), len(want))
+	}
+	for i, w := range want {
+		c := cohorts[i]
+		if !c.Week.Equal(w.week) || c.Users != w.users || !slices.Equal(c.Active, w.active) {
+			t.Errorf("cohort %d = %s %d %v, want %s %d %v", i, c.Week, c.Users, c.Active, w.week, w.users, w.active)
+		}
+	}
+	if cohorts[0].Retention[2] != 2.0/3.0 {
+		t.Errorf("retention = %v, want 2/3", cohorts[0].Retention[2])
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/sync/singleflight"
+)
+
+// GenServiceCachedImpl wraps a GenService with a Mongo backed cache keyed by
+// normalized prompt, generation options and generator version. Concurrent identical requests are
+// coalesced into a single upstream call. Refresh requests skip the cache read
+// and replace the cached generation.
+type GenServiceCachedImpl struct {
+	next             GenService
+	cacheCol         *mongo.Collection
+	generatorVersion string
+	ttl              time.Duration
+	flight           singleflight.Group
+}
+
+// generation is a cached generator response. It lives here rather than in
+// models since it embeds the generator's dto.Roadmap as-is.
+type generation struct {
+	Key              string      `bson:"_id"`
+	GeneratorVersion string      `bson:"generatorVersion"`
+	Prompt           string      `bson:"prompt"`
+	Roadmap          dto.Roadmap `bson:"roadmap"`
+	Ts               time.Time   `bson:"ts"`
+}
+
+func NewGenServiceCachedImpl(next GenService, cacheCol *mongo.Collection, generatorVersion string, ttl time.Duration) GenService {
+	return &GenServiceCachedImpl{
+		next:             next,
+		cacheCol:         cacheCol,
+		generatorVersion: generatorVersion,
+		ttl:              ttl,
+	}
+}
+
+func (g *GenServiceCachedImpl) GenerateRoadmap(ctx context.Context, req dto.GenerateRoadmapRequest) (dto.Roadmap, error) {
+	key, err := g.cacheKey(req)
+	if err != nil {
+		return dto.Roadmap{}, err
+	}
+
+	// a refresh must not share the flight of a request served from the cache
+	flightKey := key
+	if req.Refresh {
+		flightKey += ":refresh"
+	}
+
+	v, err, _ := g.flight.Do(flightKey, func() (any, error) {
+		// the call is shared between callers, so one of them going away must not cancel it
+		ctx := context.WithoutCancel(ctx)
+
+		if !req.Refresh {
+			var cached generation
+			err := g.cacheCol.FindOne(ctx, bson.M{
+				"_id": key,
+				"ts":  bson.M{"$gte": time.Now().Add(-g.ttl)},
+			}).Decode(&cached)
+			if err == nil {
+				return cached.Roadmap, nil
+			}
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				slog.Error(errors.Join(err, errors.New("could not read generation cache")).Error())
+			}
+		}
+
+		roadmap, err := g.next.GenerateRoadmap(ctx, req)
+		if err != nil {
+			return dto.Roadmap{}, err
+		}
+
+		_, err = g.cacheCol.ReplaceOne(
+			ctx,
+			bson.M{"_id": key},
+			generation{
+				Key:              key,
+				GeneratorVersion: g.generatorVersion,
+				Prompt:           common.NormalizeText(req.Prompt),
+				Roadmap:          roadmap,
+				Ts:               time.Now(),
+			},
+			options.Replace().SetUpsert(true),
+		)
+		if err != nil {
+			slog.Error(errors.Join(err, errors.New("could not write generation cache")).Error())
+		}
+
+		return roadmap, nil
+	})
+	if err != nil {
+		return dto.Roadmap{}, err
+	}
+
+	return v.(dto.Roadmap), nil
+}
+
+// RefineRoadmap is not cached, since its result depends on the current roadmap.
+func (g *GenServiceCachedImpl) RefineRoadmap(ctx context.Context, current dto.Roadmap, refinement dto.Refinement) (dto.Roadmap, error) {
+	return g.next.RefineRoadmap(ctx, current, refinement)
+}
+
+// cacheKey hashes the generator version, the normalized prompt and the
+// generation options, with focus tags normalized and sorted. Calibration hints
+// are the server's, not part of the request.
+func (g *GenServiceCachedImpl) cacheKey(req dto.GenerateRoadmapRequest) (string, error) {
+	req.Prompt = common.NormalizeText(req.Prompt)
+	req.Calibration = nil
+	req.Refresh = false
+	tags := make([]string, len(req.FocusTags))
+	for i, t := range req.FocusTags {
+		tags[i] = common.NormalizeText(t)
+	}
+	slices.Sort(tags)
+	req.FocusTags = tags
+
+	opts, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(g.generatorVersion + "\x00" + string(opts)))
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+)
+
+// GenServiceCalibratedImpl wraps a GenService, telling the generator how its
+// past estimates compare with the time learners actually took. Wrap it in the
+// cache, so that a recomputed calibration does not expire every generation.
+type GenServiceCalibratedImpl struct {
+	next               GenService
+	calibrationService CalibrationService
+}
+
+func NewGenServiceCalibratedImpl(next GenService, calibrationService CalibrationService) GenService {
+	return &GenServiceCalibratedImpl{
+		next:               next,
+		calibrationService: calibrationService,
+	}
+}
+
+func (g *GenServiceCalibratedImpl) GenerateRoadmap(ctx context.Context, req dto.GenerateRoadmapRequest) (dto.Roadmap, error) {
+	req.Calibration = nil
+	// the generator does without the hints rather than failing
+	hints, err := g.calibrationService.Hints(ctx, req.FocusTags)
+	if err != nil {
+		slog.ErrorContext(ctx, err.Error())
+	} else if hints.Overall > 0 || len(hints.Topics) > 0 {
+		req.Calibration = &hints
+	}
+	return g.next.GenerateRoadmap(ctx, req)
+}
+
+func (g *GenServiceCalibratedImpl) RefineRoadmap(ctx context.Context, current dto.Roadmap, refinement dto.Refinement) (dto.Roadmap, error) {
+	return g.next.RefineRoadmap(ctx, current, refinement)
+}
@@ -10,12 +10,26 @@ import (
 	"time"
 
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 type GenService interface {
-	GenerateRoadmap(ctx context.Context, prompt string) (dto.Roadmap, error)
+	GenerateRoadmap(ctx context.Context, req dto.GenerateRoadmapRequest) (dto.Roadmap, error)
+
+	// RefineRoadmap applies refinement to current, sending current to the generator
+	// as context. The result is merged back with MergeRefinement.
+	RefineRoadmap(ctx context.Context, current dto.Roadmap, refinement dto.Refinement) (dto.Roadmap, error)
 }
 
+// genTransport traces calls to the generator and propagates the trace context
+// to it.
+var genTransport = otelhttp.NewTransport(
+	http.DefaultTransport,
+	otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return "genservice " + r.Method + " " + r.URL.Path
+	}),
+)
+
 type GenServiceImpl struct {
 	AppPyURL string
 }
@@ -24,20 +38,37 @@ func NewGenServiceImpl(appPyURL string) GenService {
 	return &GenServiceImpl{AppPyURL: appPyURL}
 }
 
-func (g *GenServiceImpl) GenerateRoadmap(ctx context.Context, prompt string) (dto.Roadmap, error) {
-	reqBody := map[string]string{"prompt": prompt}
+func (g *GenServiceImpl) GenerateRoadmap(ctx context.Context, req dto.GenerateRoadmapRequest) (dto.Roadmap, error) {
+	return g.post(ctx, "/receber", req)
+}
+
+func (g *GenServiceImpl) RefineRoadmap(ctx context.Context, current dto.Roadmap, refinement dto.Refinement) (dto.Roadmap, error) {
+	generated, err := g.post(ctx, "/refinar", map[string]any{
+		"roadmap":     current,
+		"scope":       refinement.Scope,
+		"targetId":    refinement.TargetID,
+		"instruction": refinement.Instruction,
+	})
+	if err != nil {
+		return dto.Roadmap{}, err
+	}
+
+	return MergeRefinement(current, generated, refinement)
+}
+
+func (g *GenServiceImpl) post(ctx context.Context, path string, reqBody any) (dto.Roadmap, error) {
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
 		return dto.Roadmap{}, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", g.AppPyURL+"/receber", bytes.NewBuffer(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", g.AppPyURL+path, bytes.NewBuffer(bodyBytes))
 	if err != nil {
 		return dto.Roadmap{}, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 1 * time.Minute}
+	client := &http.Client{Timeout: 1 * time.Minute, Transport: genTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return dto.Roadmap{}, err
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// GenServiceMeteredImpl wraps a GenService, recording the latency and failures
+// of every call. Wrap the upstream service to measure the generator itself,
+// cache hits excluded.
+type GenServiceMeteredImpl struct {
+	next     GenService
+	duration *prometheus.HistogramVec
+	failures *prometheus.CounterVec
+}
+
+func NewGenServiceMeteredImpl(next GenService, reg prometheus.Registerer) GenService {
+	return &GenServiceMeteredImpl{
+		next: next,
+		duration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "generation_duration_seconds",
+			Help:    "Latency of roadmap generator calls.",
+			Buckets: []float64{1, 2.5, 5, 10, 20, 30, 45, 60, 90},
+		}, []string{"operation"}),
+		failures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "generation_failures_total",
+			Help: "Roadmap generator calls that failed.",
+		}, []string{"operation"}),
+	}
+}
+
+func (g *GenServiceMeteredImpl) GenerateRoadmap(ctx context.Context, req dto.GenerateRoadmapRequest) (dto.Roadmap, error) {
+	start := time.Now()
+	roadmap, err := g.next.GenerateRoadmap(ctx, req)
+	g.observe("generate", start, err)
+	return roadmap, err
+}
+
+func (g *GenServiceMeteredImpl) RefineRoadmap(ctx context.Context, current dto.Roadmap, refinement dto.Refinement) (dto.Roadmap, error) {
+	start := time.Now()
+	roadmap, err := g.next.RefineRoadmap(ctx, current, refinement)
+	g.observe("refine", start, err)
+	return roadmap, err
+}
+
+func (g *GenServiceMeteredImpl) observe(operation string, start time.Time, err error) {
+	g.duration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		g.failures.WithLabelValues(operation).Inc()
+	}
+}
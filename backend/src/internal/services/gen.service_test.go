@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
 )
 
@@ -25,7 +26,7 @@ func TestGenServiceImpl_GenerateRoadmap(t *testing.T) {
 			g := services.GenServiceImpl{
 				AppPyURL: "https://genservice.roady.patos.dev/",
 			}
-			got, gotErr := g.GenerateRoadmap(context.Background(), tt.prompt)
+			got, gotErr := g.GenerateRoadmap(context.Background(), dto.GenerateRoadmapRequest{Prompt: tt.prompt})
 			if gotErr != nil {
 				if !tt.wantErr {
 					t.Errorf("GenerateRoadmap() failed: %v", gotErr)
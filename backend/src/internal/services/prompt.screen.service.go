@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+)
+
+// PromptScreenService checks user prompts before they reach the generator.
+type PromptScreenService interface {
+	// Screen runs the screening pipeline over prompt. Rejections are recorded as
+	// telemetry events and returned with their reasons.
+	Screen(ctx context.Context, prompt string) (models.PromptVerdict, error)
+}
+
+// PromptClassifier is a hook for an external abuse or off-topic classifier. It
+// returns the reasons a prompt was flagged for, if any.
+type PromptClassifier interface {
+	Classify(ctx context.Context, prompt string) ([]string, error)
+}
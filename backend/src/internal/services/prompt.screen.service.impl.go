@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/common"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/logger"
+)
+
+// injectionPatterns match, over normalized text, attempts to override the
+// generator's system prompt.
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(ignore|disregard|forget)\b.{0,30}\b(previous|prior|above|all)\b.{0,20}\b(instructions?|rules|prompts?)\b`),
+	regexp.MustCompile(`\b(ignore|desconsidere|esqueca)\b.{0,30}\b(instrucoes|regras|comandos)\b`),
+	regexp.MustCompile(`\b(system prompt|prompt do sistema|developer mode|modo desenvolvedor|jailbreak)\b`),
+	regexp.MustCompile(`\b(you are now|voce agora e|a partir de agora voce)\b`),
+	regexp.MustCompile(`\b(reveal|show|print|mostre|revele)\b.{0,20}\b(instructions|prompt|instrucoes)\b`),
+	regexp.MustCompile("```|<\\|.*?\\|>|^\\s*#{2,}\\s"),
+}
+
+type PromptScreenServiceImpl struct {
+	telemetryService TelemetryService
+	classifier       PromptClassifier
+	denyList         []string
+	minLen           int
+	maxLen           int
+}
+
+// NewPromptScreenServiceImpl creates a PromptScreenService, classifier may be nil.
+func NewPromptScreenServiceImpl(telemetryService TelemetryService, classifier PromptClassifier, denyList []string, minLen int, maxLen int) PromptScreenService {
+	terms := []string{}
+	for _, t := range denyList {
+		if t = common.NormalizeText(t); t != "" {
+			terms = append(terms, t)
+		}
+	}
+
+	return &PromptScreenServiceImpl{
+		telemetryService: telemetryService,
+		classifier:       classifier,
+		denyList:         terms,
+		minLen:           minLen,
+		maxLen:           maxLen,
+	}
+}
+
+func (s *PromptScreenServiceImpl) Screen(ctx context.Context, prompt string) (models.PromptVerdict, error) {
+	reasons := []string{}
+	normalized := common.NormalizeText(prompt)
+
+	length := utf8.RuneCountInString(strings.TrimSpace(prompt))
+	if length < s.minLen {
+		reasons = append(reasons, models.PromptTooShort)
+	}
+	if length > s.maxLen {
+		reasons = append(reasons, models.PromptTooLong)
+	}
+
+	tokens := strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := " " + strings.Join(tokens, " ") + " "
+	for _, term := range s.denyList {
+		if strings.Contains(words, " "+term+" ") {
+			reasons = append(reasons, models.PromptDeniedTerm)
+			break
+		}
+	}
+
+	for _, re := range injectionPatterns {
+		if re.MatchString(normalized) {
+			reasons = append(reasons, models.PromptInjection)
+			break
+		}
+	}
+
+	if len(reasons) == 0 && s.classifier != nil {
+		flags, err := s.classifier.Classify(ctx, prompt)
+		if err != nil {
+			return models.PromptVerdict{}, err
+		}
+		for _, f := range flags {
+			reasons = append(reasons, models.PromptFlagged+":"+f)
+		}
+	}
+
+	if len(reasons) == 0 {
+		return models.PromptVerdict{Allowed: true}, nil
+	}
+
+	s.telemetryService.RecordEvent(
+		ctx,
+		models.EventPromptRejected,
+		map[string]any{
+			"prompt":  logger.RedactPII(prompt),
+			"reasons": reasons,
+		},
+		map[string]string{},
+	)
+
+	return models.PromptVerdict{Allowed: false, Reasons: reasons}, nil
+}
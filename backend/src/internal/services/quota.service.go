@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+)
+
+// QuotaService meters the expensive generation path. Limits are enforced with
+// per-user and per-IP token buckets, a daily cap by plan tier and a daily cap
+// per IP. Emails are not authenticated, so only the IP limits hold against a
+// caller rotating them; the user limits share a user's quota across IPs.
+type QuotaService interface {
+	// AllowGeneration consumes one generation for email and ip. The decision is
+	// returned even when not allowed, so callers can set rate limit headers.
+	// Nothing is consumed when not allowed.
+	AllowGeneration(ctx context.Context, email string, ip string) (models.QuotaDecision, error)
+
+	// RefundGeneration gives back what an allowed decision consumed, for
+	// generations that did not happen.
+	RefundGeneration(ctx context.Context, decision models.QuotaDecision) error
+
+	// Override returns the admin override for a user, if any.
+	Override(ctx context.Context, email string) (models.QuotaOverride, error)
+
+	// SetOverride replaces the plan limits of a user.
+	SetOverride(ctx context.Context, override models.QuotaOverride) error
+
+	// DeleteOverride reverts a user to their plan limits.
+	DeleteOverride(ctx context.Context, email string) error
+}
+
+// TokenBucket holds up to Capacity tokens, refilled at RefillPerSec.
+type TokenBucket struct {
+	Capacity     float64
+	RefillPerSec float64
+}
+
+// Refill returns the tokens of a bucket that held tokens elapsed ago.
+func (b TokenBucket) Refill(tokens float64, elapsed time.Duration) float64 {
+	return min(b.Capacity, tokens+max(elapsed.Seconds(), 0)*b.RefillPerSec)
+}
+
+// Take refills a bucket that held tokens elapsed ago and takes one token from
+// it, returning the tokens left and whether there was one to take.
+func (b TokenBucket) Take(tokens float64, elapsed time.Duration) (float64, bool) {
+	tokens = b.Refill(tokens, elapsed)
+	if tokens < 1 {
+		return tokens, false
+	}
+	return tokens - 1, true
+}
+
+// Wait returns how long a bucket holding tokens takes to hold one.
+func (b TokenBucket) Wait(tokens float64) time.Duration {
+	if tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - tokens) / b.RefillPerSec * float64(time.Second))
+}
+
+// QuotaLimits configures a QuotaService. A zero IpDailyCap disables the cap.
+type QuotaLimits struct {
+	UserBucket TokenBucket
+	IpBucket   TokenBucket
+	DailyCaps  map[models.PlanTier]int
+	IpDailyCap int
+}
+
+// DailyCapDecision decides on a generation after used others today, out of
+// limit. Daily caps reset at midnight UTC.
+func DailyCapDecision(used int, limit int, now time.Time) models.QuotaDecision {
+	now = now.UTC()
+	reset := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(24 * time.Hour)
+	if used >= limit {
+		return models.QuotaDecision{Allowed: false, Limit: limit, Remaining: 0, Reset: reset, RetryAfter: reset.Sub(now)}
+	}
+	return models.QuotaDecision{Allowed: true, Limit: limit, Remaining: limit - used - 1, Reset: reset}
+}
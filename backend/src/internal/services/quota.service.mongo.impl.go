@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// QuotaServiceMongoImpl keeps every counter in Mongo, so limits hold across replicas.
+type QuotaServiceMongoImpl struct {
+	mongoClient  *mongo.Client
+	bucketsCol   *mongo.Collection
+	usageCol     *mongo.Collection
+	overridesCol *mongo.Collection
+	usersCol     *mongo.Collection
+	limits       QuotaLimits
+}
+
+func NewQuotaServiceMongoImpl(mongoClient *mongo.Client, bucketsCol, usageCol, overridesCol, usersCol *mongo.Collection, limits QuotaLimits) QuotaService {
+	return &QuotaServiceMongoImpl{
+		mongoClient:  mongoClient,
+		bucketsCol:   bucketsCol,
+		usageCol:     usageCol,
+		overridesCol: overridesCol,
+		usersCol:     usersCol,
+		limits:       limits,
+	}
+}
+
+// quotaCasRetries bounds the attempts at updating a contended token bucket.
+const quotaCasRetries = 5
+
+type tokenBucketDoc struct {
+	Key       string    `bson:"_id"`
+	Tokens    float64   `bson:"tokens"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+}
+
+type dailyUsageDoc struct {
+	Key       string    `bson:"_id"`
+	Count     int       `bson:"count"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// quotaBucket is a token bucket and its key.
+type quotaBucket struct {
+	key    string
+	bucket TokenBucket
+}
+
+// quotaCap is a daily cap and its key.
+type quotaCap struct {
+	key   string
+	limit int
+}
+
+func (s *QuotaServiceMongoImpl) AllowGeneration(ctx context.Context, email string, ip string) (models.QuotaDecision, error) {
+	now := time.Now().UTC()
+	day := now.Format(time.DateOnly)
+
+	override, err := s.Override(ctx, email)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.QuotaDecision{}, err
+	}
+
+	// the IP limits apply to everyone, an email proves nothing
+	buckets := []quotaBucket{{"ip:" + ip, s.limits.IpBucket}}
+	caps := []quotaCap{}
+	if s.limits.IpDailyCap > 0 {
+		caps = append(caps, quotaCap{"ip:" + ip + ":" + day, s.limits.IpDailyCap})
+	}
+	if !override.Unlimited {
+		buckets = append(buckets, quotaBucket{"user:" + email, s.limits.UserBucket})
+		limit := override.DailyLimit
+		if limit == 0 {
+			limit, err = s.dailyCap(ctx, email)
+			if err != nil {
+				return models.QuotaDecision{}, err
+			}
+		}
+		caps = append(caps, quotaCap{email + ":" + day, limit})
+	}
+
+	consumed := models.QuotaDecision{}
+	// refund gives back what was consumed before a limit denied
+	refund := func(denied models.QuotaDecision) (models.QuotaDecision, error) {
+		if err := s.RefundGeneration(context.WithoutCancel(ctx), consumed); err != nil {
+			return models.QuotaDecision{}, errors.Join(err, errors.New("could not refund quota"))
+		}
+		return denied, nil
+	}
+
+	for _, b := range buckets {
+		tokens, ok, err := s.take(ctx, b.key, b.bucket, now)
+		if err != nil {
+			_, refundErr := refund(models.QuotaDecision{})
+			return models.QuotaDecision{}, errors.Join(err, refundErr)
+		}
+		if !ok {
+			wait := b.bucket.Wait(tokens)
+			return refund(models.QuotaDecision{
+				Allowed:    false,
+				Limit:      int(b.bucket.Capacity),
+				Remaining:  0,
+				Reset:      now.Add(wait),
+				RetryAfter: wait,
+			})
+		}
+		consumed.Buckets = append(consumed.Buckets, b.key)
+	}
+
+	// the last cap is the user's when there is one, the one reported
+	decision := DailyCapDecision(0, math.MaxInt32, now)
+	decision.Remaining = math.MaxInt32
+	for _, c := range caps {
+		used, ok, err := s.count(ctx, c, now)
+		if err != nil {
+			_, refundErr := refund(models.QuotaDecision{})
+			return models.QuotaDecision{}, errors.Join(err, refundErr)
+		}
+		if !ok {
+			return refund(DailyCapDecision(c.limit, c.limit, now))
+		}
+		consumed.Usage = append(consumed.Usage, c.key)
+		decision = DailyCapDecision(used, c.limit, now)
+	}
+
+	decision.Buckets = consumed.Buckets
+	decision.Usage = consumed.Usage
+	return decision, nil
+}
+
+// take takes one token from a bucket, returning the tokens left. Buckets are
+// updated with compare-and-swap, the token math living in TokenBucket.
+func (s *QuotaServiceMongoImpl) take(ctx context.Context, key string, bucket TokenBucket, now time.Time) (float64, bool, error) {
+	for range quotaCasRetries {
+		var doc tokenBucketDoc
+		err := s.bucketsCol.FindOne(ctx, bson.M{"_id": key}).Decode(&doc)
+		found := err == nil
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			doc = tokenBucketDoc{Key: key, Tokens: bucket.Capacity, UpdatedAt: now}
+		} else if err != nil {
+			return 0, false, err
+		}
+
+		tokens, ok := bucket.Take(doc.Tokens, now.Sub(doc.UpdatedAt))
+		if !ok {
+			return tokens, false, nil
+		}
+
+		if !found {
+			_, err := s.bucketsCol.InsertOne(ctx, tokenBucketDoc{Key: key, Tokens: tokens, UpdatedAt: now})
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+			return tokens, err == nil, err
+		}
+		res, err := s.bucketsCol.UpdateOne(
+			ctx,
+			bson.M{"_id": key, "tokens": doc.Tokens, "updatedAt": doc.UpdatedAt},
+			bson.M{"$set": bson.M{"tokens": tokens, "updatedAt": now}},
+		)
+		if err != nil {
+			return 0, false, err
+		}
+		if res.MatchedCount == 1 {
+			return tokens, true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("token bucket %s is contended", key)
+}
+
+// count counts one generation against a daily cap, returning how many were
+// counted before it. The conditional upsert keeps concurrent requests under it.
+func (s *QuotaServiceMongoImpl) count(ctx context.Context, c quotaCap, now time.Time) (int, bool, error) {
+	var usage dailyUsageDoc
+	err := s.usageCol.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": c.key, "count": bson.M{"$lt": c.limit}},
+		bson.M{
+			"$inc":         bson.M{"count": 1},
+			"$setOnInsert": bson.M{"expiresAt": DailyCapDecision(0, c.limit, now).Reset},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&usage)
+	if mongo.IsDuplicateKeyError(err) {
+		// the day's document exists but did not match the filter: the cap is reached
+		return c.limit, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return usage.Count - 1, true, nil
+}
+
+func (s *QuotaServiceMongoImpl) RefundGeneration(ctx context.Context, decision models.QuotaDecision) error {
+	for _, key := range decision.Buckets {
+		bucket := s.limits.UserBucket
+		if strings.HasPrefix(key, "ip:") {
+			bucket = s.limits.IpBucket
+		}
+		_, err := s.bucketsCol.UpdateOne(ctx, bson.M{"_id": key}, mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"tokens": bson.M{"$min": bson.A{bucket.Capacity, bson.M{"$add": bson.A{"$tokens", 1}}}}}}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, key := range decision.Usage {
+		_, err := s.usageCol.UpdateOne(ctx, bson.M{"_id": key, "count": bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{"count": -1}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *QuotaServiceMongoImpl) dailyCap(ctx context.Context, email string) (int, error) {
+	var user models.User
+	err := s.usersCol.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, err
+	}
+
+	if limit, ok := s.limits.DailyCaps[user.Plan]; ok {
+		return limit, nil
+	}
+	return s.limits.DailyCaps[models.PlanFree], nil
+}
+
+func (s *QuotaServiceMongoImpl) Override(ctx context.Context, email string) (models.QuotaOverride, error) {
+	var override models.QuotaOverride
+	err := s.overridesCol.FindOne(ctx, bson.M{"_id": email}).Decode(&override)
+	return override, err
+}
+
+func (s *QuotaServiceMongoImpl) SetOverride(ctx context.Context, override models.QuotaOverride) error {
+	override.UpdatedAt = time.Now()
+	_, err := s.overridesCol.ReplaceOne(
+		ctx,
+		bson.M{"_id": override.Email},
+		override,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+func (s *QuotaServiceMongoImpl) DeleteOverride(ctx context.Context, email string) error {
+	_, err := s.overridesCol.DeleteOne(ctx, bson.M{"_id": email})
+	return err
+}
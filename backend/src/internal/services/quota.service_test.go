@@ -0,0 +1,100 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+)
+
+func TestTokenBucket_Refill(t *testing.T) {
+	bucket := services.TokenBucket{Capacity: 3, RefillPerSec: 1.0 / 60}
+	tests := []struct {
+		name    string
+		tokens  float64
+		elapsed time.Duration
+		want    float64
+	}{
+		{name: "no time elapsed", tokens: 1.5, elapsed: 0, want: 1.5},
+		{name: "one token a minute", tokens: 0, elapsed: 90 * time.Second, want: 1.5},
+		{name: "capped at capacity", tokens: 2, elapsed: time.Hour, want: 3},
+		{name: "clock going backwards", tokens: 1, elapsed: -time.Minute, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bucket.Refill(tt.tokens, tt.elapsed); got != tt.want {
+				t.Errorf("Refill() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenBucket_Take(t *testing.T) {
+	bucket := services.TokenBucket{Capacity: 3, RefillPerSec: 1.0 / 60}
+	tests := []struct {
+		name     string
+		tokens   float64
+		elapsed  time.Duration
+		want     float64
+		wantOk   bool
+		wantWait time.Duration
+	}{
+		{name: "full bucket", tokens: 3, want: 2, wantOk: true},
+		{name: "refilled just enough", tokens: 0.5, elapsed: 30 * time.Second, want: 0, wantOk: true},
+		{name: "empty bucket", tokens: 0, elapsed: 15 * time.Second, want: 0.25, wantOk: false, wantWait: 45 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := bucket.Take(tt.tokens, tt.elapsed)
+			if got != tt.want || ok != tt.wantOk {
+				t.Fatalf("Take() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+			if wait := bucket.Wait(got); !ok && wait != tt.wantWait {
+				t.Errorf("Wait() = %v, want %v", wait, tt.wantWait)
+			}
+		})
+	}
+
+	// taking drains a full bucket in Capacity takes
+	tokens, taken := bucket.Capacity, 0
+	for {
+		left, ok := bucket.Take(tokens, 0)
+		if !ok {
+			break
+		}
+		tokens = left
+		taken++
+	}
+	if taken != 3 {
+		t.Errorf("took %d tokens from a full bucket, want 3", taken)
+	}
+}
+
+func TestDailyCapDecision(t *testing.T) {
+	now := time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)
+	midnight := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		used          int
+		limit         int
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{name: "first of the day", used: 0, limit: 10, wantAllowed: true, wantRemaining: 9},
+		{name: "last one", used: 9, limit: 10, wantAllowed: true, wantRemaining: 0},
+		{name: "cap reached", used: 10, limit: 10, wantAllowed: false, wantRemaining: 0, wantRetry: 6 * time.Hour},
+		{name: "no generations allowed", used: 0, limit: 0, wantAllowed: false, wantRemaining: 0, wantRetry: 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := services.DailyCapDecision(tt.used, tt.limit, now)
+			if got.Allowed != tt.wantAllowed || got.Remaining != tt.wantRemaining || got.RetryAfter != tt.wantRetry {
+				t.Errorf("DailyCapDecision() = %+v, want allowed %v, remaining %d, retry after %v", got, tt.wantAllowed, tt.wantRemaining, tt.wantRetry)
+			}
+			if got.Limit != tt.limit || !got.Reset.Equal(midnight) {
+				t.Errorf("DailyCapDecision() limit %d reset %v, want %d, %v", got.Limit, got.Reset, tt.limit, midnight)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+)
+
+// Weights of each feed signal, every signal scores between 0 and 1.
+const (
+	feedTagWeight        = 3.0
+	feedTopicWeight      = 2.0
+	feedLevelWeight      = 1.0
+	feedPopularityWeight = 1.0
+)
+
+// nextSkillLevel is the difficulty a user at a level is ready to move on to.
+var nextSkillLevel = map[models.SkillLevel]models.SkillLevel{
+	models.SkillBeginner:     models.SkillIntermediate,
+	models.SkillIntermediate: models.SkillAdvanced,
+}
+
+// feedSignal is the weighted score of one signal, along with its explanation.
+type feedSignal struct {
+	score  float64
+	reason dto.FeedReason
+}
+
+// FeedProfile is what a feed is personalized on.
+type FeedProfile struct {
+	SkillLevel models.SkillLevel
+
+	// Tags weighs the tags of the enrolled roadmaps by how many of them carry
+	// each, TagSources names an enrolled roadmap carrying each tag.
+	Tags       map[string]float64
+	TagSources map[string]string
+
+	// Topics maps the terms of completed node titles to a node title.
+	Topics map[string]string
+}
+
+// NewFeedProfile builds the profile of user from the roadmaps they are
+// enrolled in and their enrollments, most recent first.
+func NewFeedProfile(user models.User, enrolled []models.Roadmap, enrollments []models.Enrollment) FeedProfile {
+	profile := FeedProfile{
+		SkillLevel: user.SkillLevel,
+		Tags:       map[string]float64{},
+		TagSources: map[string]string{},
+		Topics:     map[string]string{},
+	}
+
+	byId := map[string]models.Roadmap{}
+	for _, rm := range enrolled {
+		byId[rm.ID.Hex()] = rm
+	}
+
+	for _, e := range enrollments {
+		rm, ok := byId[e.RoadmapID.Hex()]
+		if !ok {
+			continue
+		}
+		for _, tag := range rm.Tags {
+			profile.Tags[tag]++
+			if _, ok := profile.TagSources[tag]; !ok {
+				profile.TagSources[tag] = rm.Title
+			}
+		}
+		for _, n := range rm.Nodes {
+			if !slices.Contains(e.CompletedNodeIds, n.ID) {
+				continue
+			}
+			for term := range termFrequencies([]string{n.Title}) {
+				if _, ok := profile.Topics[term]; !ok {
+					profile.Topics[term] = n.Title
+				}
+			}
+		}
+	}
+
+	return profile
+}
+
+// ScoreFeed ranks candidates for profile, best first, blending its affinity
+// to each with popularity (between 0 and 1, by roadmap ID). Every item is
+// explained by the signal that contributed the most to its score.
+func ScoreFeed(profile FeedProfile, candidates []models.Roadmap, popularity map[string]float64, size int) []dto.FeedItem {
+	totalTags := 0.0
+	for _, w := range profile.Tags {
+		totalTags += w
+	}
+
+	items := []dto.FeedItem{}
+	for _, rm := range candidates {
+		signals := []feedSignal{}
+
+		if totalTags > 0 {
+			score, tag := 0.0, ""
+			for _, t := range rm.Tags {
+				score += profile.Tags[t]
+				if profile.Tags[t] > profile.Tags[tag] {
+					tag = t
+				}
+			}
+			if tag != "" {
+				signals = append(signals, feedSignal{
+					feedTagWeight * min(1, score/totalTags),
+					dto.FeedReason{Kind: dto.FeedReasonLearning, Text: fmt.Sprintf("Because you're learning %s", profile.TagSources[tag])},
+				})
+			}
+		}
+
+		if len(profile.Topics) > 0 {
+			terms := termFrequencies(append([]string{rm.Title}, nodeTitles(rm.Nodes)...))
+			matched := []string{}
+			for term := range terms {
+				if _, ok := profile.Topics[term]; ok {
+					matched = append(matched, term)
+				}
+			}
+			if len(matched) > 0 {
+				slices.Sort(matched)
+				signals = append(signals, feedSignal{
+					feedTopicWeight * float64(len(matched)) / float64(len(terms)),
+					dto.FeedReason{Kind: dto.FeedReasonCompleted, Text: fmt.Sprintf("Because you completed %s", profile.Topics[matched[0]])},
+				})
+			}
+		}
+
+		if profile.SkillLevel != "" {
+			level := 0.0
+			switch models.SkillLevel(rm.Difficulty) {
+			case profile.SkillLevel:
+				level = 1
+			case nextSkillLevel[profile.SkillLevel]:
+				level = 0.5
+			}
+			if rm.Difficulty == "mixed" {
+				level = 0.5
+			}
+			if level > 0 {
+				signals = append(signals, feedSignal{
+					feedLevelWeight * level,
+					dto.FeedReason{Kind: dto.FeedReasonLevel, Text: fmt.Sprintf("Matches your %s level", profile.SkillLevel)},
+				})
+			}
+		}
+
+		signals = append(signals, feedSignal{
+			feedPopularityWeight * popularity[rm.ID.Hex()],
+			dto.FeedReason{Kind: dto.FeedReasonPopular, Text: "Popular right now"},
+		})
+
+		item := dto.FeedItem{
+			ID:         rm.ID.Hex(),
+			Title:      rm.Title,
+			Difficulty: rm.Difficulty,
+			Tags:       rm.Tags,
+			Upvotes:    rm.Upvotes,
+		}
+		best := -1.0
+		for _, sig := range signals {
+			item.Score += sig.score
+			if sig.score > best {
+				best = sig.score
+				item.Reason = sig.reason
+			}
+		}
+		items = append(items, item)
+	}
+
+	slices.SortStableFunc(items, func(a, b dto.FeedItem) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		if a.Upvotes != b.Upvotes {
+			return b.Upvotes - a.Upvotes
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return items[:min(size, len(items))]
+}
+
+// feedPopularity scores candidates between 0 and 1, half on upvotes and half
+// on recent enrollments, both on a log scale.
+func feedPopularity(candidates []models.Roadmap, recentEnrollments map[string]int) map[string]float64 {
+	maxUpvotes, maxRecent := 0.0, 0.0
+	for _, rm := range candidates {
+		maxUpvotes = max(maxUpvotes, math.Log1p(float64(rm.Upvotes)))
+		maxRecent = max(maxRecent, math.Log1p(float64(recentEnrollments[rm.ID.Hex()])))
+	}
+
+	popularity := make(map[string]float64, len(candidates))
+	for _, rm := range candidates {
+		p := 0.0
+		if maxUpvotes > 0 {
+			p += 0.5 * math.Log1p(float64(rm.Upvotes)) / maxUpvotes
+		}
+		if maxRecent > 0 {
+			p += 0.5 * math.Log1p(float64(recentEnrollments[rm.ID.Hex()])) / maxRecent
+		}
+		popularity[rm.ID.Hex()] = p
+	}
+	return popularity
+}
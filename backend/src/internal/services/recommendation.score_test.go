@@ -0,0 +1,76 @@
+package services_test
+
+import (
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+)
+
+func TestScoreFeed(t *testing.T) {
+	docker := models.Roadmap{
+		ID:    primitive.NewObjectID(),
+		Title: "Docker do zero",
+		Tags:  []string{"docker", "devops"},
+		Nodes: []models.Nodes{{ID: "n1", Title: "Imagens"}, {ID: "n2", Title: "Volumes"}},
+	}
+	enrollments := []models.Enrollment{{RoadmapID: docker.ID, CompletedNodeIds: []string{"n1"}}}
+
+	kubernetes := models.Roadmap{ID: primitive.NewObjectID(), Title: "Kubernetes", Tags: []string{"devops", "docker"}, Difficulty: "advanced"}
+	registry := models.Roadmap{ID: primitive.NewObjectID(), Title: "Imagens e registries", Difficulty: "intermediate"}
+	python := models.Roadmap{ID: primitive.NewObjectID(), Title: "Python", Difficulty: "beginner", Upvotes: 1000}
+	hyped := models.Roadmap{ID: primitive.NewObjectID(), Title: "Rust", Difficulty: "advanced", Upvotes: 5000}
+	candidates := []models.Roadmap{python, hyped, registry, kubernetes}
+	popularity := map[string]float64{hyped.ID.Hex(): 0.9, python.ID.Hex(): 0.2}
+
+	tests := []struct {
+		name  string
+		user  models.User
+		want  []string
+		kinds []dto.FeedReasonKind
+	}{
+		{
+			name:  "affinity beats popularity",
+			user:  models.User{},
+			want:  []string{kubernetes.ID.Hex(), registry.ID.Hex(), hyped.ID.Hex(), python.ID.Hex()},
+			kinds: []dto.FeedReasonKind{dto.FeedReasonLearning, dto.FeedReasonCompleted, dto.FeedReasonPopular, dto.FeedReasonPopular},
+		},
+		{
+			name:  "skill level",
+			user:  models.User{SkillLevel: models.SkillBeginner},
+			want:  []string{kubernetes.ID.Hex(), registry.ID.Hex(), python.ID.Hex(), hyped.ID.Hex()},
+			kinds: []dto.FeedReasonKind{dto.FeedReasonLearning, dto.FeedReasonCompleted, dto.FeedReasonLevel, dto.FeedReasonPopular},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := services.NewFeedProfile(tt.user, []models.Roadmap{docker}, enrollments)
+			items := services.ScoreFeed(profile, candidates, popularity, 10)
+
+			ids := []string{}
+			kinds := []dto.FeedReasonKind{}
+			for _, item := range items {
+				ids = append(ids, item.ID)
+				kinds = append(kinds, item.Reason.Kind)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("ScoreFeed() = %v, want %v", ids, tt.want)
+			}
+			if !slices.Equal(kinds, tt.kinds) {
+				t.Errorf("ScoreFeed() reasons = %v, want %v", kinds, tt.kinds)
+			}
+		})
+	}
+
+	t.Run("explanation names the enrolled roadmap", func(t *testing.T) {
+		profile := services.NewFeedProfile(models.User{}, []models.Roadmap{docker}, enrollments)
+		items := services.ScoreFeed(profile, []models.Roadmap{kubernetes}, nil, 1)
+		if len(items) != 1 || items[0].Reason.Text != "Because you're learning Docker do zero" {
+			t.Errorf("ScoreFeed() = %+v, want a reason naming Docker do zero", items)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+)
+
+// RecommendationService personalizes roadmap listings per user.
+type RecommendationService interface {
+	// Feed ranks the roadmaps q.Email is not enrolled in nor owns, explaining
+	// each one, and records the impression.
+	Feed(ctx context.Context, q dto.FeedQuery) (dto.Feed, error)
+
+	// RecordClick records a click on a feed item, so feed quality can be measured.
+	RecordClick(ctx context.Context, email string, click dto.FeedClick) error
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type RecommendationServiceImpl struct {
+	roadmapService    RoadmapService
+	enrollmentService EnrollmentService
+	userService       UserService
+	telemetryService  TelemetryService
+	trendingWindow    time.Duration
+}
+
+// NewRecommendationServiceImpl creates a RecommendationService, popularity
+// counts the enrollments within trendingWindow.
+func NewRecommendationServiceImpl(roadmapService RoadmapService, enrollmentService EnrollmentService, userService UserService, telemetryService TelemetryService, trendingWindow time.Duration) RecommendationService {
+	return &RecommendationServiceImpl{
+		roadmapService:    roadmapService,
+		enrollmentService: enrollmentService,
+		userService:       userService,
+		telemetryService:  telemetryService,
+		trendingWindow:    trendingWindow,
+	}
+}
+
+func (s *RecommendationServiceImpl) Feed(ctx context.Context, q dto.FeedQuery) (dto.Feed, error) {
+	user, err := s.userService.User(ctx, q.Email)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		user = models.User{Email: q.Email}
+	} else if err != nil {
+		return dto.Feed{}, err
+	}
+
+	enrollments, err := s.enrollmentService.Enrollments(ctx, q.Email)
+	if err != nil {
+		return dto.Feed{}, err
+	}
+	enrolledIds := map[primitive.ObjectID]bool{}
+	for _, e := range enrollments {
+		enrolledIds[e.RoadmapID] = true
+	}
+
+	roadmaps, err := s.roadmapService.Roadmaps(ctx, models.SortUpvotes)
+	if err != nil {
+		return dto.Feed{}, err
+	}
+	enrolled := []models.Roadmap{}
+	candidates := []models.Roadmap{}
+	for _, rm := range roadmaps {
+		switch {
+		case enrolledIds[rm.ID]:
+			enrolled = append(enrolled, rm)
+		case rm.UserEmail != q.Email:
+			candidates = append(candidates, rm)
+		}
+	}
+
+	recent, err := s.enrollmentService.EnrollmentCounts(ctx, time.Now().Add(-s.trendingWindow))
+	if err != nil {
+		return dto.Feed{}, err
+	}
+
+	feed := dto.Feed{
+		FeedID: primitive.NewObjectID().Hex(),
+		Items:  ScoreFeed(NewFeedProfile(user, enrolled, enrollments), candidates, feedPopularity(candidates, recent), q.Size),
+	}
+
+	roadmapIds := make([]string, len(feed.Items))
+	for i, item := range feed.Items {
+		roadmapIds[i] = item.ID
+	}
+	s.telemetryService.RecordEvent(
+		ctx,
+		models.EventFeedImpression,
+		map[string]any{
+			"email":      q.Email,
+			"feedId":     feed.FeedID,
+			"roadmapIds": roadmapIds,
+		},
+		map[string]string{},
+	)
+
+	return feed, nil
+}
+
+func (s *RecommendationServiceImpl) RecordClick(ctx context.Context, email string, click dto.FeedClick) error {
+	return s.telemetryService.RecordEvent(
+		ctx,
+		models.EventFeedClick,
+		map[string]any{
+			"email":     email,
+			"feedId":    click.FeedID,
+			"roadmapId": click.RoadmapID,
+			"position":  click.Position,
+		},
+		map[string]string{},
+	)
+}
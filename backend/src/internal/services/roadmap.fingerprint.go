@@ -0,0 +1,162 @@
+package services
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	minHashSize  = 60
+	minHashBands = 20 // of 3 rows, pairs above ~0.4 Jaccard likely share a band
+	minHashRows  = minHashSize / minHashBands
+)
+
+// fingerprintFeatures is the set roadmaps are compared on: the words and word
+// pairs of the title and node titles, and the tags.
+func fingerprintFeatures(roadmap models.Roadmap) []string {
+	features := map[string]bool{}
+	for _, text := range append([]string{roadmap.Title}, nodeTitles(roadmap.Nodes)...) {
+		addWordFeatures(features, text)
+	}
+	for _, tag := range roadmap.Tags {
+		features["#"+common.Slugify(tag)] = true
+	}
+	return slices.Sorted(maps.Keys(features))
+}
+
+// addWordFeatures adds the words and word pairs of text, stop words aside.
+func addWordFeatures(features map[string]bool, text string) {
+	words := slices.DeleteFunc(tokenize(text), func(w string) bool { return similarityStopWords[w] })
+	for i, w := range words {
+		features[w] = true
+		if i > 0 {
+			features[words[i-1]+" "+w] = true
+		}
+	}
+}
+
+// mix64 is the splitmix64 finalizer, deriving the hash functions of the
+// signature from a single FNV hash per feature.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// RoadmapFingerprint returns the MinHash signature of a roadmap and its LSH band
+// keys. Roadmaps sharing a band key are near-duplicate candidates.
+func RoadmapFingerprint(roadmap models.Roadmap) ([]uint32, []string) {
+	return minHash(fingerprintFeatures(roadmap))
+}
+
+// PromptFingerprint returns the MinHash signature of a generation prompt, on its
+// words and word pairs, and its LSH band keys. Both are nil for a prompt made of
+// stop words only, which would match any other.
+func PromptFingerprint(prompt string) ([]uint32, []string) {
+	features := map[string]bool{}
+	addWordFeatures(features, prompt)
+	if len(features) == 0 {
+		return nil, nil
+	}
+	return minHash(slices.Sorted(maps.Keys(features)))
+}
+
+// minHash returns the MinHash signature of a feature set and its LSH band keys.
+func minHash(features []string) ([]uint32, []string) {
+	signature := make([]uint32, minHashSize)
+	for i := range signature {
+		signature[i] = ^uint32(0)
+	}
+	for _, f := range features {
+		h := fnv.New64a()
+		h.Write([]byte(f))
+		base := h.Sum64()
+		for i := range signature {
+			if v := uint32(mix64(base + uint64(i)*0x9e3779b97f4a7c15)); v < signature[i] {
+				signature[i] = v
+			}
+		}
+	}
+
+	bands := make([]string, minHashBands)
+	buf := make([]byte, 4*minHashRows)
+	for b := range bands {
+		for r := 0; r < minHashRows; r++ {
+			binary.LittleEndian.PutUint32(buf[4*r:], signature[b*minHashRows+r])
+		}
+		h := fnv.New64a()
+		h.Write(buf)
+		bands[b] = fmt.Sprintf("%d:%016x", b, h.Sum64())
+	}
+	return signature, bands
+}
+
+// SignatureSimilarity estimates the Jaccard similarity of two roadmaps from
+// their signatures.
+func SignatureSimilarity(a []uint32, b []uint32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+// DuplicateClusters groups roadmaps linked by a duplicate match, directly or
+// through others. Clusters are ordered by their smallest ID, as are their members.
+func DuplicateClusters(fingerprints []models.Fingerprint) [][]primitive.ObjectID {
+	parent := map[primitive.ObjectID]primitive.ObjectID{}
+	var find func(id primitive.ObjectID) primitive.ObjectID
+	find = func(id primitive.ObjectID) primitive.ObjectID {
+		p, ok := parent[id]
+		if !ok {
+			parent[id] = id
+			return id
+		}
+		if p == id {
+			return id
+		}
+		root := find(p)
+		parent[id] = root
+		return root
+	}
+	for _, fp := range fingerprints {
+		for _, d := range fp.Duplicates {
+			a, b := find(fp.RoadmapID), find(d.RoadmapID)
+			if a != b {
+				parent[b] = a
+			}
+		}
+	}
+
+	groups := map[primitive.ObjectID][]primitive.ObjectID{}
+	for id := range parent {
+		root := find(id)
+		groups[root] = append(groups[root], id)
+	}
+	compare := func(a, b primitive.ObjectID) int { return strings.Compare(a.Hex(), b.Hex()) }
+	clusters := [][]primitive.ObjectID{}
+	for _, ids := range groups {
+		if len(ids) > 1 {
+			slices.SortFunc(ids, compare)
+			clusters = append(clusters, ids)
+		}
+	}
+	slices.SortFunc(clusters, func(a, b []primitive.ObjectID) int { return compare(a[0], b[0]) })
+	return clusters
+}
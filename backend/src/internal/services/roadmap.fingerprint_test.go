@@ -0,0 +1,100 @@
+package services_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func fingerprintRoadmap(title string, tags []string, nodes ...string) models.Roadmap {
+	rm := models.Roadmap{ID: primitive.NewObjectID(), Title: title, Tags: tags}
+	for _, n := range nodes {
+		rm.Nodes = append(rm.Nodes, models.Nodes{Title: n})
+	}
+	return rm
+}
+
+func TestRoadmapFingerprint(t *testing.T) {
+	devops := fingerprintRoadmap(
+		"Become a DevOps engineer",
+		[]string{"devops", "docker"},
+		"Linux basics", "Shell scripting", "Docker containers", "Kubernetes clusters", "CI/CD pipelines", "Monitoring with Prometheus",
+	)
+	devopsAgain := fingerprintRoadmap(
+		"Become a DevOps Engineer",
+		[]string{"DevOps", "docker"},
+		"Linux basics", "Shell scripting", "Docker containers", "Kubernetes clusters", "CI/CD pipelines", "Monitoring and alerting",
+	)
+	frontend := fingerprintRoadmap(
+		"Frontend development with React",
+		[]string{"react", "javascript"},
+		"HTML and CSS", "JavaScript fundamentals", "React components", "State management", "Testing React apps",
+	)
+
+	sigA, bandsA := services.RoadmapFingerprint(devops)
+	sigB, bandsB := services.RoadmapFingerprint(devopsAgain)
+	sigC, bandsC := services.RoadmapFingerprint(frontend)
+
+	if sim := services.SignatureSimilarity(sigA, sigB); sim < 0.6 {
+		t.Errorf("SignatureSimilarity() of near-duplicates = %g, want at least 0.6", sim)
+	}
+	if sim := services.SignatureSimilarity(sigA, sigC); sim > 0.2 {
+		t.Errorf("SignatureSimilarity() of unrelated roadmaps = %g, want at most 0.2", sim)
+	}
+	if !slices.ContainsFunc(bandsA, func(b string) bool { return slices.Contains(bandsB, b) }) {
+		t.Error("near-duplicates share no band")
+	}
+	if slices.ContainsFunc(bandsA, func(b string) bool { return slices.Contains(bandsC, b) }) {
+		t.Error("unrelated roadmaps share a band")
+	}
+
+	again, _ := services.RoadmapFingerprint(devops)
+	if !slices.Equal(sigA, again) {
+		t.Error("RoadmapFingerprint() is not deterministic")
+	}
+}
+
+func TestDuplicateClusters(t *testing.T) {
+	ids := make([]primitive.ObjectID, 6)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID()
+	}
+	match := func(id primitive.ObjectID) models.DuplicateMatch {
+		return models.DuplicateMatch{RoadmapID: id, Similarity: 0.8}
+	}
+
+	clusters := services.DuplicateClusters([]models.Fingerprint{
+		{RoadmapID: ids[1], Duplicates: []models.DuplicateMatch{match(ids[0])}},
+		{RoadmapID: ids[2], Duplicates: []models.DuplicateMatch{match(ids[1])}},
+		{RoadmapID: ids[4], Duplicates: []models.DuplicateMatch{match(ids[3])}},
+		{RoadmapID: ids[5], Duplicates: []models.DuplicateMatch{}},
+	})
+
+	want := [][]primitive.ObjectID{{ids[0], ids[1], ids[2]}, {ids[3], ids[4]}}
+	if !slices.EqualFunc(clusters, want, slices.Equal) {
+		t.Errorf("DuplicateClusters() = %v, want %v", clusters, want)
+	}
+}
+
+func TestPromptFingerprint(t *testing.T) {
+	sigA, bandsA := services.PromptFingerprint("Quero virar um engenheiro DevOps")
+	sigB, _ := services.PromptFingerprint("quero virar engenheiro devops!!")
+	sigC, _ := services.PromptFingerprint("aprender react para frontend")
+
+	if sim := services.SignatureSimilarity(sigA, sigB); sim < 0.8 {
+		t.Errorf("SignatureSimilarity() of near-identical prompts = %g, want at least 0.8", sim)
+	}
+	if sim := services.SignatureSimilarity(sigA, sigC); sim > 0.2 {
+		t.Errorf("SignatureSimilarity() of unrelated prompts = %g, want at most 0.2", sim)
+	}
+	if len(bandsA) == 0 {
+		t.Error("PromptFingerprint() returned no bands")
+	}
+
+	if sig, bands := services.PromptFingerprint("!!!"); sig != nil || bands != nil {
+		t.Errorf("PromptFingerprint() of a prompt without words = %v, %v, want nil", sig, bands)
+	}
+}
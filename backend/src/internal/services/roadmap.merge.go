@@ -0,0 +1,328 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/common"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
+)
+
+// MergeRefinement merges the generator response for a refinement back into
+// current. Modules and nodes untouched by the refinement keep their IDs, and
+// regenerated ones keep theirs whenever they can be matched by title.
+func MergeRefinement(current dto.Roadmap, generated dto.Roadmap, refinement dto.Refinement) (dto.Roadmap, error) {
+	var merged dto.Roadmap
+	var err error
+
+	switch refinement.Scope {
+	case dto.RefinementScopeModule:
+		merged, err = mergeModule(current, generated, refinement.TargetID)
+	case dto.RefinementScopeNode:
+		merged, err = mergeExpandedNode(current, generated, refinement.TargetID)
+	default:
+		merged = mergeRoadmap(current, generated)
+	}
+	if err != nil {
+		return dto.Roadmap{}, err
+	}
+
+	merged.EstimatedTotalMinutes = 0
+	for _, n := range merged.Nodes {
+		merged.EstimatedTotalMinutes += n.EstimatedMinutes
+	}
+
+	return merged, nil
+}
+
+func mergeModule(current dto.Roadmap, generated dto.Roadmap, moduleId string) (dto.Roadmap, error) {
+	mIdx := slices.IndexFunc(current.Modules, func(m models.Modules) bool { return m.ID == moduleId })
+	if mIdx < 0 {
+		return dto.Roadmap{}, constants.ErrRefinementTarget
+	}
+	curModule := current.Modules[mIdx]
+
+	gIdx := slices.IndexFunc(generated.Modules, func(m models.Modules) bool { return m.ID == moduleId })
+	if gIdx < 0 {
+		gIdx = slices.IndexFunc(generated.Modules, func(m models.Modules) bool { return m.Order == curModule.Order })
+	}
+	if gIdx < 0 {
+		return dto.Roadmap{}, errors.New("generator response is missing the regenerated module")
+	}
+	genModule := generated.Modules[gIdx]
+
+	oldIds := map[string]bool{}
+	oldByTitle := map[string]string{}
+	for _, n := range current.Nodes {
+		if n.ModuleID == moduleId {
+			oldIds[n.ID] = true
+			oldByTitle[common.NormalizeText(n.Title)] = n.ID
+		}
+	}
+
+	alloc := newIdAllocator(current)
+	taken := map[string]bool{}
+	remap := map[string]string{}
+	var genNodes []models.Nodes
+	for _, n := range generated.Nodes {
+		if n.ModuleID != genModule.ID && !slices.Contains(genModule.NodeIds, n.ID) {
+			continue
+		}
+		genNodes = append(genNodes, n)
+
+		id, ok := oldByTitle[common.NormalizeText(n.Title)]
+		switch {
+		case ok && !taken[id]:
+		case oldIds[n.ID] && !taken[n.ID]:
+			id = n.ID
+		default:
+			id = alloc.claim(moduleId)
+		}
+		taken[id] = true
+		remap[n.ID] = id
+	}
+
+	existing := map[string]bool{}
+	for _, n := range current.Nodes {
+		if n.ModuleID != moduleId {
+			existing[n.ID] = true
+		}
+	}
+
+	newNodes := make([]models.Nodes, 0, len(genNodes))
+	nodeIds := make([]string, 0, len(genNodes))
+	for _, n := range genNodes {
+		n.ID = remap[n.ID]
+		n.ModuleID = moduleId
+		n.PrereqNodeIds = remapPrereqs(n.PrereqNodeIds, remap, existing)
+		newNodes = append(newNodes, n)
+		nodeIds = append(nodeIds, n.ID)
+	}
+
+	merged := current
+	merged.Modules = slices.Clone(current.Modules)
+	merged.Modules[mIdx] = models.Modules{
+		ID:      moduleId,
+		Title:   genModule.Title,
+		Order:   curModule.Order,
+		Summary: genModule.Summary,
+		NodeIds: nodeIds,
+	}
+
+	merged.Nodes = nil
+	inserted := false
+	for _, n := range current.Nodes {
+		if n.ModuleID == moduleId {
+			if !inserted {
+				merged.Nodes = append(merged.Nodes, newNodes...)
+				inserted = true
+			}
+			continue
+		}
+		n.PrereqNodeIds = remapPrereqs(n.PrereqNodeIds, nil, mergeSets(existing, taken))
+		merged.Nodes = append(merged.Nodes, n)
+	}
+	if !inserted {
+		merged.Nodes = append(merged.Nodes, newNodes...)
+	}
+
+	return merged, nil
+}
+
+func mergeExpandedNode(current dto.Roadmap, generated dto.Roadmap, nodeId string) (dto.Roadmap, error) {
+	nIdx := slices.IndexFunc(current.Nodes, func(n models.Nodes) bool { return n.ID == nodeId })
+	if nIdx < 0 {
+		return dto.Roadmap{}, constants.ErrRefinementTarget
+	}
+	target := current.Nodes[nIdx]
+
+	existingIds := map[string]bool{}
+	existingTitles := map[string]bool{}
+	for _, n := range current.Nodes {
+		existingIds[n.ID] = true
+		existingTitles[common.NormalizeText(n.Title)] = true
+	}
+
+	alloc := newIdAllocator(current)
+	remap := map[string]string{}
+	var subNodes []models.Nodes
+	for _, n := range generated.Nodes {
+		if existingIds[n.ID] || existingTitles[common.NormalizeText(n.Title)] {
+			continue
+		}
+		// nodes elsewhere are the generator renumbering or rewording the rest
+		// of the roadmap, not sub-nodes
+		if n.ModuleID != target.ModuleID && !slices.Contains(n.PrereqNodeIds, any(nodeId)) {
+			continue
+		}
+		remap[n.ID] = alloc.claim(nodeId)
+		subNodes = append(subNodes, n)
+	}
+	if len(subNodes) == 0 {
+		return dto.Roadmap{}, errors.New("generator response has no new nodes")
+	}
+
+	subIds := make([]string, 0, len(subNodes))
+	for i, n := range subNodes {
+		prereqs := remapPrereqs(n.PrereqNodeIds, remap, existingIds)
+		if !slices.Contains(prereqs, any(nodeId)) {
+			prereqs = append([]any{nodeId}, prereqs...)
+		}
+		n.ID = remap[n.ID]
+		n.ModuleID = target.ModuleID
+		n.PrereqNodeIds = prereqs
+		subNodes[i] = n
+		subIds = append(subIds, n.ID)
+	}
+
+	merged := current
+	merged.Nodes = slices.Insert(slices.Clone(current.Nodes), nIdx+1, subNodes...)
+	merged.Modules = slices.Clone(current.Modules)
+	for i, m := range merged.Modules {
+		if m.ID != target.ModuleID {
+			continue
+		}
+		pos := slices.Index(m.NodeIds, nodeId) + 1
+		if pos == 0 {
+			pos = len(m.NodeIds)
+		}
+		m.NodeIds = slices.Insert(slices.Clone(m.NodeIds), pos, subIds...)
+		merged.Modules[i] = m
+	}
+
+	return merged, nil
+}
+
+func mergeRoadmap(current dto.Roadmap, generated dto.Roadmap) dto.Roadmap {
+	curModules := map[string]string{}
+	for _, m := range current.Modules {
+		curModules[common.NormalizeText(m.Title)] = m.ID
+	}
+	curNodes := map[string]string{}
+	for _, n := range current.Nodes {
+		curNodes[common.NormalizeText(n.Title)] = n.ID
+	}
+
+	alloc := newIdAllocator(current)
+	moduleRemap := stableIds(generated.Modules, func(m models.Modules) (string, string) { return m.ID, m.Title }, curModules, alloc, "module")
+	nodeRemap := stableIds(generated.Nodes, func(n models.Nodes) (string, string) { return n.ID, n.Title }, curNodes, alloc, "node")
+
+	merged := generated
+	merged.ID = current.ID
+	merged.Modules = make([]models.Modules, len(generated.Modules))
+	for i, m := range generated.Modules {
+		m.ID = moduleRemap[m.ID]
+		nodeIds := make([]string, 0, len(m.NodeIds))
+		for _, id := range m.NodeIds {
+			if newId, ok := nodeRemap[id]; ok {
+				nodeIds = append(nodeIds, newId)
+			}
+		}
+		m.NodeIds = nodeIds
+		merged.Modules[i] = m
+	}
+	merged.Nodes = make([]models.Nodes, len(generated.Nodes))
+	for i, n := range generated.Nodes {
+		n.ID = nodeRemap[n.ID]
+		if id, ok := moduleRemap[n.ModuleID]; ok {
+			n.ModuleID = id
+		}
+		n.PrereqNodeIds = remapPrereqs(n.PrereqNodeIds, nodeRemap, nil)
+		merged.Nodes[i] = n
+	}
+
+	return merged
+}
+
+// stableIds maps the generated IDs of items to their final IDs: the ID of the
+// current item with the same title if there is one, otherwise the generated ID
+// itself or a fresh one if that is already used.
+func stableIds[T any](items []T, key func(T) (string, string), current map[string]string, alloc *idAllocator, prefix string) map[string]string {
+	remap := map[string]string{}
+	taken := map[string]bool{}
+	for _, item := range items {
+		id, title := key(item)
+		if curId, ok := current[common.NormalizeText(title)]; ok && !taken[curId] {
+			remap[id] = curId
+			taken[curId] = true
+		}
+	}
+	for _, item := range items {
+		id, _ := key(item)
+		if _, ok := remap[id]; ok {
+			continue
+		}
+		if id != "" && alloc.claimId(id) {
+			remap[id] = id
+		} else {
+			remap[id] = alloc.claim(prefix)
+		}
+	}
+	return remap
+}
+
+// remapPrereqs rewrites prereqs through remap, keeping IDs found in keep and
+// dropping the rest.
+func remapPrereqs(prereqs []any, remap map[string]string, keep map[string]bool) []any {
+	out := []any{}
+	for _, p := range prereqs {
+		id, ok := p.(string)
+		if !ok {
+			continue
+		}
+		if newId, ok := remap[id]; ok {
+			out = append(out, newId)
+		} else if keep[id] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func mergeSets(a map[string]bool, b map[string]bool) map[string]bool {
+	out := make(map[string]bool, len(a)+len(b))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		out[k] = v
+	}
+	return out
+}
+
+// idAllocator hands out module and node IDs that are not yet used in a roadmap.
+type idAllocator struct {
+	used map[string]bool
+}
+
+func newIdAllocator(roadmap dto.Roadmap) *idAllocator {
+	used := map[string]bool{}
+	for _, m := range roadmap.Modules {
+		used[m.ID] = true
+	}
+	for _, n := range roadmap.Nodes {
+		used[n.ID] = true
+	}
+	return &idAllocator{used: used}
+}
+
+func (a *idAllocator) claim(prefix string) string {
+	for i := 1; ; i++ {
+		id := fmt.Sprintf("%s-%d", prefix, i)
+		if !a.used[id] {
+			a.used[id] = true
+			return id
+		}
+	}
+}
+
+func (a *idAllocator) claimId(id string) bool {
+	if a.used[id] {
+		return false
+	}
+	a.used[id] = true
+	return true
+}
@@ -0,0 +1,149 @@
+package services_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
+)
+
+func baseRoadmap() dto.Roadmap {
+	return dto.Roadmap{
+		Title: "DevOps",
+		Modules: []models.Modules{
+			{ID: "m1", Title: "Linux", Order: 0, NodeIds: []string{"n1", "n2"}},
+			{ID: "m2", Title: "Containers", Order: 1, NodeIds: []string{"n3"}},
+		},
+		Nodes: []models.Nodes{
+			{ID: "n1", ModuleID: "m1", Title: "Shell", EstimatedMinutes: 30},
+			{ID: "n2", ModuleID: "m1", Title: "Permissions", EstimatedMinutes: 20},
+			{ID: "n3", ModuleID: "m2", Title: "Docker", EstimatedMinutes: 60, PrereqNodeIds: []any{"n2"}},
+		},
+	}
+}
+
+func nodeIds(r dto.Roadmap) []string {
+	ids := []string{}
+	for _, n := range r.Nodes {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
+
+func TestMergeRefinement(t *testing.T) {
+	tests := []struct {
+		name       string
+		generated  dto.Roadmap
+		refinement dto.Refinement
+		wantNodes  []string
+		wantTotal  int
+		wantErr    error
+	}{
+		{
+			name: "regenerate module keeps matching titles",
+			generated: dto.Roadmap{
+				Modules: []models.Modules{{ID: "m1", Title: "Linux basics", NodeIds: []string{"a", "b"}}},
+				Nodes: []models.Nodes{
+					{ID: "a", ModuleID: "m1", Title: "shell", EstimatedMinutes: 40},
+					{ID: "b", ModuleID: "m1", Title: "Processes", EstimatedMinutes: 25},
+				},
+			},
+			refinement: dto.Refinement{Scope: dto.RefinementScopeModule, TargetID: "m1"},
+			wantNodes:  []string{"n1", "m1-1", "n3"},
+			wantTotal:  125,
+		},
+		{
+			name: "expand node inserts sub-nodes after it",
+			generated: dto.Roadmap{
+				Nodes: []models.Nodes{
+					{ID: "n3", ModuleID: "m2", Title: "Docker"},
+					{ID: "x", ModuleID: "m2", Title: "Dockerfile", EstimatedMinutes: 15},
+					{ID: "y", ModuleID: "m2", Title: "Compose", EstimatedMinutes: 15, PrereqNodeIds: []any{"x"}},
+				},
+			},
+			refinement: dto.Refinement{Scope: dto.RefinementScopeNode, TargetID: "n3"},
+			wantNodes:  []string{"n1", "n2", "n3", "n3-1", "n3-2"},
+			wantTotal:  140,
+		},
+		{
+			name: "expand node ignores renumbered nodes of other modules",
+			generated: dto.Roadmap{
+				Nodes: []models.Nodes{
+					{ID: "1", ModuleID: "m1", Title: "Shell scripting", EstimatedMinutes: 30},
+					{ID: "2", ModuleID: "m1", Title: "File permissions", EstimatedMinutes: 20},
+					{ID: "3", ModuleID: "m2", Title: "Docker"},
+					{ID: "4", ModuleID: "m2", Title: "Dockerfile", EstimatedMinutes: 15},
+					{ID: "5", ModuleID: "m3", Title: "Registries", EstimatedMinutes: 10, PrereqNodeIds: []any{"n3"}},
+				},
+			},
+			refinement: dto.Refinement{Scope: dto.RefinementScopeNode, TargetID: "n3"},
+			wantNodes:  []string{"n1", "n2", "n3", "n3-1", "n3-2"},
+			wantTotal:  135,
+		},
+		{
+			name: "whole roadmap keeps ids by title",
+			generated: dto.Roadmap{
+				Modules: []models.Modules{{ID: "m2", Title: "Linux", NodeIds: []string{"n3", "k"}}},
+				Nodes: []models.Nodes{
+					{ID: "n3", ModuleID: "m2", Title: "Shell", EstimatedMinutes: 10},
+					{ID: "k", ModuleID: "m2", Title: "Kubernetes", EstimatedMinutes: 10, PrereqNodeIds: []any{"n3"}},
+				},
+			},
+			refinement: dto.Refinement{Scope: dto.RefinementScopeRoadmap},
+			wantNodes:  []string{"n1", "k"},
+			wantTotal:  20,
+		},
+		{
+			name:       "unknown module",
+			refinement: dto.Refinement{Scope: dto.RefinementScopeModule, TargetID: "nope"},
+			wantErr:    constants.ErrRefinementTarget,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := services.MergeRefinement(baseRoadmap(), tt.generated, tt.refinement)
+			if tt.wantErr != nil {
+				if !errors.Is(gotErr, tt.wantErr) {
+					t.Fatalf("MergeRefinement() error = %v, want %v", gotErr, tt.wantErr)
+				}
+				return
+			}
+			if gotErr != nil {
+				t.Fatalf("MergeRefinement() failed: %v", gotErr)
+			}
+			if ids := nodeIds(got); !slices.Equal(ids, tt.wantNodes) {
+				t.Errorf("MergeRefinement() nodes = %v, want %v", ids, tt.wantNodes)
+			}
+			if got.EstimatedTotalMinutes != tt.wantTotal {
+				t.Errorf("MergeRefinement() total = %d, want %d", got.EstimatedTotalMinutes, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestMergeRefinement_ExpandNodeKeepsPrereqs(t *testing.T) {
+	generated := dto.Roadmap{
+		Nodes: []models.Nodes{
+			{ID: "x", ModuleID: "m2", Title: "Dockerfile", EstimatedMinutes: 15, PrereqNodeIds: []any{"n1", "gone"}},
+			{ID: "y", ModuleID: "m2", Title: "Compose", EstimatedMinutes: 15, PrereqNodeIds: []any{"x"}},
+		},
+	}
+	got, err := services.MergeRefinement(baseRoadmap(), generated, dto.Refinement{Scope: dto.RefinementScopeNode, TargetID: "n3"})
+	if err != nil {
+		t.Fatalf("MergeRefinement() failed: %v", err)
+	}
+
+	want := map[string][]any{
+		"n3-1": {"n3", "n1"},
+		"n3-2": {"n3", "n3-1"},
+	}
+	for _, n := range got.Nodes {
+		if prereqs, ok := want[n.ID]; ok && !slices.Equal(n.PrereqNodeIds, prereqs) {
+			t.Errorf("node %s prereqs = %v, want %v", n.ID, n.PrereqNodeIds, prereqs)
+		}
+	}
+}
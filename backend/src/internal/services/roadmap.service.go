@@ -2,10 +2,19 @@ package services
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"math/rand"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/common"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -13,27 +22,105 @@ import (
 )
 
 type RoadmapService interface {
-	Roadmaps(ctx context.Context) ([]models.Roadmap, error)
+	Roadmaps(ctx context.Context, sort models.RoadmapSort) ([]models.Roadmap, error)
 	Roadmap(ctx context.Context, roadmapId string) (models.Roadmap, error)
-	RoadmapsFromUser(ctx context.Context, email string) ([]models.Roadmap, error)
+	RoadmapsFromUser(ctx context.Context, email string, sort models.RoadmapSort) ([]models.Roadmap, error)
 
-	Insert(ctx context.Context, email string, roadmap dto.Roadmap) (models.Roadmap, error)
+	// RoadmapsByTag returns the roadmaps tagged with tag or any of its synonyms,
+	// which roadmaps keep until the tag backfill rewrites them.
+	RoadmapsByTag(ctx context.Context, tag models.Tag, sort models.RoadmapSort) ([]models.Roadmap, error)
+
+	// RoadmapByPrompt returns the most upvoted roadmap generated with the same
+	// options from a prompt that normalizes to the same text as req's, else the
+	// one from the most similar near-identical prompt.
+	RoadmapByPrompt(ctx context.Context, req dto.GenerateRoadmapRequest) (models.Roadmap, error)
+
+	// SuggestRoadmaps completes prefix into roadmap titles and tags with a prefix
+	// query, for when the search backend is unavailable.
+	SuggestRoadmaps(ctx context.Context, prefix string, size int) (dto.Suggestions, error)
+
+	// SimilarRoadmaps ranks stored roadmaps by TF-IDF cosine similarity to roadmap,
+	// for when the search backend is unavailable. Those of q.Email and excludedIds
+	// are left out.
+	SimilarRoadmaps(ctx context.Context, roadmap models.Roadmap, q dto.SimilarQuery, excludedIds []string) ([]dto.SearchResult, error)
+
+	// Insert stores a roadmap generated for req.
+	Insert(ctx context.Context, email string, req dto.GenerateRoadmapRequest, roadmap dto.Roadmap) (models.Roadmap, error)
+
+	// Update replaces the generated content of a roadmap, keeping its owner, upvotes
+	// and prompt.
+	Update(ctx context.Context, roadmapId string, roadmap dto.Roadmap) (models.Roadmap, error)
+
+	// Fork copies a roadmap for email to follow and edit as their own.
+	Fork(ctx context.Context, roadmapId string, email string) (models.Roadmap, error)
+
+	// SetTags replaces the tags of a roadmap, leaving the rest untouched.
+	SetTags(ctx context.Context, roadmapId string, tags []string) error
+
+	// TagCounts returns the number of roadmaps using each tag.
+	TagCounts(ctx context.Context) (map[string]int, error)
 }
 
 type RoadmapServiceImpl struct {
 	mongoClient *mongo.Client
 	roadmapsCol *mongo.Collection
+	outboxCol   *mongo.Collection
+
+	promptThreshold  float64
+	promptCandidates int64
 }
 
-func NewRoadmapServiceImpl(mongoClient *mongo.Client, roadmapsCol *mongo.Collection) RoadmapService {
+// NewRoadmapServiceImpl creates a RoadmapService recording every write in
+// outboxCol, for the search index to pick it up when change streams are unavailable.
+// Prompts are near-identical from an estimated Jaccard similarity of
+// promptThreshold, out of at most promptCandidates sharing an LSH band.
+func NewRoadmapServiceImpl(mongoClient *mongo.Client, roadmapsCol *mongo.Collection, outboxCol *mongo.Collection, promptThreshold float64, promptCandidates int64) RoadmapService {
 	return &RoadmapServiceImpl{
-		mongoClient: mongoClient,
-		roadmapsCol: roadmapsCol,
+		mongoClient:      mongoClient,
+		roadmapsCol:      roadmapsCol,
+		outboxCol:        outboxCol,
+		promptThreshold:  promptThreshold,
+		promptCandidates: promptCandidates,
+	}
+}
+
+// GenerationOptionsKey returns the canonical form of the generation options of
+// req, with focus tags slugified and sorted. Empty if none is set.
+func GenerationOptionsKey(req dto.GenerateRoadmapRequest) string {
+	parts := []string{}
+	add := func(name string, value string) {
+		if value != "" {
+			parts = append(parts, name+"="+value)
+		}
+	}
+	add("difficulty", req.Difficulty)
+	add("skillLevel", req.SkillLevel)
+	if req.WeeklyHours > 0 {
+		add("weeklyHours", strconv.Itoa(req.WeeklyHours))
+	}
+	if req.TimeBudgetHours > 0 {
+		add("timeBudgetHours", strconv.Itoa(req.TimeBudgetHours))
+	}
+	add("language", req.Language)
+	tags := make([]string, 0, len(req.FocusTags))
+	for _, t := range req.FocusTags {
+		tags = append(tags, common.Slugify(t))
 	}
+	slices.Sort(tags)
+	add("focusTags", strings.Join(slices.Compact(tags), ","))
+	return strings.Join(parts, ";")
 }
 
-func (s *RoadmapServiceImpl) Roadmaps(ctx context.Context) ([]models.Roadmap, error) {
-	opts := options.Find().SetSort(bson.M{"upvotes": -1})
+// roadmapSort translates sort into a Mongo sort, upvotes by default.
+func roadmapSort(sort models.RoadmapSort) bson.D {
+	if sort == models.SortTrending {
+		return bson.D{{Key: "trending.week", Value: -1}, {Key: "upvotes", Value: -1}}
+	}
+	return bson.D{{Key: "upvotes", Value: -1}}
+}
+
+func (s *RoadmapServiceImpl) Roadmaps(ctx context.Context, sort models.RoadmapSort) ([]models.Roadmap, error) {
+	opts := options.Find().SetSort(roadmapSort(sort))
 	cur, err := s.roadmapsCol.Find(ctx, bson.M{}, opts)
 	if err != nil {
 		return nil, err
@@ -64,9 +151,32 @@ func (s *RoadmapServiceImpl) Roadmap(ctx context.Context, roadmapId string) (mod
 	return roadmap, err
 }
 
-func (s *RoadmapServiceImpl) RoadmapsFromUser(ctx context.Context, email string) ([]models.Roadmap, error) {
-	opts := options.Find().SetSort(bson.M{"upvotes": -1})
-	cur, err := s.roadmapsCol.Find(ctx, bson.M{"userEmail": email}, opts)
+func (s *RoadmapServiceImpl) RoadmapsFromUser(ctx context.Context, email string, sort models.RoadmapSort) ([]models.Roadmap, error) {
+	opts := options.Find().SetSort(roadmapSort(sort))
+	cur, err := s.roadmapsCol.Find(ctx, bson.M{"useremail": email}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var roadmaps []models.Roadmap
+	for cur.Next(ctx) {
+		var roadmap models.Roadmap
+		if err := cur.Decode(&roadmap); err != nil {
+			return nil, err
+		}
+		roadmaps = append(roadmaps, roadmap)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+	return roadmaps, nil
+}
+
+func (s *RoadmapServiceImpl) RoadmapsByTag(ctx context.Context, tag models.Tag, sort models.RoadmapSort) ([]models.Roadmap, error) {
+	slugs := append([]string{tag.Slug}, tag.Synonyms...)
+	opts := options.Find().SetSort(roadmapSort(sort))
+	cur, err := s.roadmapsCol.Find(ctx, bson.M{"tags": bson.M{"$in": slugs}}, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +196,139 @@ func (s *RoadmapServiceImpl) RoadmapsFromUser(ctx context.Context, email string)
 	return roadmaps, nil
 }
 
-func (s *RoadmapServiceImpl) Insert(ctx context.Context, email string, roadmap dto.Roadmap) (models.Roadmap, error) {
+func (s *RoadmapServiceImpl) RoadmapByPrompt(ctx context.Context, req dto.GenerateRoadmapRequest) (models.Roadmap, error) {
+	// roadmaps generated without options have no optionsKey, which null matches
+	var optionsKey any
+	if key := GenerationOptionsKey(req); key != "" {
+		optionsKey = key
+	}
+
+	opts := options.FindOne().SetSort(bson.M{"upvotes": -1})
+	var roadmap models.Roadmap
+	err := s.roadmapsCol.FindOne(
+		ctx,
+		bson.M{"promptKey": common.NormalizeText(req.Prompt), "optionsKey": optionsKey},
+		opts,
+	).Decode(&roadmap)
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return roadmap, err
+	}
+
+	signature, bands := PromptFingerprint(req.Prompt)
+	if len(bands) == 0 {
+		return models.Roadmap{}, mongo.ErrNoDocuments
+	}
+	cur, err := s.roadmapsCol.Find(
+		ctx,
+		bson.M{"promptBands": bson.M{"$in": bands}, "optionsKey": optionsKey},
+		options.Find().SetLimit(s.promptCandidates),
+	)
+	if err != nil {
+		return models.Roadmap{}, err
+	}
+	var candidates []models.Roadmap
+	if err := cur.All(ctx, &candidates); err != nil {
+		return models.Roadmap{}, err
+	}
+
+	best, bestSim := models.Roadmap{}, 0.0
+	for _, c := range candidates {
+		sim := SignatureSimilarity(signature, c.PromptSignature)
+		if sim < s.promptThreshold {
+			continue
+		}
+		if sim > bestSim || (sim == bestSim && c.Upvotes > best.Upvotes) {
+			best, bestSim = c, sim
+		}
+	}
+	if bestSim == 0 {
+		return models.Roadmap{}, mongo.ErrNoDocuments
+	}
+	return best, nil
+}
+
+func (s *RoadmapServiceImpl) SuggestRoadmaps(ctx context.Context, prefix string, size int) (dto.Suggestions, error) {
+	re := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix), Options: "i"}
+
+	opts := options.Find().
+		SetSort(bson.M{"upvotes": -1}).
+		SetLimit(int64(size)).
+		SetProjection(bson.M{"title": 1, "tags": 1})
+	cur, err := s.roadmapsCol.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"title": re},
+		bson.M{"tags": re},
+	}}, opts)
+	if err != nil {
+		return dto.Suggestions{}, err
+	}
+	defer cur.Close(ctx)
+
+	ret := dto.Suggestions{
+		Titles: []dto.TitleSuggestion{},
+		Tags:   []string{},
+	}
+	lowerPrefix := strings.ToLower(prefix)
+	for cur.Next(ctx) {
+		var roadmap models.Roadmap
+		if err := cur.Decode(&roadmap); err != nil {
+			return dto.Suggestions{}, err
+		}
+		if strings.HasPrefix(strings.ToLower(roadmap.Title), lowerPrefix) {
+			ret.Titles = append(ret.Titles, dto.TitleSuggestion{ID: roadmap.ID.Hex(), Title: roadmap.Title})
+		}
+		for _, tag := range roadmap.Tags {
+			if strings.HasPrefix(strings.ToLower(tag), lowerPrefix) && !slices.Contains(ret.Tags, tag) && len(ret.Tags) < size {
+				ret.Tags = append(ret.Tags, tag)
+			}
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return dto.Suggestions{}, err
+	}
+	return ret, nil
+}
+
+func (s *RoadmapServiceImpl) SimilarRoadmaps(ctx context.Context, roadmap models.Roadmap, q dto.SimilarQuery, excludedIds []string) ([]dto.SearchResult, error) {
+	excluded := []primitive.ObjectID{roadmap.ID}
+	for _, id := range excludedIds {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			excluded = append(excluded, objID)
+		}
+	}
+	filter := bson.M{"_id": bson.M{"$nin": excluded}}
+	if q.Email != "" {
+		filter["useremail"] = bson.M{"$ne": q.Email}
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"upvotes": -1}).
+		SetLimit(constants.SimilarFallbackCandidates).
+		SetProjection(bson.M{"title": 1, "description": 1, "tags": 1, "nodes.title": 1, "difficulty": 1, "upvotes": 1})
+	cur, err := s.roadmapsCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []models.Roadmap
+	if err := cur.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+
+	results := []dto.SearchResult{}
+	for _, sr := range RankSimilar(roadmap, candidates, q.Size) {
+		results = append(results, dto.SearchResult{
+			ID:         sr.Roadmap.ID.Hex(),
+			Title:      sr.Roadmap.Title,
+			Highlights: []string{},
+			Score:      sr.Score,
+			Difficulty: sr.Roadmap.Difficulty,
+			Tags:       sr.Roadmap.Tags,
+		})
+	}
+	return results, nil
+}
+
+func (s *RoadmapServiceImpl) Insert(ctx context.Context, email string, req dto.GenerateRoadmapRequest, roadmap dto.Roadmap) (models.Roadmap, error) {
+	signature, bands := PromptFingerprint(req.Prompt)
 	rm := models.Roadmap{
 		ID:                    primitive.NewObjectID(),
 		Upvotes:               rand.Int() % 10_000_000,
@@ -99,7 +341,120 @@ func (s *RoadmapServiceImpl) Insert(ctx context.Context, email string, roadmap d
 		Tags:                  roadmap.Tags,
 		Modules:               roadmap.Modules,
 		Nodes:                 roadmap.Nodes,
+		PromptKey:             common.NormalizeText(req.Prompt),
+		PromptSignature:       signature,
+		PromptBands:           bands,
+		OptionsKey:            GenerationOptionsKey(req),
 	}
 	_, err := s.roadmapsCol.InsertOne(ctx, rm)
-	return rm, err
+	if err != nil {
+		return rm, err
+	}
+	s.recordOutbox(ctx, rm.ID)
+	return rm, nil
+}
+
+func (s *RoadmapServiceImpl) Fork(ctx context.Context, roadmapId string, email string) (models.Roadmap, error) {
+	original, err := s.Roadmap(ctx, roadmapId)
+	if err != nil {
+		return models.Roadmap{}, err
+	}
+
+	rm := original
+	rm.ID = primitive.NewObjectID()
+	rm.Upvotes = 0
+	rm.UserEmail = email
+	// not generated, the prompt cache must not serve it
+	rm.PromptKey = ""
+	rm.PromptSignature = nil
+	rm.PromptBands = nil
+	rm.OptionsKey = ""
+	rm.ForkedFrom = &original.ID
+	rm.Trending = models.TrendingScores{}
+	if _, err := s.roadmapsCol.InsertOne(ctx, rm); err != nil {
+		return rm, err
+	}
+	s.recordOutbox(ctx, rm.ID)
+	return rm, nil
+}
+
+func (s *RoadmapServiceImpl) Update(ctx context.Context, roadmapId string, roadmap dto.Roadmap) (models.Roadmap, error) {
+	objID, err := primitive.ObjectIDFromHex(roadmapId)
+	if err != nil {
+		return models.Roadmap{}, err
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var rm models.Roadmap
+	err = s.roadmapsCol.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": objID},
+		bson.M{"$set": bson.M{
+			"schemaversion":         roadmap.SchemaVersion,
+			"title":                 roadmap.Title,
+			"description":           roadmap.Description,
+			"difficulty":            roadmap.Difficulty,
+			"estimatedtotalminutes": roadmap.EstimatedTotalMinutes,
+			"tags":                  roadmap.Tags,
+			"modules":               roadmap.Modules,
+			"nodes":                 roadmap.Nodes,
+		}},
+		opts,
+	).Decode(&rm)
+	if err != nil {
+		return rm, err
+	}
+	s.recordOutbox(ctx, rm.ID)
+	return rm, nil
+}
+
+func (s *RoadmapServiceImpl) SetTags(ctx context.Context, roadmapId string, tags []string) error {
+	objID, err := primitive.ObjectIDFromHex(roadmapId)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.roadmapsCol.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"tags": tags}})
+	if err != nil {
+		return err
+	}
+	s.recordOutbox(ctx, objID)
+	return nil
+}
+
+func (s *RoadmapServiceImpl) TagCounts(ctx context.Context) (map[string]int, error) {
+	cur, err := s.roadmapsCol.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	counts := map[string]int{}
+	for cur.Next(ctx) {
+		var row struct {
+			Tag   string `bson:"_id"`
+			Count int    `bson:"count"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, err
+		}
+		counts[row.Tag] = row.Count
+	}
+	return counts, cur.Err()
+}
+
+// recordOutbox enqueues roadmapId for indexing. The roadmap is already stored,
+// so a failure is only logged, the consistency check catches it later.
+func (s *RoadmapServiceImpl) recordOutbox(ctx context.Context, roadmapId primitive.ObjectID) {
+	_, err := s.outboxCol.InsertOne(ctx, models.SearchOutbox{
+		ID:        primitive.NewObjectID(),
+		RoadmapID: roadmapId,
+		Ts:        time.Now(),
+	})
+	if err != nil {
+		slog.Error(errors.Join(err, errors.New("could not record search outbox entry")).Error())
+	}
 }
@@ -0,0 +1,48 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+)
+
+func TestGenerationOptionsKey(t *testing.T) {
+	tests := []struct {
+		name string
+		req  dto.GenerateRoadmapRequest
+		want string
+	}{
+		{
+			name: "no options",
+			req:  dto.GenerateRoadmapRequest{Prompt: "aprender go"},
+			want: "",
+		},
+		{
+			name: "every option",
+			req: dto.GenerateRoadmapRequest{
+				Prompt:          "aprender go",
+				Difficulty:      "advanced",
+				SkillLevel:      "beginner",
+				WeeklyHours:     5,
+				TimeBudgetHours: 40,
+				Language:        "en",
+				FocusTags:       []string{"Web APIs", "concurrency", "web-apis"},
+			},
+			want: "difficulty=advanced;skillLevel=beginner;weeklyHours=5;timeBudgetHours=40;language=en;focusTags=concurrency,web-apis",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := services.GenerationOptionsKey(tt.req); got != tt.want {
+				t.Errorf("GenerationOptionsKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	a := dto.GenerateRoadmapRequest{Prompt: "x", Language: "en", Difficulty: "advanced"}
+	b := dto.GenerateRoadmapRequest{Prompt: "x", Language: "pt-BR"}
+	if services.GenerationOptionsKey(a) == services.GenerationOptionsKey(b) {
+		t.Error("GenerationOptionsKey() is the same for different options")
+	}
+}
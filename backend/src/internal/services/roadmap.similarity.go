@@ -0,0 +1,124 @@
+package services
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+)
+
+// similarityStopWords are left out of similarity, too common to relate roadmaps.
+var similarityStopWords = map[string]bool{
+	"de": true, "da": true, "do": true, "das": true, "dos": true, "e": true, "em": true, "a": true,
+	"o": true, "as": true, "os": true, "um": true, "uma": true, "para": true, "com": true, "por": true,
+	"the": true, "and": true, "of": true, "to": true, "for": true, "with": true, "in": true, "an": true,
+}
+
+// ScoredRoadmap is a roadmap along with its similarity to another one.
+type ScoredRoadmap struct {
+	Roadmap models.Roadmap
+	Score   float64
+}
+
+// RankSimilar ranks candidates by the TF-IDF cosine similarity of their title,
+// description, tags and node titles to target's, most similar first. target
+// itself and unrelated candidates are left out.
+func RankSimilar(target models.Roadmap, candidates []models.Roadmap, size int) []ScoredRoadmap {
+	texts := make([][]string, len(candidates))
+	for i, c := range candidates {
+		texts[i] = similarityText(c.Title, c.Description, c.Tags, nodeTitles(c.Nodes))
+	}
+	scores := cosineScores(similarityText(target.Title, target.Description, target.Tags, nodeTitles(target.Nodes)), texts)
+
+	ranked := []ScoredRoadmap{}
+	for i, c := range candidates {
+		if c.ID != target.ID && scores[i] > 0 {
+			ranked = append(ranked, ScoredRoadmap{Roadmap: c, Score: scores[i]})
+		}
+	}
+	slices.SortStableFunc(ranked, func(a, b ScoredRoadmap) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		if a.Roadmap.Upvotes != b.Roadmap.Upvotes {
+			return b.Roadmap.Upvotes - a.Roadmap.Upvotes
+		}
+		return strings.Compare(a.Roadmap.ID.Hex(), b.Roadmap.ID.Hex())
+	})
+	return ranked[:min(size, len(ranked))]
+}
+
+func nodeTitles(nodes []models.Nodes) []string {
+	titles := make([]string, len(nodes))
+	for i, n := range nodes {
+		titles[i] = n.Title
+	}
+	return titles
+}
+
+// similarityText is the content roadmaps are compared on, tags weigh twice.
+func similarityText(title string, description string, tags []string, nodes []string) []string {
+	text := []string{title, description}
+	text = append(text, tags...)
+	text = append(text, tags...)
+	return append(text, nodes...)
+}
+
+// cosineScores returns the TF-IDF cosine similarity of each candidate to
+// target, with document frequencies taken over the candidates.
+func cosineScores(target []string, candidates [][]string) []float64 {
+	tfs := make([]map[string]float64, len(candidates))
+	df := map[string]int{}
+	for i, c := range candidates {
+		tfs[i] = termFrequencies(c)
+		for term := range tfs[i] {
+			df[term]++
+		}
+	}
+
+	n := float64(len(candidates))
+	weigh := func(tf map[string]float64) (map[string]float64, float64) {
+		vec := map[string]float64{}
+		norm := 0.0
+		for term, f := range tf {
+			w := f * math.Log(1+n/float64(1+df[term]))
+			vec[term] = w
+			norm += w * w
+		}
+		return vec, math.Sqrt(norm)
+	}
+
+	targetVec, targetNorm := weigh(termFrequencies(target))
+	scores := make([]float64, len(candidates))
+	if targetNorm == 0 {
+		return scores
+	}
+	for i, tf := range tfs {
+		vec, norm := weigh(tf)
+		if norm == 0 {
+			continue
+		}
+		dot := 0.0
+		for term, w := range targetVec {
+			dot += w * vec[term]
+		}
+		scores[i] = dot / (targetNorm * norm)
+	}
+	return scores
+}
+
+func termFrequencies(text []string) map[string]float64 {
+	tf := map[string]float64{}
+	for _, v := range text {
+		for _, term := range tokenize(v) {
+			if !similarityStopWords[term] {
+				tf[term]++
+			}
+		}
+	}
+	return tf
+}
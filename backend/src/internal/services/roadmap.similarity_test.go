@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+)
+
+func TestRankSimilar(t *testing.T) {
+	target := models.Roadmap{
+		ID:    primitive.NewObjectID(),
+		Title: "Docker para iniciantes",
+		Tags:  []string{"docker", "devops"},
+		Nodes: []models.Nodes{{Title: "Imagens"}, {Title: "Volumes"}},
+	}
+	compose := models.Roadmap{
+		ID:    primitive.NewObjectID(),
+		Title: "Docker Compose",
+		Tags:  []string{"docker"},
+		Nodes: []models.Nodes{{Title: "Volumes"}},
+	}
+	ci := models.Roadmap{
+		ID:      primitive.NewObjectID(),
+		Title:   "Pipelines de CI",
+		Tags:    []string{"devops"},
+		Upvotes: 100,
+	}
+	cooking := models.Roadmap{
+		ID:    primitive.NewObjectID(),
+		Title: "Culinária para iniciantes de verdade",
+		Tags:  []string{"receitas"},
+	}
+
+	tests := []struct {
+		name       string
+		candidates []models.Roadmap
+		size       int
+		want       []primitive.ObjectID
+	}{
+		{name: "most shared content first", candidates: []models.Roadmap{ci, cooking, compose}, size: 5, want: []primitive.ObjectID{compose.ID, ci.ID, cooking.ID}},
+		{name: "size limits", candidates: []models.Roadmap{ci, compose}, size: 1, want: []primitive.ObjectID{compose.ID}},
+		{name: "target left out", candidates: []models.Roadmap{target, compose}, size: 5, want: []primitive.ObjectID{compose.ID}},
+		{name: "stop words do not relate", candidates: []models.Roadmap{{ID: primitive.NewObjectID(), Title: "de para"}}, size: 5, want: []primitive.ObjectID{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []primitive.ObjectID{}
+			for _, sr := range services.RankSimilar(target, tt.candidates, tt.size) {
+				got = append(got, sr.Roadmap.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("RankSimilar() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
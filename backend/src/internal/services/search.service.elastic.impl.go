@@ -0,0 +1,638 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/bulk"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/search"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/create"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/updatealiases"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+// reindexBatchSize is the number of documents per bulk request of a reindex.
+const reindexBatchSize = 500
+
+// SearchServiceElasticImpl searches through index, an alias pointing at a
+// timestamped concrete index so reindexes can swap it without downtime.
+type SearchServiceElasticImpl struct {
+	client *elasticsearch.TypedClient
+	index  string
+
+	mu         sync.RWMutex
+	reindexing bool
+	shadow     string          // index being filled by the running reindex, if any
+	tombstones map[string]bool // IDs deleted during the running reindex
+}
+
+func NewSearchServiceElasticImpl(es *elasticsearch.TypedClient, index string) SearchService {
+	return &SearchServiceElasticImpl{
+		client: es,
+		index:  index,
+	}
+}
+
+func (s *SearchServiceElasticImpl) EnsureIndex(ctx context.Context) error {
+	exists, err := s.client.Indices.Exists(s.index).Do(ctx)
+	if err != nil {
+		return errors.Join(err, errors.New("could not check index"))
+	}
+	if exists {
+		slog.Info(fmt.Sprintf("index %s already exists, keeping its mapping", s.index))
+		return nil
+	}
+
+	_, err = s.createIndex(ctx, map[string]types.Alias{s.index: {}})
+	return err
+}
+
+// createIndex creates a new concrete index with the roadmaps mapping.
+func (s *SearchServiceElasticImpl) createIndex(ctx context.Context, aliases map[string]types.Alias) (string, error) {
+	name := fmt.Sprintf("%s-%d", s.index, time.Now().UnixNano())
+	_, err := s.client.Indices.Create(name).Request(&create.Request{
+		Mappings: roadmapsMapping(),
+		Aliases:  aliases,
+	}).Do(ctx)
+	if err != nil {
+		return "", errors.Join(err, errors.New("could not create index"))
+	}
+	return name, nil
+}
+
+func (s *SearchServiceElasticImpl) InsertRoadmap(ctx context.Context, roadmap models.Roadmap, prompt string) error {
+	return s.bulk(ctx, []models.Search{searchDoc(roadmap, prompt)}, nil)
+}
+
+func (s *SearchServiceElasticImpl) BulkSync(ctx context.Context, roadmaps []models.Roadmap, deletedIds []string) error {
+	docs := make([]models.Search, len(roadmaps))
+	for i, rm := range roadmaps {
+		docs[i] = searchDoc(rm, rm.PromptKey)
+	}
+	return s.bulk(ctx, docs, deletedIds)
+}
+
+// bulk writes docs and deletes deletedIds through the alias, and into the
+// index being built by a running reindex.
+func (s *SearchServiceElasticImpl) bulk(ctx context.Context, docs []models.Search, deletedIds []string) error {
+	if len(docs) == 0 && len(deletedIds) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	targets := []string{s.index}
+	if s.shadow != "" {
+		targets = append(targets, s.shadow)
+		for _, doc := range docs {
+			delete(s.tombstones, doc.RoadmapID)
+		}
+		// the shadow may not hold them yet, the reindex must not bring them back
+		for _, id := range deletedIds {
+			s.tombstones[id] = true
+		}
+	}
+	s.mu.Unlock()
+
+	req := s.client.Bulk()
+	for _, target := range targets {
+		for _, doc := range docs {
+			if err := req.IndexOp(types.IndexOperation{Index_: &target, Id_: &doc.RoadmapID}, doc); err != nil {
+				return err
+			}
+		}
+		for _, id := range deletedIds {
+			if err := req.DeleteOp(types.DeleteOperation{Index_: &target, Id_: &id}); err != nil {
+				return err
+			}
+		}
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return errors.Join(err, errors.New("could not index roadmaps"))
+	}
+	return bulkErrors(resp, http.StatusNotFound)
+}
+
+func (s *SearchServiceElasticImpl) Reindex(ctx context.Context, load func(ctx context.Context) ([]models.Roadmap, error)) error {
+	s.mu.Lock()
+	if s.reindexing {
+		s.mu.Unlock()
+		return constants.ErrReindexRunning
+	}
+	s.reindexing = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.reindexing = false
+		s.shadow = ""
+		s.tombstones = nil
+		s.mu.Unlock()
+	}()
+
+	name, err := s.createIndex(ctx, nil)
+	if err != nil {
+		return err
+	}
+	swapped := false
+	defer func() {
+		if !swapped {
+			s.client.Indices.Delete(name).Do(context.Background())
+		}
+	}()
+
+	s.mu.Lock()
+	s.shadow = name
+	s.tombstones = map[string]bool{}
+	s.mu.Unlock()
+
+	// loaded after the shadow index is set, so later writes reach it as well
+	roadmaps, err := load(ctx)
+	if err != nil {
+		return err
+	}
+
+	for chunk := range slices.Chunk(roadmaps, reindexBatchSize) {
+		req := s.client.Bulk().Index(name)
+		for _, rm := range chunk {
+			doc := searchDoc(rm, rm.PromptKey)
+			// create keeps documents the sync pipeline wrote meanwhile, they are newer
+			if err := req.CreateOp(types.CreateOperation{Id_: &doc.RoadmapID}, doc); err != nil {
+				return err
+			}
+		}
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return errors.Join(err, errors.New("could not fill new index"))
+		}
+		if err := bulkErrors(resp, http.StatusConflict); err != nil {
+			return errors.Join(err, errors.New("could not fill new index"))
+		}
+	}
+
+	// the fill may have created documents deleted since the roadmaps were loaded
+	s.mu.RLock()
+	deleted := slices.Collect(maps.Keys(s.tombstones))
+	s.mu.RUnlock()
+	if len(deleted) > 0 {
+		req := s.client.Bulk().Index(name)
+		for _, id := range deleted {
+			if err := req.DeleteOp(types.DeleteOperation{Id_: &id}); err != nil {
+				return err
+			}
+		}
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return errors.Join(err, errors.New("could not drop deleted documents"))
+		}
+		if err := bulkErrors(resp, http.StatusNotFound); err != nil {
+			return errors.Join(err, errors.New("could not drop deleted documents"))
+		}
+	}
+
+	if _, err := s.client.Indices.Refresh().Index(name).Do(ctx); err != nil {
+		return errors.Join(err, errors.New("could not refresh new index"))
+	}
+
+	if err := s.swapAlias(ctx, name); err != nil {
+		return err
+	}
+	swapped = true
+	return nil
+}
+
+// swapAlias atomically points the alias to name, then drops the indexes it
+// pointed to before.
+func (s *SearchServiceElasticImpl) swapAlias(ctx context.Context, name string) error {
+	actions := []types.IndicesAction{{Add: &types.AddAction{Index: &name, Alias: &s.index}}}
+	old := []string{}
+
+	isAlias, err := s.client.Indices.ExistsAlias(s.index).Do(ctx)
+	if err != nil {
+		return errors.Join(err, errors.New("could not check alias"))
+	}
+	if isAlias {
+		aliases, err := s.client.Indices.GetAlias().Name(s.index).Do(ctx)
+		if err != nil {
+			return errors.Join(err, errors.New("could not get alias"))
+		}
+		for idx := range aliases {
+			old = append(old, idx)
+			actions = append(actions, types.IndicesAction{Remove: &types.RemoveAction{Index: &idx, Alias: &s.index}})
+		}
+	} else {
+		// an index created before aliases were used, it has to go for the alias to take its name
+		exists, err := s.client.Indices.Exists(s.index).Do(ctx)
+		if err != nil {
+			return errors.Join(err, errors.New("could not check index"))
+		}
+		if exists {
+			actions = append(actions, types.IndicesAction{RemoveIndex: &types.RemoveIndexAction{Index: &s.index}})
+		}
+	}
+
+	_, err = s.client.Indices.UpdateAliases().Request(&updatealiases.Request{Actions: actions}).Do(ctx)
+	if err != nil {
+		return errors.Join(err, errors.New("could not swap alias"))
+	}
+	slog.Info(fmt.Sprintf("alias %s now points to %s", s.index, name))
+
+	for _, idx := range old {
+		if _, err := s.client.Indices.Delete(idx).Do(ctx); err != nil {
+			slog.Error(errors.Join(err, fmt.Errorf("could not delete old index %s", idx)).Error())
+		}
+	}
+	return nil
+}
+
+func (s *SearchServiceElasticImpl) IndexedHashes(ctx context.Context) (map[string]string, error) {
+	size := 1000
+	hashes := map[string]string{}
+
+	var after []types.FieldValue
+	for {
+		req := s.client.Search().Index(s.index).Request(&search.Request{
+			Query:   &types.Query{MatchAll: &types.MatchAllQuery{}},
+			Size:    &size,
+			Sort:    []types.SortCombinations{"roadmapId"},
+			Source_: types.SourceFilter{Includes: []string{"roadmapId", "hash"}},
+		})
+		if after != nil {
+			req.SearchAfter(after...)
+		}
+		resp, err := req.Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, hit := range resp.Hits.Hits {
+			var doc models.Search
+			if err := json.Unmarshal(hit.Source_, &doc); err != nil {
+				return nil, err
+			}
+			hashes[doc.RoadmapID] = doc.Hash
+		}
+		if len(resp.Hits.Hits) < size {
+			return hashes, nil
+		}
+		after = resp.Hits.Hits[len(resp.Hits.Hits)-1].Sort
+	}
+}
+
+// bulkErrors joins the errors of the failed items of resp, except those
+// failing with one of the ignored statuses.
+func bulkErrors(resp *bulk.Response, ignored ...int) error {
+	if !resp.Errors {
+		return nil
+	}
+	errs := []error{}
+	for _, item := range resp.Items {
+		for op, res := range item {
+			if res.Error == nil || slices.Contains(ignored, res.Status) {
+				continue
+			}
+			reason := res.Error.Type
+			if res.Error.Reason != nil {
+				reason = *res.Error.Reason
+			}
+			id := ""
+			if res.Id_ != nil {
+				id = *res.Id_
+			}
+			errs = append(errs, fmt.Errorf("%s %s: %s", op, id, reason))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *SearchServiceElasticImpl) SearchRoadmaps(ctx context.Context, query string, q dto.SearchQuery) (dto.SearchResults, error) {
+	from, size := searchPage(q)
+	fuzziness := "AUTO"
+	tagsSize := 20
+	authorSize := 10
+
+	difficultyField := "difficulty"
+	tagsField := "tags"
+	authorField := "userEmail"
+	timeField := "estimatedTotalMinutes"
+
+	ranges := make([]types.AggregationRange, len(estimatedTimeBuckets))
+	for i, b := range estimatedTimeBuckets {
+		ranges[i] = types.AggregationRange{Key: &b.Key, From: minutes(b.From), To: minutes(b.To)}
+	}
+
+	// the filters only apply to the hits, each facet is filtered by the others
+	filters := searchFilters(q)
+	facets := map[string]types.Aggregations{
+		facetDifficulty: {Terms: &types.TermsAggregation{Field: &difficultyField}},
+		facetTags:       {Terms: &types.TermsAggregation{Field: &tagsField, Size: &tagsSize}},
+		facetAuthor:     {Terms: &types.TermsAggregation{Field: &authorField, Size: &authorSize}},
+		facetTime:       {Range: &types.RangeAggregation{Field: &timeField, Ranges: ranges}},
+	}
+	aggs := make(map[string]types.Aggregations, len(facets))
+	for name, facet := range facets {
+		aggs[name] = types.Aggregations{
+			Filter:       &types.Query{Bool: &types.BoolQuery{Filter: otherFilters(filters, name)}},
+			Aggregations: map[string]types.Aggregations{"facet": facet},
+		}
+	}
+
+	resp, err := s.client.Search().Index(s.index).
+		Request(&search.Request{
+			Query: &types.Query{
+				Bool: &types.BoolQuery{
+					Must: []types.Query{{
+						MultiMatch: &types.MultiMatchQuery{
+							Query:     query,
+							Fields:    elasticSearchFields(),
+							Fuzziness: fuzziness,
+						},
+					}},
+				},
+			},
+			PostFilter: &types.Query{Bool: &types.BoolQuery{Filter: otherFilters(filters, "")}},
+			From:       &from,
+			Size:       &size,
+			Highlight: &types.Highlight{
+				Fields: map[string]types.HighlightField{
+					"title":       {},
+					"description": {},
+					"modules":     {},
+				},
+			},
+			Aggregations: aggs,
+		}).Do(ctx)
+	if err != nil {
+		return dto.SearchResults{}, err
+	}
+
+	ret := dto.SearchResults{
+		Page:     q.Page,
+		PageSize: q.PageSize,
+		Results:  []dto.SearchResult{},
+		Facets: dto.SearchFacets{
+			Difficulty: termsFacet(facetAggregate(resp.Aggregations[facetDifficulty])),
+			Tags:       termsFacet(facetAggregate(resp.Aggregations[facetTags])),
+			Author:     termsFacet(facetAggregate(resp.Aggregations[facetAuthor])),
+			Time:       rangeFacet(facetAggregate(resp.Aggregations[facetTime])),
+		},
+	}
+	if resp.Hits.Total != nil {
+		ret.Total = resp.Hits.Total.Value
+	}
+
+	for _, hit := range resp.Hits.Hits {
+		var doc models.Search
+		if err := json.Unmarshal(hit.Source_, &doc); err != nil {
+			return dto.SearchResults{}, err
+		}
+
+		highlights := []string{}
+		for _, field := range []string{"title", "description", "modules"} {
+			highlights = append(highlights, hit.Highlight[field]...)
+		}
+
+		result := dto.SearchResult{
+			ID:         doc.RoadmapID,
+			Title:      doc.Title,
+			Highlights: highlights,
+			Difficulty: doc.Difficulty,
+			Tags:       doc.Tags,
+		}
+		if hit.Score_ != nil {
+			result.Score = float64(*hit.Score_)
+		}
+		ret.Results = append(ret.Results, result)
+	}
+
+	return ret, nil
+}
+
+func (s *SearchServiceElasticImpl) SuggestRoadmaps(ctx context.Context, prefix string, size int) (dto.Suggestions, error) {
+	skipDuplicates := true
+	noHits := 0
+
+	resp, err := s.client.Search().Index(s.index).
+		Request(&search.Request{
+			Size:    &noHits,
+			Source_: types.SourceFilter{Includes: []string{"roadmapId", "title"}},
+			Suggest: &types.Suggester{
+				Suggesters: map[string]types.FieldSuggester{
+					"titles": {
+						Prefix: &prefix,
+						Completion: &types.CompletionSuggester{
+							Field:          "titleSuggest",
+							Size:           &size,
+							SkipDuplicates: &skipDuplicates,
+						},
+					},
+					"tags": {
+						Prefix: &prefix,
+						Completion: &types.CompletionSuggester{
+							Field:          "tagSuggest",
+							Size:           &size,
+							SkipDuplicates: &skipDuplicates,
+						},
+					},
+				},
+			},
+		}).Do(ctx)
+	if err != nil {
+		return dto.Suggestions{}, err
+	}
+
+	ret := dto.Suggestions{
+		Titles: []dto.TitleSuggestion{},
+		Tags:   []string{},
+	}
+	for _, opt := range completionOptions(resp.Suggest["titles"]) {
+		var doc models.Search
+		if err := json.Unmarshal(opt.Source_, &doc); err != nil {
+			return dto.Suggestions{}, err
+		}
+		ret.Titles = append(ret.Titles, dto.TitleSuggestion{ID: doc.RoadmapID, Title: doc.Title})
+	}
+	for _, opt := range completionOptions(resp.Suggest["tags"]) {
+		ret.Tags = append(ret.Tags, opt.Text)
+	}
+
+	return ret, nil
+}
+
+func (s *SearchServiceElasticImpl) SimilarRoadmaps(ctx context.Context, roadmap models.Roadmap, q dto.SimilarQuery, excludedIds []string) ([]dto.SearchResult, error) {
+	doc := searchDoc(roadmap, "")
+	like, err := json.Marshal(map[string]any{
+		"title":       doc.Title,
+		"description": doc.Description,
+		"tags":        doc.Tags,
+		"nodes":       doc.Nodes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	minFreq := 1
+	mustNot := []types.Query{{Ids: &types.IdsQuery{Values: append([]string{doc.RoadmapID}, excludedIds...)}}}
+	if q.Email != "" {
+		mustNot = append(mustNot, types.Query{Term: map[string]types.TermQuery{
+			"userEmail": {Value: q.Email},
+		}})
+	}
+
+	resp, err := s.client.Search().Index(s.index).
+		Request(&search.Request{
+			Query: &types.Query{
+				Bool: &types.BoolQuery{
+					Must: []types.Query{{
+						MoreLikeThis: &types.MoreLikeThisQuery{
+							Fields:      []string{"title", "description", "tags.text", "nodes"},
+							Like:        []types.Like{types.LikeDocument{Doc: like}},
+							MinTermFreq: &minFreq,
+							MinDocFreq:  &minFreq,
+						},
+					}},
+					MustNot: mustNot,
+				},
+			},
+			Size:    &q.Size,
+			Source_: types.SourceFilter{Includes: []string{"roadmapId", "title", "difficulty", "tags"}},
+		}).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []dto.SearchResult{}
+	for _, hit := range resp.Hits.Hits {
+		var doc models.Search
+		if err := json.Unmarshal(hit.Source_, &doc); err != nil {
+			return nil, err
+		}
+		result := dto.SearchResult{
+			ID:         doc.RoadmapID,
+			Title:      doc.Title,
+			Highlights: []string{},
+			Difficulty: doc.Difficulty,
+			Tags:       doc.Tags,
+		}
+		if hit.Score_ != nil {
+			result.Score = float64(*hit.Score_)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func completionOptions(suggests []types.Suggest) []types.CompletionSuggestOption {
+	opts := []types.CompletionSuggestOption{}
+	for _, s := range suggests {
+		if c, ok := s.(*types.CompletionSuggest); ok {
+			opts = append(opts, c.Options...)
+		}
+	}
+	return opts
+}
+
+// searchFilters translates the facet filters of q into filter clauses.
+// searchFilters returns the filters of q, by facet.
+func searchFilters(q dto.SearchQuery) map[string]types.Query {
+	filters := map[string]types.Query{}
+
+	if len(q.Difficulty) > 0 {
+		filters[facetDifficulty] = types.Query{Terms: &types.TermsQuery{
+			TermsQuery: map[string]types.TermsQueryField{"difficulty": q.Difficulty},
+		}}
+	}
+	if len(q.Tags) > 0 {
+		filters[facetTags] = types.Query{Terms: &types.TermsQuery{
+			TermsQuery: map[string]types.TermsQueryField{"tags": q.Tags},
+		}}
+	}
+	if q.Author != "" {
+		filters[facetAuthor] = types.Query{Term: map[string]types.TermQuery{
+			"userEmail": {Value: q.Author},
+		}}
+	}
+	if len(q.Time) > 0 {
+		should := []types.Query{}
+		for _, b := range estimatedTimeBuckets {
+			if !slices.Contains(q.Time, b.Key) {
+				continue
+			}
+			should = append(should, types.Query{Range: map[string]types.RangeQuery{
+				"estimatedTotalMinutes": types.NumberRangeQuery{Gte: minutes(b.From), Lt: minutes(b.To)},
+			}})
+		}
+		filters[facetTime] = types.Query{Bool: &types.BoolQuery{
+			Should:             should,
+			MinimumShouldMatch: 1,
+		}}
+	}
+
+	return filters
+}
+
+// otherFilters returns the filters of every facet but except, which may be empty.
+func otherFilters(filters map[string]types.Query, except string) []types.Query {
+	others := []types.Query{}
+	for _, facet := range searchFacets {
+		if f, ok := filters[facet]; ok && facet != except {
+			others = append(others, f)
+		}
+	}
+	return others
+}
+
+// facetAggregate unwraps the aggregation of a facet from its filter.
+func facetAggregate(agg types.Aggregate) types.Aggregate {
+	filtered, ok := agg.(*types.FilterAggregate)
+	if !ok {
+		return nil
+	}
+	return filtered.Aggregations["facet"]
+}
+
+func termsFacet(agg types.Aggregate) []dto.FacetBucket {
+	facet := []dto.FacetBucket{}
+	terms, ok := agg.(*types.StringTermsAggregate)
+	if !ok {
+		return facet
+	}
+	buckets, ok := terms.Buckets.([]types.StringTermsBucket)
+	if !ok {
+		return facet
+	}
+	for _, b := range buckets {
+		facet = append(facet, dto.FacetBucket{Key: fmt.Sprint(b.Key), Count: b.DocCount})
+	}
+	return facet
+}
+
+func rangeFacet(agg types.Aggregate) []dto.FacetBucket {
+	facet := []dto.FacetBucket{}
+	ranges, ok := agg.(*types.RangeAggregate)
+	if !ok {
+		return facet
+	}
+	buckets, ok := ranges.Buckets.([]types.RangeBucket)
+	if !ok {
+		return facet
+	}
+	for _, b := range buckets {
+		if b.Key != nil {
+			facet = append(facet, dto.FacetBucket{Key: *b.Key, Count: b.DocCount})
+		}
+	}
+	return facet
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+// minutes converts an estimatedTimeBuckets bound into a range bound.
+func minutes(m int) *types.Float64 {
+	if m == 0 {
+		return nil
+	}
+	f := types.Float64(m)
+	return &f
+}
+
+// multilingualText is a text field analyzed as-is plus Portuguese and English
+// subfields, so stemming works for both languages.
+func multilingualText() *types.TextProperty {
+	pt := "portuguese"
+	en := "english"
+
+	p := types.NewTextProperty()
+	p.Fields = map[string]types.Property{
+		"pt": &types.TextProperty{Type: "text", Analyzer: &pt},
+		"en": &types.TextProperty{Type: "text", Analyzer: &en},
+	}
+	return p
+}
+
+// roadmapsMapping is the explicit mapping of the roadmaps index, see models.Search.
+func roadmapsMapping() *types.TypeMapping {
+	// tags are filtered on as keywords and matched on as text
+	tags := types.NewKeywordProperty()
+	tags.Fields = map[string]types.Property{
+		"text": types.NewTextProperty(),
+	}
+
+	// hash is only compared against Mongo, never searched
+	noIndex := false
+	hash := types.NewKeywordProperty()
+	hash.Index = &noIndex
+
+	return &types.TypeMapping{
+		Properties: map[string]types.Property{
+			"roadmapId":             types.NewKeywordProperty(),
+			"userEmail":             types.NewKeywordProperty(),
+			"title":                 multilingualText(),
+			"prompt":                multilingualText(),
+			"description":           multilingualText(),
+			"modules":               multilingualText(),
+			"nodes":                 multilingualText(),
+			"tags":                  tags,
+			"difficulty":            types.NewKeywordProperty(),
+			"estimatedTotalMinutes": types.NewIntegerNumberProperty(),
+			"upvotes":               types.NewIntegerNumberProperty(),
+			"titleSuggest":          types.NewCompletionProperty(),
+			"tagSuggest":            types.NewCompletionProperty(),
+			"hash":                  hash,
+		},
+	}
+}
+
+// elasticSearchFields are the fields matched by a search query, with the
+// language subfields boosted like their parent field.
+func elasticSearchFields() []string {
+	fields := []string{}
+	for field, weight := range searchFieldWeights {
+		boost := fmt.Sprintf("^%g", weight)
+		if field == "tags" {
+			fields = append(fields, "tags.text"+boost)
+			continue
+		}
+		fields = append(fields, field+boost, field+".pt"+boost, field+".en"+boost)
+	}
+	slices.Sort(fields)
+	return fields
+}
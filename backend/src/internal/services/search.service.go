@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+)
+
+// SearchService defines the interface for full-text search over roadmaps.
+type SearchService interface {
+	// EnsureIndex prepares the backend for indexing, creating the roadmaps index
+	// with its explicit mapping if it does not exist yet.
+	EnsureIndex(ctx context.Context) error
+
+	// InsertRoadmap indexes roadmap, replacing any previous document with its ID.
+	InsertRoadmap(ctx context.Context, roadmap models.Roadmap, prompt string) error
+
+	// BulkSync upserts roadmaps and deletes the documents of deletedIds in a
+	// single request. Roadmaps are indexed with their PromptKey as prompt.
+	BulkSync(ctx context.Context, roadmaps []models.Roadmap, deletedIds []string) error
+
+	// Reindex builds a fresh index from the roadmaps returned by load and swaps
+	// it in atomically. Writes made through this instance while it runs reach
+	// both indexes, those of other replicas have to be replayed after it.
+	Reindex(ctx context.Context, load func(ctx context.Context) ([]models.Roadmap, error)) error
+
+	// IndexedHashes returns the content hash of every indexed document, by roadmap ID.
+	IndexedHashes(ctx context.Context) (map[string]string, error)
+
+	// SearchRoadmaps runs a fuzzy full-text query, titles weigh the most. Each
+	// facet counts the results filtered by every other facet, so its values can
+	// still be switched or combined. Pages past searchMaxResultWindow are empty.
+	SearchRoadmaps(ctx context.Context, query string, q dto.SearchQuery) (dto.SearchResults, error)
+
+	// SuggestRoadmaps completes prefix into roadmap titles and tags, most upvoted first.
+	SuggestRoadmaps(ctx context.Context, prefix string, size int) (dto.Suggestions, error)
+
+	// SimilarRoadmaps returns the roadmaps most like roadmap by title, description,
+	// tags and node titles, leaving out roadmap itself, those of q.Email and excludedIds.
+	SimilarRoadmaps(ctx context.Context, roadmap models.Roadmap, q dto.SimilarQuery, excludedIds []string) ([]dto.SearchResult, error)
+}
+
+// Search facets, also the names of their filters.
+const (
+	facetDifficulty = "difficulty"
+	facetTags       = "tags"
+	facetAuthor     = "author"
+	facetTime       = "time"
+)
+
+var searchFacets = []string{facetDifficulty, facetTags, facetAuthor, facetTime}
+
+// searchMaxResultWindow is the deepest result a search can page to, the
+// index.max_result_window of Elasticsearch, left at its default.
+const searchMaxResultWindow = 10_000
+
+// searchPage returns the offset and size of the page of q, clamped to
+// searchMaxResultWindow.
+func searchPage(q dto.SearchQuery) (from int, size int) {
+	from = min((q.Page-1)*q.PageSize, searchMaxResultWindow)
+	return from, min(q.PageSize, searchMaxResultWindow-from)
+}
+
+// estimatedTimeBuckets are the estimated time facets, bounds are in minutes
+// and a zero bound is open.
+var estimatedTimeBuckets = []struct {
+	Key  string
+	From int
+	To   int
+}{
+	{Key: "under-5h", To: 5 * 60},
+	{Key: "5-20h", From: 5 * 60, To: 20 * 60},
+	{Key: "20-50h", From: 20 * 60, To: 50 * 60},
+	{Key: "over-50h", From: 50 * 60},
+}
+
+// searchFieldWeights are the boosts of each searched field.
+var searchFieldWeights = map[string]float64{
+	"title":       4,
+	"tags":        2,
+	"description": 1.5,
+	"modules":     1,
+	"prompt":      1,
+}
+
+// searchDoc builds the indexed document of roadmap, hashing its content.
+func searchDoc(roadmap models.Roadmap, prompt string) models.Search {
+	modules := make([]string, len(roadmap.Modules))
+	for i, m := range roadmap.Modules {
+		modules[i] = m.Title
+	}
+
+	doc := models.Search{
+		RoadmapID:             roadmap.ID.Hex(),
+		UserEmail:             roadmap.UserEmail,
+		Title:                 roadmap.Title,
+		Prompt:                prompt,
+		Description:           roadmap.Description,
+		Difficulty:            roadmap.Difficulty,
+		Tags:                  roadmap.Tags,
+		Modules:               modules,
+		Nodes:                 nodeTitles(roadmap.Nodes),
+		EstimatedTotalMinutes: roadmap.EstimatedTotalMinutes,
+		Upvotes:               roadmap.Upvotes,
+		TitleSuggest: models.Completion{
+			Input:  []string{roadmap.Title},
+			Weight: roadmap.Upvotes,
+		},
+		TagSuggest: models.Completion{
+			Input:  roadmap.Tags,
+			Weight: roadmap.Upvotes,
+		},
+	}
+	doc.Hash = searchDocHash(doc)
+	return doc
+}
+
+// searchDocHash hashes the indexed content of doc, ignoring its Hash field.
+func searchDocHash(doc models.Search) string {
+	doc.Hash = ""
+	b, _ := json.Marshal(doc)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
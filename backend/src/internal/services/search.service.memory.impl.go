@@ -0,0 +1,570 @@
+package services
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/common"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
+)
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// fuzzyMatchWeight discounts query terms matched within an edit distance.
+	fuzzyMatchWeight = 0.5
+)
+
+// highlightedFields are the fields highlights are extracted from, as in the
+// Elasticsearch implementation.
+var highlightedFields = []string{"title", "description", "modules"}
+
+var wordRe = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// SearchServiceMemoryImpl is a pure Go SearchService scoring documents with
+// BM25 over the same weighted fields as the Elasticsearch implementation. It
+// is meant for tests and small deployments, everything lives in memory.
+type SearchServiceMemoryImpl struct {
+	mu         sync.RWMutex
+	idx        *memoryIndex
+	reindexing bool
+	shadow     *memoryIndex    // index being filled by the running reindex, if any
+	tombstones map[string]bool // IDs deleted during the running reindex
+}
+
+// memoryIndex holds the documents and the term statistics BM25 needs.
+type memoryIndex struct {
+	docs     map[string]*memoryDoc
+	df       map[string]map[string]int
+	totalLen map[string]int
+	vocab    map[string]int
+}
+
+type memoryDoc struct {
+	doc    models.Search
+	tf     map[string]map[string]int
+	length map[string]int
+}
+
+func NewSearchServiceMemoryImpl() SearchService {
+	return &SearchServiceMemoryImpl{
+		idx: newMemoryIndex(),
+	}
+}
+
+func newMemoryIndex() *memoryIndex {
+	return &memoryIndex{
+		docs:     map[string]*memoryDoc{},
+		df:       map[string]map[string]int{},
+		totalLen: map[string]int{},
+		vocab:    map[string]int{},
+	}
+}
+
+func newMemoryDoc(doc models.Search) *memoryDoc {
+	md := &memoryDoc{
+		doc:    doc,
+		tf:     map[string]map[string]int{},
+		length: map[string]int{},
+	}
+	for field, values := range searchableFields(doc) {
+		md.tf[field] = map[string]int{}
+		for _, v := range values {
+			for _, term := range tokenize(v) {
+				md.tf[field][term]++
+				md.length[field]++
+			}
+		}
+	}
+	return md
+}
+
+func (s *SearchServiceMemoryImpl) EnsureIndex(ctx context.Context) error {
+	return nil
+}
+
+func (s *SearchServiceMemoryImpl) InsertRoadmap(ctx context.Context, roadmap models.Roadmap, prompt string) error {
+	md := newMemoryDoc(searchDoc(roadmap, prompt))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.idx.put(md)
+	if s.shadow != nil {
+		s.shadow.put(md)
+		delete(s.tombstones, md.doc.RoadmapID)
+	}
+	return nil
+}
+
+func (s *SearchServiceMemoryImpl) BulkSync(ctx context.Context, roadmaps []models.Roadmap, deletedIds []string) error {
+	mds := make([]*memoryDoc, len(roadmaps))
+	for i, rm := range roadmaps {
+		mds[i] = newMemoryDoc(searchDoc(rm, rm.PromptKey))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, idx := range []*memoryIndex{s.idx, s.shadow} {
+		if idx == nil {
+			continue
+		}
+		for _, md := range mds {
+			idx.put(md)
+		}
+		for _, id := range deletedIds {
+			idx.remove(id)
+		}
+	}
+	if s.shadow != nil {
+		for _, md := range mds {
+			delete(s.tombstones, md.doc.RoadmapID)
+		}
+		// the shadow may not hold them yet, the loaded snapshot must not bring them back
+		for _, id := range deletedIds {
+			s.tombstones[id] = true
+		}
+	}
+	return nil
+}
+
+func (s *SearchServiceMemoryImpl) Reindex(ctx context.Context, load func(ctx context.Context) ([]models.Roadmap, error)) error {
+	s.mu.Lock()
+	if s.reindexing {
+		s.mu.Unlock()
+		return constants.ErrReindexRunning
+	}
+	s.reindexing = true
+	s.shadow = newMemoryIndex()
+	s.tombstones = map[string]bool{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.reindexing = false
+		s.shadow = nil
+		s.tombstones = nil
+		s.mu.Unlock()
+	}()
+
+	// loaded after the shadow index is set, so later writes reach it as well
+	roadmaps, err := load(ctx)
+	if err != nil {
+		return err
+	}
+	mds := make([]*memoryDoc, len(roadmaps))
+	for i, rm := range roadmaps {
+		mds[i] = newMemoryDoc(searchDoc(rm, rm.PromptKey))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, md := range mds {
+		// documents written or deleted meanwhile are newer than the loaded ones
+		if _, ok := s.shadow.docs[md.doc.RoadmapID]; !ok && !s.tombstones[md.doc.RoadmapID] {
+			s.shadow.put(md)
+		}
+	}
+	s.idx = s.shadow
+	return nil
+}
+
+func (s *SearchServiceMemoryImpl) IndexedHashes(ctx context.Context) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hashes := make(map[string]string, len(s.idx.docs))
+	for id, md := range s.idx.docs {
+		hashes[id] = md.doc.Hash
+	}
+	return hashes, nil
+}
+
+// put indexes md, replacing any previous document with its ID.
+func (idx *memoryIndex) put(md *memoryDoc) {
+	idx.remove(md.doc.RoadmapID)
+	idx.docs[md.doc.RoadmapID] = md
+	idx.account(md, 1)
+}
+
+func (idx *memoryIndex) remove(id string) {
+	if old, ok := idx.docs[id]; ok {
+		idx.account(old, -1)
+		delete(idx.docs, id)
+	}
+}
+
+// account adds (sign 1) or removes (sign -1) the statistics of md.
+func (idx *memoryIndex) account(md *memoryDoc, sign int) {
+	for field, terms := range md.tf {
+		if idx.df[field] == nil {
+			idx.df[field] = map[string]int{}
+		}
+		for term := range terms {
+			idx.df[field][term] += sign
+			idx.vocab[term] += sign
+			if idx.df[field][term] == 0 {
+				delete(idx.df[field], term)
+			}
+			if idx.vocab[term] == 0 {
+				delete(idx.vocab, term)
+			}
+		}
+		idx.totalLen[field] += sign * md.length[field]
+	}
+}
+
+type scoredDoc struct {
+	doc   *memoryDoc
+	score float64
+}
+
+func (s *SearchServiceMemoryImpl) SearchRoadmaps(ctx context.Context, query string, q dto.SearchQuery) (dto.SearchResults, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	idx := s.idx
+	terms := idx.expandQuery(tokenize(query))
+	n := float64(len(idx.docs))
+
+	// hits match the query, matches also match the filters
+	hits := []scoredDoc{}
+	for _, md := range idx.docs {
+		score := 0.0
+		for field, weight := range searchFieldWeights {
+			avgLen := float64(idx.totalLen[field]) / n
+			for term, termWeight := range terms {
+				tf := float64(md.tf[field][term])
+				if tf == 0 {
+					continue
+				}
+				df := float64(idx.df[field][term])
+				idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+				norm := tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(md.length[field])/avgLen))
+				score += weight * termWeight * idf * norm
+			}
+		}
+		if score > 0 {
+			hits = append(hits, scoredDoc{doc: md, score: score})
+		}
+	}
+
+	matches := []scoredDoc{}
+	for _, h := range hits {
+		if matchesFilters(h.doc.doc, q, "") {
+			matches = append(matches, h)
+		}
+	}
+	slices.SortFunc(matches, func(a, b scoredDoc) int {
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		}
+		if a.doc.doc.Upvotes != b.doc.doc.Upvotes {
+			return b.doc.doc.Upvotes - a.doc.doc.Upvotes
+		}
+		return strings.Compare(a.doc.doc.RoadmapID, b.doc.doc.RoadmapID)
+	})
+
+	ret := dto.SearchResults{
+		Total:    int64(len(matches)),
+		Page:     q.Page,
+		PageSize: q.PageSize,
+		Results:  []dto.SearchResult{},
+		Facets:   memoryFacets(hits, q),
+	}
+
+	from, size := searchPage(q)
+	from = min(from, len(matches))
+	to := min(from+size, len(matches))
+	for _, m := range matches[from:to] {
+		ret.Results = append(ret.Results, dto.SearchResult{
+			ID:         m.doc.doc.RoadmapID,
+			Title:      m.doc.doc.Title,
+			Highlights: highlight(m.doc.doc, terms),
+			Score:      m.score,
+			Difficulty: m.doc.doc.Difficulty,
+			Tags:       m.doc.doc.Tags,
+		})
+	}
+
+	return ret, nil
+}
+
+func (s *SearchServiceMemoryImpl) SuggestRoadmaps(ctx context.Context, prefix string, size int) (dto.Suggestions, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prefix = common.NormalizeText(prefix)
+	ret := dto.Suggestions{
+		Titles: []dto.TitleSuggestion{},
+		Tags:   []string{},
+	}
+
+	docs := make([]models.Search, 0, len(s.idx.docs))
+	for _, md := range s.idx.docs {
+		docs = append(docs, md.doc)
+	}
+	slices.SortFunc(docs, func(a, b models.Search) int {
+		if a.Upvotes != b.Upvotes {
+			return b.Upvotes - a.Upvotes
+		}
+		return strings.Compare(a.RoadmapID, b.RoadmapID)
+	})
+
+	seenTags := map[string]bool{}
+	for _, doc := range docs {
+		if len(ret.Titles) < size && strings.HasPrefix(common.NormalizeText(doc.Title), prefix) {
+			ret.Titles = append(ret.Titles, dto.TitleSuggestion{ID: doc.RoadmapID, Title: doc.Title})
+		}
+		for _, tag := range doc.Tags {
+			if len(ret.Tags) < size && !seenTags[tag] && strings.HasPrefix(common.NormalizeText(tag), prefix) {
+				seenTags[tag] = true
+				ret.Tags = append(ret.Tags, tag)
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+func (s *SearchServiceMemoryImpl) SimilarRoadmaps(ctx context.Context, roadmap models.Roadmap, q dto.SimilarQuery, excludedIds []string) ([]dto.SearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	target := searchDoc(roadmap, "")
+	docs := []models.Search{}
+	texts := [][]string{}
+	for _, md := range s.idx.docs {
+		doc := md.doc
+		if doc.RoadmapID == target.RoadmapID || (q.Email != "" && doc.UserEmail == q.Email) || slices.Contains(excludedIds, doc.RoadmapID) {
+			continue
+		}
+		docs = append(docs, doc)
+		texts = append(texts, similarityText(doc.Title, doc.Description, doc.Tags, doc.Nodes))
+	}
+	scores := cosineScores(similarityText(target.Title, target.Description, target.Tags, target.Nodes), texts)
+
+	results := []dto.SearchResult{}
+	for i, doc := range docs {
+		if scores[i] > 0 {
+			results = append(results, dto.SearchResult{
+				ID:         doc.RoadmapID,
+				Title:      doc.Title,
+				Highlights: []string{},
+				Score:      scores[i],
+				Difficulty: doc.Difficulty,
+				Tags:       doc.Tags,
+			})
+		}
+	}
+	slices.SortFunc(results, func(a, b dto.SearchResult) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return results[:min(q.Size, len(results))], nil
+}
+
+// expandQuery maps each query term, and the indexed terms within its fuzzy
+// edit distance, to the weight it contributes with.
+func (idx *memoryIndex) expandQuery(tokens []string) map[string]float64 {
+	terms := map[string]float64{}
+	for _, token := range tokens {
+		terms[token] = 1
+		maxDist := fuzzyDistance(token)
+		if maxDist == 0 {
+			continue
+		}
+		for term := range idx.vocab {
+			if _, ok := terms[term]; ok {
+				continue
+			}
+			if levenshtein(token, term) <= maxDist {
+				terms[term] = fuzzyMatchWeight
+			}
+		}
+	}
+	return terms
+}
+
+func searchableFields(doc models.Search) map[string][]string {
+	return map[string][]string{
+		"title":       {doc.Title},
+		"tags":        doc.Tags,
+		"description": {doc.Description},
+		"modules":     doc.Modules,
+		"prompt":      {doc.Prompt},
+	}
+}
+
+// matchesFilters tells if doc matches the filters of q on every facet but
+// except, which may be empty.
+func matchesFilters(doc models.Search, q dto.SearchQuery, except string) bool {
+	for _, facet := range searchFacets {
+		if facet != except && !matchesFacet(doc, q, facet) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesFacet(doc models.Search, q dto.SearchQuery, facet string) bool {
+	switch facet {
+	case facetDifficulty:
+		return len(q.Difficulty) == 0 || slices.Contains(q.Difficulty, doc.Difficulty)
+	case facetTags:
+		return len(q.Tags) == 0 || slices.ContainsFunc(doc.Tags, func(t string) bool { return slices.Contains(q.Tags, t) })
+	case facetAuthor:
+		return q.Author == "" || doc.UserEmail == q.Author
+	case facetTime:
+		return len(q.Time) == 0 || slices.Contains(q.Time, timeBucketKey(doc.EstimatedTotalMinutes))
+	}
+	return true
+}
+
+func timeBucketKey(minutes int) string {
+	for _, b := range estimatedTimeBuckets {
+		if (b.From == 0 || minutes >= b.From) && (b.To == 0 || minutes < b.To) {
+			return b.Key
+		}
+	}
+	return ""
+}
+
+// memoryFacets counts the values of each facet over the hits matching the
+// filters of q on every other facet.
+func memoryFacets(hits []scoredDoc, q dto.SearchQuery) dto.SearchFacets {
+	difficulty := map[string]int64{}
+	tags := map[string]int64{}
+	author := map[string]int64{}
+	time := map[string]int64{}
+	for _, h := range hits {
+		doc := h.doc.doc
+		if matchesFilters(doc, q, facetDifficulty) {
+			difficulty[doc.Difficulty]++
+		}
+		if matchesFilters(doc, q, facetAuthor) {
+			author[doc.UserEmail]++
+		}
+		if matchesFilters(doc, q, facetTime) {
+			time[timeBucketKey(doc.EstimatedTotalMinutes)]++
+		}
+		if matchesFilters(doc, q, facetTags) {
+			for _, t := range doc.Tags {
+				tags[t]++
+			}
+		}
+	}
+
+	timeFacet := []dto.FacetBucket{}
+	for _, b := range estimatedTimeBuckets {
+		timeFacet = append(timeFacet, dto.FacetBucket{Key: b.Key, Count: time[b.Key]})
+	}
+
+	return dto.SearchFacets{
+		Difficulty: topBuckets(difficulty, 10),
+		Tags:       topBuckets(tags, 20),
+		Author:     topBuckets(author, 10),
+		Time:       timeFacet,
+	}
+}
+
+// topBuckets returns the size largest counts, like a terms aggregation.
+func topBuckets(counts map[string]int64, size int) []dto.FacetBucket {
+	buckets := []dto.FacetBucket{}
+	for k, c := range counts {
+		if k != "" {
+			buckets = append(buckets, dto.FacetBucket{Key: k, Count: c})
+		}
+	}
+	slices.SortFunc(buckets, func(a, b dto.FacetBucket) int {
+		if a.Count != b.Count {
+			return int(b.Count - a.Count)
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return buckets[:min(size, len(buckets))]
+}
+
+// highlight wraps the words of doc matching terms in <em> tags, per matching
+// field value.
+func highlight(doc models.Search, terms map[string]float64) []string {
+	values := map[string][]string{
+		"title":       {doc.Title},
+		"description": {doc.Description},
+		"modules":     doc.Modules,
+	}
+
+	highlights := []string{}
+	for _, field := range highlightedFields {
+		for _, v := range values[field] {
+			matched := false
+			h := wordRe.ReplaceAllStringFunc(v, func(w string) string {
+				if _, ok := terms[common.NormalizeText(w)]; ok {
+					matched = true
+					return "<em>" + w + "</em>"
+				}
+				return w
+			})
+			if matched {
+				highlights = append(highlights, h)
+			}
+		}
+	}
+	return highlights
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(common.NormalizeText(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// fuzzyDistance mirrors Elasticsearch's AUTO fuzziness.
+func fuzzyDistance(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
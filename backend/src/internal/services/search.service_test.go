@@ -0,0 +1,416 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	elasticsearch "github.com/elastic/go-elasticsearch/v8"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+)
+
+var (
+	dockerId     = primitive.NewObjectID()
+	kubernetesId = primitive.NewObjectID()
+	pythonDataId = primitive.NewObjectID()
+	pythonAdvId  = primitive.NewObjectID()
+)
+
+func searchFixtures() []models.Roadmap {
+	return []models.Roadmap{
+		{
+			ID: dockerId, UserEmail: "ana@roady.dev", Upvotes: 10,
+			Title: "Docker do zero", Description: "Containers e imagens para iniciantes",
+			Difficulty: "beginner", EstimatedTotalMinutes: 600, Tags: []string{"docker", "devops"},
+			Modules: []models.Modules{{Title: "Imagens"}, {Title: "Volumes"}},
+		},
+		{
+			ID: kubernetesId, UserEmail: "bia@roady.dev", Upvotes: 50,
+			Title: "Kubernetes para produção", Description: "Orquestração de containers em clusters",
+			Difficulty: "advanced", EstimatedTotalMinutes: 4000, Tags: []string{"kubernetes", "devops"},
+			Modules: []models.Modules{{Title: "Pods"}, {Title: "Deployments"}},
+		},
+		{
+			ID: pythonDataId, UserEmail: "ana@roady.dev", Upvotes: 30,
+			Title: "Python para ciência de dados", Description: "Pandas, numpy e visualização",
+			Difficulty: "intermediate", EstimatedTotalMinutes: 1500, Tags: []string{"python", "data-science"},
+			Modules: []models.Modules{{Title: "Pandas"}},
+		},
+		{
+			ID: pythonAdvId, UserEmail: "caio@roady.dev", Upvotes: 80,
+			Title: "Python avançado", Description: "Decorators, geradores e concorrência",
+			Difficulty: "advanced", EstimatedTotalMinutes: 2400, Tags: []string{"python"},
+			Modules: []models.Modules{{Title: "Asyncio"}},
+		},
+	}
+}
+
+func resultIds(r dto.SearchResults) []string {
+	ids := []string{}
+	for _, res := range r.Results {
+		ids = append(ids, res.ID)
+	}
+	return ids
+}
+
+func facetCount(buckets []dto.FacetBucket, key string) int64 {
+	for _, b := range buckets {
+		if b.Key == key {
+			return b.Count
+		}
+	}
+	return 0
+}
+
+// testSearchService is the conformance suite every SearchService must pass.
+// sync makes previously indexed documents visible to searches.
+func testSearchService(t *testing.T, s services.SearchService, sync func() error) {
+	ctx := context.Background()
+
+	if err := s.EnsureIndex(ctx); err != nil {
+		t.Fatalf("EnsureIndex() failed: %v", err)
+	}
+	for _, rm := range searchFixtures() {
+		if err := s.InsertRoadmap(ctx, rm, rm.Title); err != nil {
+			t.Fatalf("InsertRoadmap() failed: %v", err)
+		}
+	}
+	if err := sync(); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	page := dto.SearchQuery{Page: 1, PageSize: 10}
+
+	searchTests := []struct {
+		name  string
+		query string
+		q     dto.SearchQuery
+		want  []string
+	}{
+		{name: "title match", query: "docker", q: page, want: []string{dockerId.Hex()}},
+		{name: "typo", query: "kubernets", q: page, want: []string{kubernetesId.Hex()}},
+		{name: "accents", query: "producao", q: page, want: []string{kubernetesId.Hex()}},
+		{name: "ties by upvotes", query: "python", q: page, want: []string{pythonAdvId.Hex(), pythonDataId.Hex()}},
+		{
+			name:  "difficulty filter",
+			query: "python",
+			q:     dto.SearchQuery{Page: 1, PageSize: 10, Difficulty: []string{"intermediate"}},
+			want:  []string{pythonDataId.Hex()},
+		},
+		{
+			name:  "time filter",
+			query: "containers",
+			q:     dto.SearchQuery{Page: 1, PageSize: 10, Time: []string{"5-20h"}},
+			want:  []string{dockerId.Hex()},
+		},
+		{
+			name:  "author filter",
+			query: "python",
+			q:     dto.SearchQuery{Page: 1, PageSize: 10, Author: "ana@roady.dev"},
+			want:  []string{pythonDataId.Hex()},
+		},
+		{
+			name:  "second page",
+			query: "python",
+			q:     dto.SearchQuery{Page: 2, PageSize: 1},
+			want:  []string{pythonDataId.Hex()},
+		},
+	}
+	for _, tt := range searchTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.SearchRoadmaps(ctx, tt.query, tt.q)
+			if err != nil {
+				t.Fatalf("SearchRoadmaps() failed: %v", err)
+			}
+			if ids := resultIds(got); !slices.Equal(ids, tt.want) {
+				t.Errorf("SearchRoadmaps(%q) = %v, want %v", tt.query, ids, tt.want)
+			}
+		})
+	}
+
+	t.Run("facets", func(t *testing.T) {
+		got, err := s.SearchRoadmaps(ctx, "devops python", page)
+		if err != nil {
+			t.Fatalf("SearchRoadmaps() failed: %v", err)
+		}
+		if got.Total != 4 {
+			t.Errorf("SearchRoadmaps() total = %d, want 4", got.Total)
+		}
+		if c := facetCount(got.Facets.Difficulty, "advanced"); c != 2 {
+			t.Errorf("difficulty facet advanced = %d, want 2", c)
+		}
+		if c := facetCount(got.Facets.Tags, "devops"); c != 2 {
+			t.Errorf("tags facet devops = %d, want 2", c)
+		}
+		if c := facetCount(got.Facets.Time, "over-50h"); c != 1 {
+			t.Errorf("time facet over-50h = %d, want 1", c)
+		}
+		if c := facetCount(got.Facets.Author, "ana@roady.dev"); c != 2 {
+			t.Errorf("author facet ana@roady.dev = %d, want 2", c)
+		}
+	})
+
+	t.Run("facets ignore their own filter", func(t *testing.T) {
+		q := dto.SearchQuery{Page: 1, PageSize: 10, Difficulty: []string{"advanced"}}
+		got, err := s.SearchRoadmaps(ctx, "devops python", q)
+		if err != nil {
+			t.Fatalf("SearchRoadmaps() failed: %v", err)
+		}
+		if got.Total != 2 {
+			t.Errorf("SearchRoadmaps() total = %d, want 2", got.Total)
+		}
+		// the other difficulties can still be picked
+		for key, want := range map[string]int64{"beginner": 1, "intermediate": 1, "advanced": 2} {
+			if c := facetCount(got.Facets.Difficulty, key); c != want {
+				t.Errorf("difficulty facet %s = %d, want %d", key, c, want)
+			}
+		}
+		// the other facets count the advanced roadmaps only
+		if c := facetCount(got.Facets.Tags, "devops"); c != 1 {
+			t.Errorf("tags facet devops = %d, want 1", c)
+		}
+		if c := facetCount(got.Facets.Author, "ana@roady.dev"); c != 0 {
+			t.Errorf("author facet ana@roady.dev = %d, want 0", c)
+		}
+	})
+
+	t.Run("page past the result window", func(t *testing.T) {
+		got, err := s.SearchRoadmaps(ctx, "python", dto.SearchQuery{Page: 200, PageSize: 100})
+		if err != nil {
+			t.Fatalf("SearchRoadmaps() failed: %v", err)
+		}
+		if len(got.Results) != 0 || got.Total != 2 {
+			t.Errorf("SearchRoadmaps() = %d results of %d, want 0 of 2", len(got.Results), got.Total)
+		}
+	})
+
+	t.Run("highlights", func(t *testing.T) {
+		got, err := s.SearchRoadmaps(ctx, "docker", page)
+		if err != nil {
+			t.Fatalf("SearchRoadmaps() failed: %v", err)
+		}
+		if len(got.Results) == 0 || !slices.ContainsFunc(got.Results[0].Highlights, func(h string) bool {
+			return strings.Contains(h, "<em>Docker</em>")
+		}) {
+			t.Errorf("SearchRoadmaps() highlights = %v, want <em>Docker</em>", got.Results)
+		}
+	})
+
+	t.Run("suggest", func(t *testing.T) {
+		got, err := s.SuggestRoadmaps(ctx, "pyt", 5)
+		if err != nil {
+			t.Fatalf("SuggestRoadmaps() failed: %v", err)
+		}
+		titles := []string{}
+		for _, ts := range got.Titles {
+			titles = append(titles, ts.ID)
+		}
+		if want := []string{pythonAdvId.Hex(), pythonDataId.Hex()}; !slices.Equal(titles, want) {
+			t.Errorf("SuggestRoadmaps() titles = %v, want %v", titles, want)
+		}
+		if !slices.Equal(got.Tags, []string{"python"}) {
+			t.Errorf("SuggestRoadmaps() tags = %v, want [python]", got.Tags)
+		}
+	})
+
+	t.Run("similar", func(t *testing.T) {
+		kubernetes := searchFixtures()[1]
+		similarTests := []struct {
+			name     string
+			q        dto.SimilarQuery
+			excluded []string
+			want     []string
+		}{
+			{name: "shared tags and words", q: dto.SimilarQuery{Size: 1}, want: []string{dockerId.Hex()}},
+			{name: "own roadmaps left out", q: dto.SimilarQuery{Size: 1, Email: "ana@roady.dev"}, want: []string{}},
+			{name: "enrolled left out", q: dto.SimilarQuery{Size: 1}, excluded: []string{dockerId.Hex()}, want: []string{}},
+		}
+		for _, tt := range similarTests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := s.SimilarRoadmaps(ctx, kubernetes, tt.q, tt.excluded)
+				if err != nil {
+					t.Fatalf("SimilarRoadmaps() failed: %v", err)
+				}
+				ids := []string{}
+				for _, r := range got {
+					ids = append(ids, r.ID)
+				}
+				if !slices.Equal(ids, tt.want) {
+					t.Errorf("SimilarRoadmaps() = %v, want %v", ids, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("upsert replaces", func(t *testing.T) {
+		rm := searchFixtures()[0]
+		rm.Title = "Podman do zero"
+		rm.Description = "Alternativa sem daemon"
+		rm.Tags = []string{"podman"}
+		rm.Modules = nil
+		if err := s.InsertRoadmap(ctx, rm, rm.Title); err != nil {
+			t.Fatalf("InsertRoadmap() failed: %v", err)
+		}
+		if err := sync(); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+
+		got, err := s.SearchRoadmaps(ctx, "docker", page)
+		if err != nil {
+			t.Fatalf("SearchRoadmaps() failed: %v", err)
+		}
+		if got.Total != 0 {
+			t.Errorf("SearchRoadmaps(docker) = %v after upsert, want none", resultIds(got))
+		}
+		got, err = s.SearchRoadmaps(ctx, "podman", page)
+		if err != nil {
+			t.Fatalf("SearchRoadmaps() failed: %v", err)
+		}
+		if ids := resultIds(got); !slices.Equal(ids, []string{dockerId.Hex()}) {
+			t.Errorf("SearchRoadmaps(podman) = %v, want [%s]", ids, dockerId.Hex())
+		}
+	})
+
+	t.Run("bulk sync", func(t *testing.T) {
+		rm := searchFixtures()[2]
+		rm.Title = "Rust para sistemas"
+		if err := s.BulkSync(ctx, []models.Roadmap{rm}, []string{kubernetesId.Hex()}); err != nil {
+			t.Fatalf("BulkSync() failed: %v", err)
+		}
+		if err := sync(); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+
+		got, err := s.SearchRoadmaps(ctx, "kubernetes", page)
+		if err != nil {
+			t.Fatalf("SearchRoadmaps() failed: %v", err)
+		}
+		if got.Total != 0 {
+			t.Errorf("SearchRoadmaps(kubernetes) = %v after delete, want none", resultIds(got))
+		}
+		got, err = s.SearchRoadmaps(ctx, "rust", page)
+		if err != nil {
+			t.Fatalf("SearchRoadmaps() failed: %v", err)
+		}
+		if ids := resultIds(got); !slices.Equal(ids, []string{pythonDataId.Hex()}) {
+			t.Errorf("SearchRoadmaps(rust) = %v, want [%s]", ids, pythonDataId.Hex())
+		}
+
+		hashes, err := s.IndexedHashes(ctx)
+		if err != nil {
+			t.Fatalf("IndexedHashes() failed: %v", err)
+		}
+		if len(hashes) != 3 || hashes[pythonDataId.Hex()] == "" {
+			t.Errorf("IndexedHashes() = %v, want 3 hashed documents", hashes)
+		}
+	})
+
+	t.Run("reindex", func(t *testing.T) {
+		fixtures := searchFixtures()
+		err := s.Reindex(ctx, func(ctx context.Context) ([]models.Roadmap, error) {
+			// written while the reindex runs, must win over the loaded version
+			rm := fixtures[3]
+			rm.Title = "Go avançado"
+			if err := s.BulkSync(ctx, []models.Roadmap{rm}, nil); err != nil {
+				return nil, err
+			}
+			return fixtures, nil
+		})
+		if err != nil {
+			t.Fatalf("Reindex() failed: %v", err)
+		}
+		if err := sync(); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+
+		hashes, err := s.IndexedHashes(ctx)
+		if err != nil {
+			t.Fatalf("IndexedHashes() failed: %v", err)
+		}
+		if len(hashes) != len(fixtures) {
+			t.Errorf("IndexedHashes() = %d documents after reindex, want %d", len(hashes), len(fixtures))
+		}
+
+		for query, want := range map[string][]string{
+			"docker":     {dockerId.Hex()},
+			"kubernetes": {kubernetesId.Hex()},
+			"go":         {pythonAdvId.Hex()},
+		} {
+			got, err := s.SearchRoadmaps(ctx, query, page)
+			if err != nil {
+				t.Fatalf("SearchRoadmaps() failed: %v", err)
+			}
+			if ids := resultIds(got); !slices.Equal(ids, want) {
+				t.Errorf("SearchRoadmaps(%q) = %v after reindex, want %v", query, ids, want)
+			}
+		}
+	})
+
+	t.Run("reindex keeps deletes", func(t *testing.T) {
+		fixtures := searchFixtures()
+		err := s.Reindex(ctx, func(ctx context.Context) ([]models.Roadmap, error) {
+			// deleted while the reindex runs, the loaded snapshot must not bring it back
+			if err := s.BulkSync(ctx, nil, []string{kubernetesId.Hex()}); err != nil {
+				return nil, err
+			}
+			return fixtures, nil
+		})
+		if err != nil {
+			t.Fatalf("Reindex() failed: %v", err)
+		}
+		if err := sync(); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+
+		hashes, err := s.IndexedHashes(ctx)
+		if err != nil {
+			t.Fatalf("IndexedHashes() failed: %v", err)
+		}
+		if _, ok := hashes[kubernetesId.Hex()]; ok || len(hashes) != len(fixtures)-1 {
+			t.Errorf("IndexedHashes() = %v after reindex, want %d documents without %s", hashes, len(fixtures)-1, kubernetesId.Hex())
+		}
+
+		got, err := s.SearchRoadmaps(ctx, "kubernetes", page)
+		if err != nil {
+			t.Fatalf("SearchRoadmaps() failed: %v", err)
+		}
+		if ids := resultIds(got); len(ids) != 0 {
+			t.Errorf("SearchRoadmaps(kubernetes) = %v after reindex, want none", ids)
+		}
+	})
+}
+
+func TestSearchServiceMemoryImpl(t *testing.T) {
+	testSearchService(t, services.NewSearchServiceMemoryImpl(), func() error { return nil })
+}
+
+// TestSearchServiceElasticImpl runs against a live cluster, only when
+// ELASTIC_TEST_ADDRESSES is set.
+func TestSearchServiceElasticImpl(t *testing.T) {
+	addrs := os.Getenv("ELASTIC_TEST_ADDRESSES")
+	if addrs == "" {
+		t.Skip("ELASTIC_TEST_ADDRESSES not set")
+	}
+
+	es, err := elasticsearch.NewTypedClient(elasticsearch.Config{Addresses: strings.Split(addrs, ",")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := fmt.Sprintf("roadmaps-test-%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		es.Indices.Delete(index + "-*").Do(context.Background())
+	})
+
+	testSearchService(t, services.NewSearchServiceElasticImpl(es, index), func() error {
+		_, err := es.Indices.Refresh().Index(index).Do(context.Background())
+		return err
+	})
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"context"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+)
+
+// SearchSyncService keeps the search index in sync with the roadmaps stored
+// in Mongo.
+type SearchSyncService interface {
+	// Sync applies pending roadmap changes to the index. It tails a change
+	// stream when available, blocking until it fails, and otherwise drains the
+	// outbox once. Meant to be run as a daemon.
+	Sync() error
+
+	// Reindex rebuilds the index from every stored roadmap and swaps it in.
+	Reindex(ctx context.Context) error
+
+	// CheckConsistency reports the roadmaps missing from, extra in or stale in
+	// the index. With repair, the drifted documents are fixed right away.
+	CheckConsistency(ctx context.Context, repair bool) (dto.IndexDrift, error)
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/dto"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/constants"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// searchSyncStateId is the ID of the change stream resume point in the state collection.
+	searchSyncStateId = "roadmaps"
+
+	// searchReindexStateId is the ID of the marker of a running reindex in the
+	// state collection, shared by every replica.
+	searchReindexStateId = "reindex"
+
+	// searchReindexTimeout is how long a reindex marker holds, a replica dying
+	// mid-reindex must not block the next ones forever.
+	searchReindexTimeout = time.Hour
+
+	// errCodeChangeStreamUnsupported is returned when watching a standalone server.
+	errCodeChangeStreamUnsupported = 40573
+
+	// errCodeChangeStreamHistoryLost is returned when the resume point fell off the oplog.
+	errCodeChangeStreamHistoryLost = 286
+)
+
+// roadmapChange is the part of a change stream event the index needs.
+type roadmapChange struct {
+	OperationType string          `bson:"operationType"`
+	FullDocument  *models.Roadmap `bson:"fullDocument"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+}
+
+// SearchSyncServiceMongoImpl feeds the search index from a change stream on
+// the roadmaps collection, falling back to the outbox RoadmapService writes
+// to when the server does not support change streams.
+//
+// Other replicas keep writing to the old index during a reindex, so once the
+// new index is swapped in the changes made since the reindex started are
+// replayed into it: from the change stream, or from the outbox, which is not
+// drained while a reindex runs.
+type SearchSyncServiceMongoImpl struct {
+	roadmapsCol   *mongo.Collection
+	outboxCol     *mongo.Collection
+	stateCol      *mongo.Collection
+	searchService SearchService
+	batchSize     int
+	changeStreams bool
+}
+
+func NewSearchSyncServiceMongoImpl(roadmapsCol, outboxCol, stateCol *mongo.Collection, searchService SearchService, batchSize int) SearchSyncService {
+	return &SearchSyncServiceMongoImpl{
+		roadmapsCol:   roadmapsCol,
+		outboxCol:     outboxCol,
+		stateCol:      stateCol,
+		searchService: searchService,
+		batchSize:     batchSize,
+		changeStreams: true,
+	}
+}
+
+func (s *SearchSyncServiceMongoImpl) Sync() error {
+	ctx := context.Background()
+
+	if s.changeStreams {
+		err := s.tail(ctx)
+		var se mongo.ServerError
+		if !errors.As(err, &se) || !se.HasErrorCode(errCodeChangeStreamUnsupported) {
+			return err
+		}
+		slog.Warn("change streams unavailable, syncing the search index from the outbox")
+		s.changeStreams = false
+	}
+
+	return s.drainOutbox(ctx)
+}
+
+// tail applies change stream events in batches, saving the resume point
+// after each one, until the stream fails.
+func (s *SearchSyncServiceMongoImpl) tail(ctx context.Context) error {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+
+	var state models.SearchSyncState
+	err := s.stateCol.FindOne(ctx, bson.M{"_id": searchSyncStateId}).Decode(&state)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	if state.ResumeToken != nil {
+		opts.SetResumeAfter(state.ResumeToken)
+	}
+
+	cs, err := s.watch(ctx, opts)
+	var se mongo.ServerError
+	if errors.As(err, &se) && se.HasErrorCode(errCodeChangeStreamHistoryLost) {
+		// changes were missed, start over from now and let a reindex catch up
+		slog.Error("search sync resume point lost, a reindex is needed")
+		_, err = s.stateCol.DeleteOne(ctx, bson.M{"_id": searchSyncStateId})
+		if err != nil {
+			return err
+		}
+		return errors.Join(se, errors.New("search sync resume point lost"))
+	}
+	if err != nil {
+		return err
+	}
+	defer cs.Close(ctx)
+
+	for cs.Next(ctx) {
+		if err := s.applyBatch(ctx, cs); err != nil {
+			return err
+		}
+
+		_, err := s.stateCol.UpdateOne(
+			ctx,
+			bson.M{"_id": searchSyncStateId},
+			bson.M{"$set": bson.M{"resumeToken": cs.ResumeToken(), "updatedAt": time.Now()}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return cs.Err()
+}
+
+// watch opens a change stream on the roadmaps. Trending scores are recomputed
+// on every roadmap periodically and are not indexed, their updates are skipped.
+func (s *SearchSyncServiceMongoImpl) watch(ctx context.Context, opts *options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	return s.roadmapsCol.Watch(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"updateDescription.updatedFields.trending": bson.M{"$exists": false}}}},
+	}, opts)
+}
+
+// applyBatch applies the current event of cs and the ones immediately
+// available after it, up to a batch.
+func (s *SearchSyncServiceMongoImpl) applyBatch(ctx context.Context, cs *mongo.ChangeStream) error {
+	changes := []roadmapChange{}
+	for {
+		var change roadmapChange
+		if err := cs.Decode(&change); err != nil {
+			return err
+		}
+		changes = append(changes, change)
+		if len(changes) >= s.batchSize || !cs.TryNext(ctx) {
+			break
+		}
+	}
+	if err := cs.Err(); err != nil {
+		return err
+	}
+	return s.applyChanges(ctx, changes)
+}
+
+// applyChanges indexes the last state of each changed roadmap.
+func (s *SearchSyncServiceMongoImpl) applyChanges(ctx context.Context, changes []roadmapChange) error {
+	last := map[primitive.ObjectID]*models.Roadmap{}
+	order := []primitive.ObjectID{}
+	for _, c := range changes {
+		if _, ok := last[c.DocumentKey.ID]; !ok {
+			order = append(order, c.DocumentKey.ID)
+		}
+		// a nil document means it was deleted, even if before the lookup of an update
+		last[c.DocumentKey.ID] = c.FullDocument
+		if c.OperationType == "delete" {
+			last[c.DocumentKey.ID] = nil
+		}
+	}
+
+	roadmaps := []models.Roadmap{}
+	deletedIds := []string{}
+	for _, id := range order {
+		if rm := last[id]; rm != nil {
+			roadmaps = append(roadmaps, *rm)
+		} else {
+			deletedIds = append(deletedIds, id.Hex())
+		}
+	}
+	return s.searchService.BulkSync(ctx, roadmaps, deletedIds)
+}
+
+// drainOutbox indexes the current state of the roadmaps in the outbox, batch
+// by batch, removing the entries once applied.
+func (s *SearchSyncServiceMongoImpl) drainOutbox(ctx context.Context) error {
+	// the reindex drains the outbox into the new index once swapped in
+	running, err := s.stateCol.CountDocuments(ctx, bson.M{
+		"_id":       searchReindexStateId,
+		"startedAt": bson.M{"$gte": time.Now().Add(-searchReindexTimeout)},
+	})
+	if err != nil {
+		return err
+	}
+	if running > 0 {
+		return nil
+	}
+	return s.applyOutbox(ctx)
+}
+
+// applyOutbox indexes the current state of the roadmaps in the outbox.
+func (s *SearchSyncServiceMongoImpl) applyOutbox(ctx context.Context) error {
+	for {
+		opts := options.Find().SetSort(bson.M{"_id": 1}).SetLimit(int64(s.batchSize))
+		cur, err := s.outboxCol.Find(ctx, bson.M{}, opts)
+		if err != nil {
+			return err
+		}
+		var entries []models.SearchOutbox
+		if err := cur.All(ctx, &entries); err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+
+		entryIds := make([]primitive.ObjectID, len(entries))
+		roadmapIds := []primitive.ObjectID{}
+		for i, e := range entries {
+			entryIds[i] = e.ID
+			if !slices.Contains(roadmapIds, e.RoadmapID) {
+				roadmapIds = append(roadmapIds, e.RoadmapID)
+			}
+		}
+
+		cur, err = s.roadmapsCol.Find(ctx, bson.M{"_id": bson.M{"$in": roadmapIds}})
+		if err != nil {
+			return err
+		}
+		var roadmaps []models.Roadmap
+		if err := cur.All(ctx, &roadmaps); err != nil {
+			return err
+		}
+
+		deletedIds := []string{}
+		for _, id := range roadmapIds {
+			if !slices.ContainsFunc(roadmaps, func(rm models.Roadmap) bool { return rm.ID == id }) {
+				deletedIds = append(deletedIds, id.Hex())
+			}
+		}
+
+		if err := s.searchService.BulkSync(ctx, roadmaps, deletedIds); err != nil {
+			return err
+		}
+		if _, err := s.outboxCol.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": entryIds}}); err != nil {
+			return err
+		}
+
+		if len(entries) < s.batchSize {
+			return nil
+		}
+	}
+}
+
+func (s *SearchSyncServiceMongoImpl) Reindex(ctx context.Context) error {
+	// millisecond precision, as stored, to release the marker by its start
+	start := time.Now().Truncate(time.Millisecond)
+
+	// a fresh marker means another replica is reindexing, a stale one is taken over
+	_, err := s.stateCol.UpdateOne(ctx,
+		bson.M{"_id": searchReindexStateId, "startedAt": bson.M{"$lt": start.Add(-searchReindexTimeout)}},
+		bson.M{"$set": bson.M{"startedAt": start}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return constants.ErrReindexRunning
+	}
+	if err != nil {
+		return err
+	}
+	defer s.stateCol.DeleteOne(context.WithoutCancel(ctx), bson.M{"_id": searchReindexStateId, "startedAt": start})
+
+	// the changes are replayed from before the roadmaps are loaded
+	var resumeToken bson.Raw
+	if s.changeStreams {
+		cs, err := s.watch(ctx, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+		var se mongo.ServerError
+		if err != nil && (!errors.As(err, &se) || !se.HasErrorCode(errCodeChangeStreamUnsupported)) {
+			return err
+		}
+		if err == nil {
+			resumeToken = cs.ResumeToken()
+			cs.Close(ctx)
+		}
+	}
+
+	if err := s.searchService.Reindex(ctx, s.roadmaps); err != nil {
+		return err
+	}
+	if err := s.catchUp(ctx, resumeToken); err != nil {
+		return errors.Join(err, errors.New("could not catch up with the changes made during the reindex"))
+	}
+	slog.Info(fmt.Sprintf("search reindex done in %s", time.Since(start)))
+	return nil
+}
+
+// catchUp applies the changes made since resumeToken, or the outbox without
+// change streams, to the index.
+func (s *SearchSyncServiceMongoImpl) catchUp(ctx context.Context, resumeToken bson.Raw) error {
+	if resumeToken == nil {
+		return s.applyOutbox(ctx)
+	}
+
+	cs, err := s.watch(ctx, options.ChangeStream().SetFullDocument(options.UpdateLookup).SetStartAfter(resumeToken))
+	if err != nil {
+		return err
+	}
+	defer cs.Close(ctx)
+
+	for cs.TryNext(ctx) {
+		if err := s.applyBatch(ctx, cs); err != nil {
+			return err
+		}
+	}
+	return cs.Err()
+}
+
+func (s *SearchSyncServiceMongoImpl) CheckConsistency(ctx context.Context, repair bool) (dto.IndexDrift, error) {
+	roadmaps, err := s.roadmaps(ctx)
+	if err != nil {
+		return dto.IndexDrift{}, err
+	}
+	indexed, err := s.searchService.IndexedHashes(ctx)
+	if err != nil {
+		return dto.IndexDrift{}, err
+	}
+
+	drift := dto.IndexDrift{
+		Stored:  len(roadmaps),
+		Indexed: len(indexed),
+		Missing: []string{},
+		Extra:   []string{},
+		Stale:   []string{},
+	}
+
+	stored := map[string]bool{}
+	drifted := []models.Roadmap{}
+	for _, rm := range roadmaps {
+		id := rm.ID.Hex()
+		stored[id] = true
+
+		hash, ok := indexed[id]
+		switch {
+		case !ok:
+			drift.Missing = append(drift.Missing, id)
+		case hash != searchDoc(rm, rm.PromptKey).Hash:
+			drift.Stale = append(drift.Stale, id)
+		default:
+			continue
+		}
+		drifted = append(drifted, rm)
+	}
+	for id := range indexed {
+		if !stored[id] {
+			drift.Extra = append(drift.Extra, id)
+		}
+	}
+	slices.Sort(drift.Extra)
+
+	if repair && (len(drifted) > 0 || len(drift.Extra) > 0) {
+		if err := s.searchService.BulkSync(ctx, drifted, drift.Extra); err != nil {
+			return drift, err
+		}
+		drift.Repaired = true
+	}
+
+	return drift, nil
+}
+
+// roadmaps loads every stored roadmap.
+func (s *SearchSyncServiceMongoImpl) roadmaps(ctx context.Context) ([]models.Roadmap, error) {
+	cur, err := s.roadmapsCol.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	roadmaps := []models.Roadmap{}
+	if err := cur.All(ctx, &roadmaps); err != nil {
+		return nil, err
+	}
+	return roadmaps, nil
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/common"
+)
+
+type TagService interface {
+	// Normalize slugifies raw tags and resolves them to their canonical tags,
+	// registering the ones never seen before. Duplicates are dropped, the order
+	// is kept.
+	Normalize(ctx context.Context, raw []string) ([]string, error)
+
+	// Tags returns every canonical tag, most used first.
+	Tags(ctx context.Context) ([]models.Tag, error)
+
+	// Tag returns the canonical tag for slug, which may also be one of its synonyms.
+	Tag(ctx context.Context, slug string) (models.Tag, error)
+
+	// UpdateUsage adjusts the usage counts after a roadmap's tags went from
+	// before to after.
+	UpdateUsage(ctx context.Context, before []string, after []string) error
+
+	// SetSynonyms makes synonyms resolve to slug. Tags among synonyms are merged
+	// into slug, along with their counts, their roadmaps are rewritten by the
+	// next Backfill.
+	SetSynonyms(ctx context.Context, slug string, synonyms []string) (models.Tag, error)
+
+	// Backfill rewrites the tags of every roadmap to their canonical form and
+	// recounts usage. A tag whose count changed during the recount keeps it, for
+	// the next Backfill to correct.
+	Backfill() error
+}
+
+// compactSlug is the key that matches slugs differing only in hyphens, like
+// "dev-ops" and "devops".
+func compactSlug(slug string) string {
+	return strings.ReplaceAll(slug, "-", "")
+}
+
+// tagIndex resolves slugs to canonical tags. Exact slugs and synonyms win over
+// compact keys.
+type tagIndex struct {
+	exact   map[string]string
+	compact map[string]string
+}
+
+func newTagIndex(tags []models.Tag) tagIndex {
+	idx := tagIndex{exact: map[string]string{}, compact: map[string]string{}}
+	for _, tag := range tags {
+		idx.add(tag.Slug)
+		for _, synonym := range tag.Synonyms {
+			idx.exact[synonym] = tag.Slug
+		}
+	}
+	return idx
+}
+
+func (idx tagIndex) add(slug string) {
+	idx.exact[slug] = slug
+	if _, ok := idx.compact[compactSlug(slug)]; !ok {
+		idx.compact[compactSlug(slug)] = slug
+	}
+}
+
+func (idx tagIndex) resolve(slug string) (string, bool) {
+	if canonical, ok := idx.exact[slug]; ok {
+		return canonical, true
+	}
+	canonical, ok := idx.compact[compactSlug(slug)]
+	return canonical, ok
+}
+
+// ResolveTags maps raw tags to canonical slugs through the known tags, returning
+// the tags that are not known yet, keyed by slug, with the raw text as their name.
+func ResolveTags(raw []string, known []models.Tag) ([]string, map[string]string) {
+	idx := newTagIndex(known)
+	tags := []string{}
+	created := map[string]string{}
+	seen := map[string]bool{}
+	for _, r := range raw {
+		slug := common.Slugify(r)
+		if slug == "" {
+			continue
+		}
+		canonical, ok := idx.resolve(slug)
+		if !ok {
+			canonical = slug
+			created[slug] = strings.TrimSpace(r)
+			idx.add(slug)
+		}
+		if seen[canonical] {
+			continue
+		}
+		seen[canonical] = true
+		tags = append(tags, canonical)
+	}
+	return tags, created
+}
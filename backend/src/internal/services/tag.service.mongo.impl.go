@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TagServiceMongoImpl struct {
+	tagsCol        *mongo.Collection
+	roadmapService RoadmapService
+}
+
+func NewTagServiceMongoImpl(tagsCol *mongo.Collection, roadmapService RoadmapService) TagService {
+	return &TagServiceMongoImpl{
+		tagsCol:        tagsCol,
+		roadmapService: roadmapService,
+	}
+}
+
+func (s *TagServiceMongoImpl) Normalize(ctx context.Context, raw []string) ([]string, error) {
+	slugs := []string{}
+	compacts := []string{}
+	for _, r := range raw {
+		if slug := common.Slugify(r); slug != "" {
+			slugs = append(slugs, slug)
+			compacts = append(compacts, compactSlug(slug))
+		}
+	}
+	if len(slugs) == 0 {
+		return []string{}, nil
+	}
+
+	cur, err := s.tagsCol.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"_id": bson.M{"$in": slugs}},
+		bson.M{"synonyms": bson.M{"$in": slugs}},
+		bson.M{"compact": bson.M{"$in": compacts}},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	var known []models.Tag
+	if err := cur.All(ctx, &known); err != nil {
+		return nil, err
+	}
+
+	tags, created := ResolveTags(raw, known)
+	now := time.Now()
+	for slug, name := range created {
+		_, err := s.tagsCol.UpdateOne(
+			ctx,
+			bson.M{"_id": slug},
+			bson.M{"$setOnInsert": models.Tag{
+				Slug:      slug,
+				Name:      name,
+				Compact:   compactSlug(slug),
+				Synonyms:  []string{},
+				UpdatedAt: now,
+			}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return nil, errors.Join(err, fmt.Errorf("could not register tag %q", slug))
+		}
+	}
+	return tags, nil
+}
+
+func (s *TagServiceMongoImpl) Tags(ctx context.Context) ([]models.Tag, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}})
+	cur, err := s.tagsCol.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	tags := []models.Tag{}
+	if err := cur.All(ctx, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (s *TagServiceMongoImpl) Tag(ctx context.Context, slug string) (models.Tag, error) {
+	slug = common.Slugify(slug)
+	var tag models.Tag
+	err := s.tagsCol.FindOne(ctx, bson.M{"$or": bson.A{
+		bson.M{"_id": slug},
+		bson.M{"synonyms": slug},
+	}}).Decode(&tag)
+	return tag, err
+}
+
+func (s *TagServiceMongoImpl) UpdateUsage(ctx context.Context, before []string, after []string) error {
+	writes := []mongo.WriteModel{}
+	inc := func(slug string, delta int) {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": slug}).
+			SetUpdate(bson.M{"$inc": bson.M{"count": delta}}),
+		)
+	}
+	for _, slug := range after {
+		if !slices.Contains(before, slug) {
+			inc(slug, 1)
+		}
+	}
+	for _, slug := range before {
+		if !slices.Contains(after, slug) {
+			inc(slug, -1)
+		}
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+
+	_, err := s.tagsCol.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (s *TagServiceMongoImpl) SetSynonyms(ctx context.Context, slug string, synonyms []string) (models.Tag, error) {
+	slug = common.Slugify(slug)
+	syns := []string{}
+	for _, synonym := range synonyms {
+		if synonym = common.Slugify(synonym); synonym != "" && synonym != slug && !slices.Contains(syns, synonym) {
+			syns = append(syns, synonym)
+		}
+	}
+
+	// tags that become synonyms are merged, along with their own synonyms and
+	// their count as of their deletion
+	mergedCount := 0
+	for _, synonym := range slices.Clone(syns) {
+		var merged models.Tag
+		err := s.tagsCol.FindOneAndDelete(ctx, bson.M{"_id": synonym}).Decode(&merged)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return models.Tag{}, errors.Join(err, fmt.Errorf("could not merge tag %q", synonym))
+		}
+		mergedCount += merged.Count
+		for _, other := range merged.Synonyms {
+			if other != slug && !slices.Contains(syns, other) {
+				syns = append(syns, other)
+			}
+		}
+	}
+
+	_, err := s.tagsCol.UpdateMany(
+		ctx,
+		bson.M{"_id": bson.M{"$ne": slug}},
+		bson.M{"$pull": bson.M{"synonyms": bson.M{"$in": append(slices.Clone(syns), slug)}}},
+	)
+	if err != nil {
+		return models.Tag{}, errors.Join(err, errors.New("could not detach synonyms from other tags"))
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var tag models.Tag
+	err = s.tagsCol.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": slug},
+		bson.M{
+			"$set":         bson.M{"synonyms": syns, "updatedAt": time.Now()},
+			"$inc":         bson.M{"count": mergedCount},
+			"$setOnInsert": bson.M{"name": slug, "compact": compactSlug(slug)},
+		},
+		opts,
+	).Decode(&tag)
+	return tag, err
+}
+
+func (s *TagServiceMongoImpl) Backfill() error {
+	ctx := context.Background()
+
+	roadmaps, err := s.roadmapService.Roadmaps(ctx, models.SortUpvotes)
+	if err != nil {
+		return err
+	}
+	rewritten := 0
+	for _, rm := range roadmaps {
+		tags, err := s.Normalize(ctx, rm.Tags)
+		if err != nil {
+			return err
+		}
+		if slices.Equal(tags, rm.Tags) {
+			continue
+		}
+		if err := s.roadmapService.SetTags(ctx, rm.ID.Hex(), tags); err != nil {
+			return errors.Join(err, fmt.Errorf("could not rewrite tags of roadmap %s", rm.ID.Hex()))
+		}
+		rewritten++
+	}
+
+	// counts are read before recounting and only replaced if unchanged since,
+	// so the increments of UpdateUsage meanwhile are not overwritten
+	tags, err := s.Tags(ctx)
+	if err != nil {
+		return err
+	}
+	counts, err := s.roadmapService.TagCounts(ctx)
+	if err != nil {
+		return err
+	}
+	writes := []mongo.WriteModel{}
+	for _, tag := range tags {
+		if tag.Count == counts[tag.Slug] {
+			continue
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": tag.Slug, "count": tag.Count}).
+			SetUpdate(bson.M{"$set": bson.M{"count": counts[tag.Slug]}}),
+		)
+	}
+	if len(writes) > 0 {
+		if _, err := s.tagsCol.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return errors.Join(err, errors.New("could not recount tags"))
+		}
+	}
+
+	slog.Info(fmt.Sprintf("tag backfill: rewrote %d of %d roadmaps", rewritten, len(roadmaps)))
+	return nil
+}
@@ -0,0 +1,61 @@
+package services_test
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+)
+
+func TestResolveTags(t *testing.T) {
+	known := []models.Tag{
+		{Slug: "devops", Compact: "devops", Synonyms: []string{"dev-ops", "sre"}},
+		{Slug: "machine-learning", Compact: "machinelearning", Synonyms: []string{"ml"}},
+		{Slug: "go", Compact: "go", Synonyms: []string{"golang"}},
+	}
+
+	tests := []struct {
+		name        string
+		raw         []string
+		wantTags    []string
+		wantCreated []string
+	}{
+		{
+			name:        "case and synonyms resolve to the canonical tag",
+			raw:         []string{"DevOps", "dev-ops", "SRE", "Golang"},
+			wantTags:    []string{"devops", "go"},
+			wantCreated: []string{},
+		},
+		{
+			name:        "hyphens and spaces do not matter",
+			raw:         []string{"Machine Learning", "machinelearning", "ML"},
+			wantTags:    []string{"machine-learning"},
+			wantCreated: []string{},
+		},
+		{
+			name:        "unknown tags are created once",
+			raw:         []string{"Kubernetes", "kubernetes", "Dev Ops", "Node.js", "nodejs"},
+			wantTags:    []string{"kubernetes", "devops", "node-js"},
+			wantCreated: []string{"kubernetes", "node-js"},
+		},
+		{
+			name:        "empty tags are dropped",
+			raw:         []string{"", "  ", "--"},
+			wantTags:    []string{},
+			wantCreated: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, created := services.ResolveTags(tt.raw, known)
+			if !slices.Equal(tags, tt.wantTags) {
+				t.Errorf("ResolveTags() tags = %v, want %v", tags, tt.wantTags)
+			}
+			if got := slices.Sorted(maps.Keys(created)); !slices.Equal(got, tt.wantCreated) {
+				t.Errorf("ResolveTags() created = %v, want %v", got, tt.wantCreated)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// OverflowPolicy tells what a full telemetry queue does with new items.
+type OverflowPolicy string
+
+const (
+	// OverflowDropNewest rejects new items while the queue is full.
+	OverflowDropNewest OverflowPolicy = "drop-newest"
+
+	// OverflowDropOldest evicts the oldest item to make room for the new one.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+
+	// OverflowSample starts rejecting items once the queue is half full, with a
+	// probability growing linearly to 1 when it is full.
+	OverflowSample OverflowPolicy = "sample"
+)
+
+// TelemetryQueue is a bounded FIFO queue that never blocks on Push, dropping
+// items by its overflow policy instead.
+type TelemetryQueue[T any] struct {
+	mu       sync.Mutex
+	items    []T
+	head     int
+	size     int
+	policy   OverflowPolicy
+	enqueued uint64
+	dropped  uint64
+}
+
+func NewTelemetryQueue[T any](capacity int, policy OverflowPolicy) *TelemetryQueue[T] {
+	return &TelemetryQueue[T]{
+		items:  make([]T, max(capacity, 1)),
+		policy: policy,
+	}
+}
+
+// Push enqueues item, returning false if an item was dropped, be it item or an
+// older one.
+func (q *TelemetryQueue[T]) Push(item T) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	capacity := len(q.items)
+	if q.policy == OverflowSample && q.size >= capacity/2 {
+		keep := float64(capacity-q.size) / float64(capacity-capacity/2)
+		if rand.Float64() >= keep {
+			q.dropped++
+			return false
+		}
+	}
+	if q.size == capacity {
+		q.dropped++
+		if q.policy != OverflowDropOldest {
+			return false
+		}
+		q.items[q.head] = item
+		q.head = (q.head + 1) % capacity
+		q.enqueued++
+		return false
+	}
+
+	q.items[(q.head+q.size)%capacity] = item
+	q.size++
+	q.enqueued++
+	return true
+}
+
+// Drain dequeues up to n items, oldest first.
+func (q *TelemetryQueue[T]) Drain(n int) []T {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n = min(n, q.size)
+	batch := make([]T, n)
+	var zero T
+	for i := range batch {
+		batch[i] = q.items[q.head]
+		q.items[q.head] = zero
+		q.head = (q.head + 1) % len(q.items)
+	}
+	q.size -= n
+	return batch
+}
+
+func (q *TelemetryQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// Counters returns the number of items enqueued and dropped so far.
+func (q *TelemetryQueue[T]) Counters() (enqueued uint64, dropped uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.enqueued, q.dropped
+}
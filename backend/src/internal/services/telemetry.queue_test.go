@@ -0,0 +1,98 @@
+package services_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+)
+
+func TestTelemetryQueue(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      services.OverflowPolicy
+		push        int
+		wantItems   []int
+		wantDropped uint64
+	}{
+		{
+			name:        "under capacity keeps everything",
+			policy:      services.OverflowDropNewest,
+			push:        3,
+			wantItems:   []int{0, 1, 2},
+			wantDropped: 0,
+		},
+		{
+			name:        "drop newest keeps the first items",
+			policy:      services.OverflowDropNewest,
+			push:        6,
+			wantItems:   []int{0, 1, 2, 3},
+			wantDropped: 2,
+		},
+		{
+			name:        "drop oldest keeps the last items",
+			policy:      services.OverflowDropOldest,
+			push:        6,
+			wantItems:   []int{2, 3, 4, 5},
+			wantDropped: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := services.NewTelemetryQueue[int](4, tt.policy)
+			for i := range tt.push {
+				q.Push(i)
+			}
+
+			if got := q.Drain(10); !slices.Equal(got, tt.wantItems) {
+				t.Errorf("Drain() = %v, want %v", got, tt.wantItems)
+			}
+			if _, dropped := q.Counters(); dropped != tt.wantDropped {
+				t.Errorf("Counters() dropped = %d, want %d", dropped, tt.wantDropped)
+			}
+			if q.Len() != 0 {
+				t.Errorf("Len() after draining = %d, want 0", q.Len())
+			}
+		})
+	}
+}
+
+func TestTelemetryQueueDrainBatches(t *testing.T) {
+	q := services.NewTelemetryQueue[int](8, services.OverflowDropNewest)
+	for i := range 5 {
+		q.Push(i)
+	}
+	if got := q.Drain(2); !slices.Equal(got, []int{0, 1}) {
+		t.Errorf("Drain(2) = %v, want [0 1]", got)
+	}
+	// wraps around the ring
+	for i := 5; i < 10; i++ {
+		q.Push(i)
+	}
+	if got := q.Drain(10); !slices.Equal(got, []int{2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Errorf("Drain(10) = %v, want [2 ... 9]", got)
+	}
+}
+
+func TestTelemetryQueueSample(t *testing.T) {
+	q := services.NewTelemetryQueue[int](100, services.OverflowSample)
+	for i := range 1000 {
+		q.Push(i)
+	}
+
+	enqueued, dropped := q.Counters()
+	if enqueued+dropped != 1000 {
+		t.Errorf("Counters() = %d enqueued + %d dropped, want 1000 in total", enqueued, dropped)
+	}
+	if n := q.Len(); n < 50 || n > 100 {
+		t.Errorf("Len() = %d, want between half and full capacity", n)
+	}
+	// nothing is sampled out below half capacity
+	want := make([]int, 50)
+	for i := range want {
+		want[i] = i
+	}
+	if got := q.Drain(50); !slices.Equal(got, want) {
+		t.Errorf("Drain(50) = %v, want 0 to 49", got)
+	}
+}
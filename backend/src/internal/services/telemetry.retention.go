@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy is how long raw telemetry is kept, by metric or event name.
+type RetentionPolicy struct {
+	Default time.Duration
+	ByName  map[string]time.Duration
+}
+
+// For returns the retention of the metric or event called name.
+func (p RetentionPolicy) For(name string) time.Duration {
+	if d, ok := p.ByName[name]; ok {
+		return d
+	}
+	return p.Default
+}
+
+// Max returns the longest retention of the policy, the one the collection TTL
+// is set to; shorter ones are enforced by deletes.
+func (p RetentionPolicy) Max() time.Duration {
+	longest := p.Default
+	for _, d := range p.ByName {
+		longest = max(longest, d)
+	}
+	return longest
+}
+
+// ParseRetentionPolicy builds a policy from the default retention and comma
+// separated name=duration overrides, e.g. "api_call_duration=72h,user_activity=180d".
+// Durations take the time.ParseDuration units plus "d" for days.
+func ParseRetentionPolicy(def time.Duration, overrides string) (RetentionPolicy, error) {
+	p := RetentionPolicy{Default: def, ByName: map[string]time.Duration{}}
+	for _, override := range strings.Split(overrides, ",") {
+		override = strings.TrimSpace(override)
+		if override == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(override, "=")
+		if !ok || name == "" {
+			return RetentionPolicy{}, fmt.Errorf("invalid retention override %q, expected name=duration", override)
+		}
+		d, err := parseRetention(value)
+		if err != nil {
+			return RetentionPolicy{}, errors.Join(err, fmt.Errorf("invalid retention for %q", name))
+		}
+		p.ByName[strings.TrimSpace(name)] = d
+	}
+	return p, nil
+}
+
+func parseRetention(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("retention must be positive, got %s", d)
+	}
+	return d, nil
+}
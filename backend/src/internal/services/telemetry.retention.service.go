@@ -0,0 +1,17 @@
+package services
+
+import "context"
+
+// TelemetryRetentionService keeps the telemetry collections bounded.
+type TelemetryRetentionService interface {
+	// Rollup downsamples the raw metrics of the buckets completed since the last
+	// run into hourly and daily rollups.
+	Rollup() error
+
+	// Expire deletes the raw metrics and events older than their retention.
+	Expire() error
+
+	// Migrate moves the raw metrics and events stored before the switch to
+	// time-series collections over to them.
+	Migrate(ctx context.Context) error
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var rollupResolutions = map[models.RollupResolution]time.Duration{
+	models.RollupHour: time.Hour,
+	models.RollupDay:  24 * time.Hour,
+}
+
+// rollupDroppedTags are left out of the rollup groups: raw paths carry IDs, the
+// route tag already identifies the endpoint.
+var rollupDroppedTags = []string{"path"}
+
+// rollupChunk is the number of buckets aggregated per pipeline, so a first run
+// over the whole raw retention does not become one huge aggregation.
+const rollupChunk = 24
+
+type TelemetryRetentionServiceMongoImpl struct {
+	metricsCol      *mongo.Collection
+	eventsCol       *mongo.Collection
+	rollupsCol      *mongo.Collection
+	stateCol        *mongo.Collection
+	metricRetention RetentionPolicy
+	eventRetention  RetentionPolicy
+	delay           time.Duration
+}
+
+// NewTelemetryRetentionServiceMongoImpl creates a TelemetryRetentionService.
+// Buckets are rolled up delay after they end, to leave the async telemetry
+// pipeline time to flush; the progress of each resolution is kept in stateCol.
+func NewTelemetryRetentionServiceMongoImpl(
+	metricsCol *mongo.Collection,
+	eventsCol *mongo.Collection,
+	rollupsCol *mongo.Collection,
+	stateCol *mongo.Collection,
+	metricRetention RetentionPolicy,
+	eventRetention RetentionPolicy,
+	delay time.Duration,
+) TelemetryRetentionService {
+	return &TelemetryRetentionServiceMongoImpl{
+		metricsCol:      metricsCol,
+		eventsCol:       eventsCol,
+		rollupsCol:      rollupsCol,
+		stateCol:        stateCol,
+		metricRetention: metricRetention,
+		eventRetention:  eventRetention,
+		delay:           delay,
+	}
+}
+
+func (s *TelemetryRetentionServiceMongoImpl) Rollup() error {
+	ctx := context.Background()
+	for res, width := range rollupResolutions {
+		if err := s.rollup(ctx, res, width); err != nil {
+			return errors.Join(err, fmt.Errorf("could not roll up metrics by %s", res))
+		}
+	}
+	return nil
+}
+
+func (s *TelemetryRetentionServiceMongoImpl) rollup(ctx context.Context, res models.RollupResolution, width time.Duration) error {
+	stateId := "rollup-" + string(res)
+	var state struct {
+		Until time.Time `bson:"until"`
+	}
+	err := s.stateCol.FindOne(ctx, bson.M{"_id": stateId}).Decode(&state)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	// buckets are aligned on UTC, like $dateTrunc
+	end := time.Now().Add(-s.delay).UTC().Truncate(width)
+	from := state.Until
+	if oldest := time.Now().Add(-s.metricRetention.Max()).UTC().Truncate(width); from.Before(oldest) {
+		from = oldest
+	}
+
+	for from.Before(end) {
+		to := from.Add(rollupChunk * width)
+		if to.After(end) {
+			to = end
+		}
+		if err := s.rollupRange(ctx, res, from, to); err != nil {
+			return err
+		}
+		_, err := s.stateCol.UpdateOne(ctx,
+			bson.M{"_id": stateId},
+			bson.M{"$set": bson.M{"until": to}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return err
+		}
+		from = to
+	}
+	return nil
+}
+
+// rollupRange aggregates the raw metrics in [from, to) into rollupsCol. The
+// rollup _id is derived from its group, so running it again replaces it.
+func (s *TelemetryRetentionServiceMongoImpl) rollupRange(ctx context.Context, res models.RollupResolution, from, to time.Time) error {
+	// tags are stored from a Go map, in random key order, and Mongo compares
+	// subdocuments field by field: sorting the keys groups equal tag sets
+	pairs := bson.M{"$filter": bson.M{
+		"input": bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$tags", bson.M{}}}},
+		"as":    "tag",
+		"cond":  bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$tag.k", rollupDroppedTags}}}},
+	}}
+	tags := bson.M{"$arrayToObject": bson.M{"$sortArray": bson.M{"input": pairs, "sortBy": bson.M{"k": 1}}}}
+
+	cur, err := s.metricsCol.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"ts": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.D{
+				{Key: "name", Value: "$name"},
+				{Key: "tags", Value: tags},
+				{Key: "ts", Value: bson.M{"$dateTrunc": bson.M{"date": "$ts", "unit": string(res)}}},
+			},
+			"count": bson.M{"$sum": 1},
+			"sum":   bson.M{"$sum": "$value"},
+			"min":   bson.M{"$min": "$value"},
+			"max":   bson.M{"$max": "$value"},
+			"pcts": bson.M{"$percentile": bson.M{
+				"input":  "$value",
+				"p":      bson.A{0.5, 0.95, 0.99},
+				"method": "approximate",
+			}},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id": bson.D{
+				{Key: "name", Value: "$_id.name"},
+				{Key: "resolution", Value: bson.M{"$literal": res}},
+				{Key: "tags", Value: "$_id.tags"},
+				{Key: "ts", Value: "$_id.ts"},
+			},
+			"name":       "$_id.name",
+			"resolution": bson.M{"$literal": res},
+			"tags":       "$_id.tags",
+			"ts":         "$_id.ts",
+			"count":      1,
+			"sum":        1,
+			"min":        1,
+			"max":        1,
+			"avg":        bson.M{"$divide": bson.A{"$sum", "$count"}},
+			"p50":        bson.M{"$arrayElemAt": bson.A{"$pcts", 0}},
+			"p95":        bson.M{"$arrayElemAt": bson.A{"$pcts", 1}},
+			"p99":        bson.M{"$arrayElemAt": bson.A{"$pcts", 2}},
+		}}},
+		{{Key: "$merge", Value: bson.M{
+			"into":           s.rollupsCol.Name(),
+			"on":             "_id",
+			"whenMatched":    "replace",
+			"whenNotMatched": "insert",
+		}}},
+	})
+	if err != nil {
+		return err
+	}
+	return cur.Close(ctx)
+}
+
+func (s *TelemetryRetentionServiceMongoImpl) Expire() error {
+	ctx := context.Background()
+	metricsErr := expire(ctx, s.metricsCol, s.metricRetention)
+	eventsErr := expire(ctx, s.eventsCol, s.eventRetention)
+	if err := errors.Join(metricsErr, eventsErr); err != nil {
+		return errors.Join(err, errors.New("could not expire telemetry"))
+	}
+	return nil
+}
+
+func (s *TelemetryRetentionServiceMongoImpl) Migrate(ctx context.Context) error {
+	metricsErr := MigrateTelemetryCollection(ctx, s.metricsCol.Database(), s.metricsCol.Name(), s.metricRetention.Max())
+	if metricsErr != nil {
+		return errors.Join(metricsErr, errors.New("could not migrate metrics"))
+	}
+	eventsErr := MigrateTelemetryCollection(ctx, s.eventsCol.Database(), s.eventsCol.Name(), s.eventRetention.Max())
+	if eventsErr != nil {
+		return errors.Join(eventsErr, errors.New("could not migrate events"))
+	}
+	return nil
+}
+
+// expire deletes what the collection TTL, set to the longest retention, keeps
+// past a shorter one.
+func expire(ctx context.Context, col *mongo.Collection, policy RetentionPolicy) error {
+	now := time.Now()
+	overridden := []string{}
+	for name, d := range policy.ByName {
+		overridden = append(overridden, name)
+		_, err := col.DeleteMany(ctx, bson.M{"name": name, "ts": bson.M{"$lt": now.Add(-d)}})
+		if err != nil {
+			return err
+		}
+	}
+	_, err := col.DeleteMany(ctx, bson.M{
+		"name": bson.M{"$nin": overridden},
+		"ts":   bson.M{"$lt": now.Add(-policy.Default)},
+	})
+	return err
+}
@@ -0,0 +1,88 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestTelemetryRetentionServiceMongoImpl_Rollup runs against a live MongoDB,
+// only when MONGO_TEST_URI is set.
+func TestTelemetryRetentionServiceMongoImpl_Rollup(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := client.Database(fmt.Sprintf("telemetry-test-%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		db.Drop(context.Background())
+		client.Disconnect(context.Background())
+	})
+
+	// the same tags in every key order, as the driver writes Go maps
+	ts := time.Now().UTC().Truncate(time.Hour).Add(-30 * time.Minute)
+	orders := []bson.D{
+		{{Key: "method", Value: "GET"}, {Key: "route", Value: "/v1/roadmaps"}, {Key: "status", Value: "200"}, {Key: "path", Value: "/v1/roadmaps"}},
+		{{Key: "status", Value: "200"}, {Key: "path", Value: "/v1/roadmaps"}, {Key: "route", Value: "/v1/roadmaps"}, {Key: "method", Value: "GET"}},
+		{{Key: "route", Value: "/v1/roadmaps"}, {Key: "method", Value: "GET"}, {Key: "path", Value: "/v1/roadmaps?sort=trending"}, {Key: "status", Value: "200"}},
+		{{Key: "path", Value: "/v1/roadmaps"}, {Key: "status", Value: "200"}, {Key: "method", Value: "GET"}, {Key: "route", Value: "/v1/roadmaps"}},
+	}
+	docs := []any{}
+	for i, tags := range orders {
+		docs = append(docs, bson.D{
+			{Key: "name", Value: "api_call_duration"},
+			{Key: "tags", Value: tags},
+			{Key: "value", Value: float64(i + 1)},
+			{Key: "ts", Value: ts},
+		})
+	}
+	if _, err := db.Collection("metrics").InsertMany(ctx, docs); err != nil {
+		t.Fatal(err)
+	}
+
+	s := services.NewTelemetryRetentionServiceMongoImpl(
+		db.Collection("metrics"),
+		db.Collection("events"),
+		db.Collection("rollups"),
+		db.Collection("rollup_state"),
+		services.RetentionPolicy{Default: 24 * time.Hour},
+		services.RetentionPolicy{Default: 24 * time.Hour},
+		0,
+	)
+	if err := s.Rollup(); err != nil {
+		t.Fatalf("Rollup() failed: %v", err)
+	}
+
+	cur, err := db.Collection("rollups").Find(ctx, bson.M{"resolution": models.RollupHour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rollups []models.MetricRollup
+	if err := cur.All(ctx, &rollups); err != nil {
+		t.Fatal(err)
+	}
+	if len(rollups) != 1 {
+		t.Fatalf("Rollup() made %d hourly rollups, want 1: %+v", len(rollups), rollups)
+	}
+	got := rollups[0]
+	if got.Count != len(orders) || got.Sum != 10 || got.Min != 1 || got.Max != 4 {
+		t.Errorf("rollup = %+v, want count 4, sum 10, min 1, max 4", got)
+	}
+	if _, ok := got.Tags["path"]; ok || len(got.Tags) != 3 {
+		t.Errorf("rollup tags = %v, want method, route and status", got.Tags)
+	}
+}
@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+)
+
+func TestParseRetentionPolicy(t *testing.T) {
+	const day = 24 * time.Hour
+
+	tests := []struct {
+		name      string
+		overrides string
+		want      map[string]time.Duration
+		wantMax   time.Duration
+		wantErr   bool
+	}{
+		{
+			name:      "no overrides",
+			overrides: "",
+			want:      map[string]time.Duration{"api_call_duration": 14 * day},
+			wantMax:   14 * day,
+		},
+		{
+			name:      "hours and days",
+			overrides: "api_call_duration=72h, user_activity = 180d",
+			want: map[string]time.Duration{
+				"api_call_duration": 72 * time.Hour,
+				"user_activity":     180 * day,
+				"other":             14 * day,
+			},
+			wantMax: 180 * day,
+		},
+		{
+			name:      "missing duration",
+			overrides: "api_call_duration",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid duration",
+			overrides: "api_call_duration=soon",
+			wantErr:   true,
+		},
+		{
+			name:      "non positive duration",
+			overrides: "api_call_duration=0d",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := services.ParseRetentionPolicy(14*day, tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRetentionPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for name, want := range tt.want {
+				if got := p.For(name); got != want {
+					t.Errorf("For(%q) = %s, want %s", name, got, want)
+				}
+			}
+			if got := p.Max(); got != tt.wantMax {
+				t.Errorf("Max() = %s, want %s", got, tt.wantMax)
+			}
+		})
+	}
+}
@@ -2,8 +2,6 @@ package services
 
 import (
 	"context"
-
-	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
 )
 
 // TelemetryService defines the interface for storing and retrieving telemetry data.
@@ -14,9 +12,28 @@ type TelemetryService interface {
 	// RecordMetric logs a numerical metric with a value and optional tags.
 	RecordMetric(ctx context.Context, metricName string, value float64, tags map[string]string) error
 
-	// Upload uploads telemetry the enqueued data. This method should be called
-	// in a separate goroutine to avoid blocking. Should panic if impl is not async.
+	// Upload uploads the enqueued telemetry data as it comes. This method blocks,
+	// it should be called in a separate goroutine. Should panic if impl is not async.
 	Upload() error
 
-	GetEvents(ctx context.Context, filter any) ([]models.Event, error)
+	// Flush writes everything enqueued so far, for a graceful shutdown.
+	Flush(ctx context.Context) error
+
+	// Stats returns the pipeline counters, including the items dropped on overflow.
+	Stats() TelemetryStats
+}
+
+// TelemetryStats are the counters of a telemetry pipeline, by kind.
+type TelemetryStats struct {
+	Events      TelemetryQueueStats `json:"events"`
+	Metrics     TelemetryQueueStats `json:"metrics"`
+	Quarantined TelemetryQueueStats `json:"quarantined"`
+}
+
+type TelemetryQueueStats struct {
+	Queued   int    `json:"queued"`
+	Enqueued uint64 `json:"enqueued"`
+	Dropped  uint64 `json:"dropped"`
+	Written  uint64 `json:"written"`
+	Failed   uint64 `json:"failed"`
 }
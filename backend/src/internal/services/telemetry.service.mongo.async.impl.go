@@ -2,30 +2,53 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
-	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/common"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// TelemetryConfig tunes the async telemetry pipeline.
+type TelemetryConfig struct {
+	QueueSize     int            // per kind, items over it are dropped by Overflow
+	BatchSize     int            // a full batch is flushed right away
+	FlushInterval time.Duration  // partial batches are flushed this often
+	Overflow      OverflowPolicy // what to drop when a queue is full
+}
+
 type TelemetryServiceMongoAsyncImpl struct {
-	mongoClient     *mongo.Client
-	metricsCol      *mongo.Collection
-	eventsCol       *mongo.Collection
-	batchInsertSize uint32
-	metricCh        chan models.Metric
-	eventsCh        chan models.Event
+	mongoClient *mongo.Client
+	metricsCol  *mongo.Collection
+	eventsCol   *mongo.Collection
+	cfg         TelemetryConfig
+	metrics     *TelemetryQueue[models.Metric]
+	events      *TelemetryQueue[models.Event]
+	batchReady  chan struct{}
+	flushMu     sync.Mutex
+	lastDropped uint64 // guarded by flushMu
+
+	metricsWritten atomic.Uint64
+	metricsFailed  atomic.Uint64
+	eventsWritten  atomic.Uint64
+	eventsFailed   atomic.Uint64
 }
 
-func NewTelemetryServiceMongoAsyncImpl(mongoClient *mongo.Client, metricsCol, eventsCol *mongo.Collection, batchInsertSize uint32) TelemetryService {
+// NewTelemetryServiceMongoAsyncImpl creates a TelemetryService whose Record
+// methods never block: items go to bounded queues, flushed by Upload.
+func NewTelemetryServiceMongoAsyncImpl(mongoClient *mongo.Client, metricsCol, eventsCol *mongo.Collection, cfg TelemetryConfig) TelemetryService {
 	return &TelemetryServiceMongoAsyncImpl{
-		mongoClient:     mongoClient,
-		metricsCol:      metricsCol,
-		eventsCol:       eventsCol,
-		metricCh:        make(chan models.Metric, 10*batchInsertSize),
-		eventsCh:        make(chan models.Event, 10*batchInsertSize),
-		batchInsertSize: batchInsertSize,
+		mongoClient: mongoClient,
+		metricsCol:  metricsCol,
+		eventsCol:   eventsCol,
+		cfg:         cfg,
+		metrics:     NewTelemetryQueue[models.Metric](cfg.QueueSize, cfg.Overflow),
+		events:      NewTelemetryQueue[models.Event](cfg.QueueSize, cfg.Overflow),
+		batchReady:  make(chan struct{}, 1),
 	}
 }
 
@@ -36,7 +59,8 @@ func (s *TelemetryServiceMongoAsyncImpl) RecordEvent(ctx context.Context, eventN
 		Tags:     tags,
 		Ts:       time.Now(),
 	}
-	s.eventsCh <- e
+	s.events.Push(e)
+	s.signalBatch(s.events.Len())
 	return nil
 }
 
@@ -47,59 +71,98 @@ func (s *TelemetryServiceMongoAsyncImpl) RecordMetric(ctx context.Context, metri
 		Tags:  tags,
 		Ts:    time.Now(),
 	}
-	s.metricCh <- e
+	s.metrics.Push(e)
+	s.signalBatch(s.metrics.Len())
 	return nil
 }
 
+// signalBatch wakes Upload up once a queue holds a full batch.
+func (s *TelemetryServiceMongoAsyncImpl) signalBatch(queued int) {
+	if queued < s.cfg.BatchSize {
+		return
+	}
+	select {
+	case s.batchReady <- struct{}{}:
+	default:
+	}
+}
+
+// Upload flushes the queues whenever a batch fills up or the flush interval
+// elapses. It only returns on a write error, for the task runner to log it.
 func (s *TelemetryServiceMongoAsyncImpl) Upload() error {
-	for {
-		batch := common.Batch(s.metricCh, s.batchInsertSize)
-		if len(batch) == 0 {
-			break
-		}
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
 
-		docs := make([]any, len(batch))
-		for i, u := range batch {
-			docs[i] = u
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.batchReady:
 		}
-		_, err := s.metricsCol.InsertMany(context.TODO(), docs)
-		if err != nil {
+		if err := s.Flush(context.Background()); err != nil {
 			return err
 		}
 	}
+}
+
+func (s *TelemetryServiceMongoAsyncImpl) Flush(ctx context.Context) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	metricsErr := flushQueue(ctx, s.metrics, s.metricsCol, s.cfg.BatchSize, &s.metricsWritten, &s.metricsFailed)
+	eventsErr := flushQueue(ctx, s.events, s.eventsCol, s.cfg.BatchSize, &s.eventsWritten, &s.eventsFailed)
+	if err := errors.Join(metricsErr, eventsErr); err != nil {
+		return errors.Join(err, errors.New("could not flush telemetry"))
+	}
+
+	stats := s.Stats()
+	if dropped := stats.Metrics.Dropped + stats.Events.Dropped; dropped > s.lastDropped {
+		slog.Warn(fmt.Sprintf(
+			"telemetry queues overflowed (%s), %d items dropped since last flush",
+			s.cfg.Overflow, dropped-s.lastDropped,
+		))
+		s.lastDropped = dropped
+	}
+	return nil
+}
+
+// flushQueue writes q to col in batches until it is empty. A batch that fails
+// to be written is counted and dropped, so a Mongo outage cannot grow memory.
+func flushQueue[T any](ctx context.Context, q *TelemetryQueue[T], col *mongo.Collection, batchSize int, written, failed *atomic.Uint64) error {
 	for {
-		batch := common.Batch(s.eventsCh, s.batchInsertSize)
+		batch := q.Drain(batchSize)
 		if len(batch) == 0 {
-			break
+			return nil
 		}
+
 		docs := make([]any, len(batch))
 		for i, u := range batch {
 			docs[i] = u
 		}
-		_, err := s.metricsCol.InsertMany(context.TODO(), docs)
-		if err != nil {
+		if _, err := col.InsertMany(ctx, docs); err != nil {
+			failed.Add(uint64(len(batch)))
 			return err
 		}
+		written.Add(uint64(len(batch)))
 	}
-
-	return nil
 }
-func (s *TelemetryServiceMongoAsyncImpl) GetEvents(ctx context.Context, filter any) ([]models.Event, error) {
-	cur, err := s.eventsCol.Find(ctx, filter)
-	if err != nil {
-		return nil, err
-	}
-	defer cur.Close(ctx)
-	var events []models.Event
-	for cur.Next(ctx) {
-		var e models.Event
-		if err := cur.Decode(&e); err != nil {
-			return nil, err
-		}
-		events = append(events, e)
-	}
-	if err := cur.Err(); err != nil {
-		return nil, err
+
+func (s *TelemetryServiceMongoAsyncImpl) Stats() TelemetryStats {
+	metricsEnqueued, metricsDropped := s.metrics.Counters()
+	eventsEnqueued, eventsDropped := s.events.Counters()
+	return TelemetryStats{
+		Metrics: TelemetryQueueStats{
+			Queued:   s.metrics.Len(),
+			Enqueued: metricsEnqueued,
+			Dropped:  metricsDropped,
+			Written:  s.metricsWritten.Load(),
+			Failed:   s.metricsFailed.Load(),
+		},
+		Events: TelemetryQueueStats{
+			Queued:   s.events.Len(),
+			Enqueued: eventsEnqueued,
+			Dropped:  eventsDropped,
+			Written:  s.eventsWritten.Load(),
+			Failed:   s.eventsFailed.Load(),
+		},
 	}
-	return events, nil
 }
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// EventValidationMode is what happens to an event that does not match its schema.
+type EventValidationMode string
+
+const (
+	// EventsReject drops the event and returns the error to the caller.
+	EventsReject EventValidationMode = "reject"
+	// EventsQuarantine stores the event aside, along with the error.
+	EventsQuarantine EventValidationMode = "quarantine"
+)
+
+// TelemetryServiceValidatedImpl wraps a TelemetryService, checking every event
+// against the registry before it is recorded. Valid events are tagged with the
+// version of their schema. Quarantined events go to a bounded queue of their
+// own, flushed along with the ones of next by Upload.
+type TelemetryServiceValidatedImpl struct {
+	next          TelemetryService
+	registry      *EventRegistry
+	mode          EventValidationMode
+	quarantineCol *mongo.Collection
+	cfg           TelemetryConfig
+	quarantine    *TelemetryQueue[models.QuarantinedEvent]
+	batchReady    chan struct{}
+	flushMu       sync.Mutex
+	written       atomic.Uint64
+	failed        atomic.Uint64
+	lastDropped   uint64 // guarded by flushMu
+	invalid       *prometheus.CounterVec
+}
+
+func NewTelemetryServiceValidatedImpl(next TelemetryService, registry *EventRegistry, mode EventValidationMode, quarantineCol *mongo.Collection, cfg TelemetryConfig, reg prometheus.Registerer) TelemetryService {
+	return &TelemetryServiceValidatedImpl{
+		next:          next,
+		registry:      registry,
+		mode:          mode,
+		quarantineCol: quarantineCol,
+		cfg:           cfg,
+		quarantine:    NewTelemetryQueue[models.QuarantinedEvent](cfg.QueueSize, cfg.Overflow),
+		batchReady:    make(chan struct{}, 1),
+		invalid: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "telemetry_invalid_events_total",
+			Help: "Events that did not match their schema.",
+		}, []string{"event", "action"}),
+	}
+}
+
+func (s *TelemetryServiceValidatedImpl) RecordEvent(ctx context.Context, eventName string, metadata map[string]any, tags map[string]string) error {
+	err := s.registry.Validate(eventName, metadata)
+	if err == nil {
+		schema, _ := s.registry.Schema(eventName)
+		tagged := maps.Clone(tags)
+		if tagged == nil {
+			tagged = map[string]string{}
+		}
+		tagged["schemaVersion"] = strconv.Itoa(schema.Version)
+		return s.next.RecordEvent(ctx, eventName, metadata, tagged)
+	}
+
+	s.invalid.WithLabelValues(eventName, string(s.mode)).Inc()
+	slog.WarnContext(ctx, err.Error())
+	if s.mode == EventsReject {
+		return err
+	}
+
+	s.quarantine.Push(models.QuarantinedEvent{
+		Event: models.Event{
+			Name:     eventName,
+			Tags:     tags,
+			Metadata: metadata,
+			Ts:       time.Now(),
+		},
+		Error: err.Error(),
+	})
+	if s.quarantine.Len() >= s.cfg.BatchSize {
+		select {
+		case s.batchReady <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *TelemetryServiceValidatedImpl) RecordMetric(ctx context.Context, metricName string, value float64, tags map[string]string) error {
+	return s.next.RecordMetric(ctx, metricName, value, tags)
+}
+
+// Upload runs the upload of next, flushing the quarantine alongside it until
+// it returns. A quarantine batch that fails to be written is logged and
+// dropped, it does not stop the upload of valid telemetry.
+func (s *TelemetryServiceValidatedImpl) Upload() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		ticker := time.NewTicker(s.cfg.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.batchReady:
+			}
+			if err := s.flushQuarantine(ctx); err != nil {
+				slog.Error(err.Error())
+			}
+		}
+	}()
+	return s.next.Upload()
+}
+
+func (s *TelemetryServiceValidatedImpl) Flush(ctx context.Context) error {
+	return errors.Join(s.flushQuarantine(ctx), s.next.Flush(ctx))
+}
+
+func (s *TelemetryServiceValidatedImpl) flushQuarantine(ctx context.Context) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	if err := flushQueue(ctx, s.quarantine, s.quarantineCol, s.cfg.BatchSize, &s.written, &s.failed); err != nil {
+		return errors.Join(err, errors.New("could not flush quarantined events"))
+	}
+	if _, dropped := s.quarantine.Counters(); dropped > s.lastDropped {
+		slog.Warn(fmt.Sprintf(
+			"quarantine queue overflowed (%s), %d events dropped since last flush",
+			s.cfg.Overflow, dropped-s.lastDropped,
+		))
+		s.lastDropped = dropped
+	}
+	return nil
+}
+
+func (s *TelemetryServiceValidatedImpl) Stats() TelemetryStats {
+	stats := s.next.Stats()
+	enqueued, dropped := s.quarantine.Counters()
+	stats.Quarantined = TelemetryQueueStats{
+		Queued:   s.quarantine.Len(),
+		Enqueued: enqueued,
+		Dropped:  dropped,
+		Written:  s.written.Load(),
+		Failed:   s.failed.Load(),
+	}
+	return stats
+}
@@ -0,0 +1,62 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statsTelemetry is a recordingTelemetry with empty stats, for the decorator
+// to add its own to.
+type statsTelemetry struct {
+	recordingTelemetry
+}
+
+func (r *statsTelemetry) Stats() services.TelemetryStats { return services.TelemetryStats{} }
+
+func TestTelemetryServiceValidatedImpl_RecordEvent(t *testing.T) {
+	valid := map[string]any{"email": "a@b.c", "feedId": "f", "roadmapId": "r", "position": 3}
+	invalid := map[string]any{"email": "a@b.c"}
+	cfg := services.TelemetryConfig{QueueSize: 10, BatchSize: 5, Overflow: services.OverflowDropNewest}
+
+	tests := []struct {
+		name            string
+		mode            services.EventValidationMode
+		metadata        map[string]any
+		wantErr         bool
+		wantRecorded    int
+		wantQuarantined int
+	}{
+		{name: "valid event", mode: services.EventsQuarantine, metadata: valid, wantRecorded: 1},
+		{name: "rejected", mode: services.EventsReject, metadata: invalid, wantErr: true},
+		// queued without a write, the collection is only used on flush
+		{name: "quarantined", mode: services.EventsQuarantine, metadata: invalid, wantQuarantined: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &statsTelemetry{}
+			svc := services.NewTelemetryServiceValidatedImpl(
+				next,
+				services.NewEventRegistry(services.ProductEvents...),
+				tt.mode,
+				nil,
+				cfg,
+				prometheus.NewRegistry(),
+			)
+
+			err := svc.RecordEvent(context.Background(), models.EventFeedClick, tt.metadata, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RecordEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(next.events) != tt.wantRecorded {
+				t.Errorf("recorded %d events, want %d", len(next.events), tt.wantRecorded)
+			}
+			if got := svc.Stats().Quarantined.Queued; got != tt.wantQuarantined {
+				t.Errorf("Stats().Quarantined.Queued = %d, want %d", got, tt.wantQuarantined)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// telemetryMigrationBatch is the number of documents copied per insert when
+// migrating a telemetry collection.
+const telemetryMigrationBatch = 1000
+
+// EnsureTelemetryCollection creates the time-series collection name, bucketed
+// by metric or event name and expiring after retention, or updates its expiry
+// if it already exists. A plain collection from before the migration is left
+// as is, to be moved over by MigrateTelemetryCollection.
+func EnsureTelemetryCollection(ctx context.Context, db *mongo.Database, name string, retention time.Duration) error {
+	spec, err := collectionSpec(ctx, db, name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case spec == nil:
+		return createTelemetryCollection(ctx, db, name, retention)
+	case spec.Type != "timeseries":
+		slog.WarnContext(ctx, fmt.Sprintf(
+			"%s.%s is not a time-series collection and never expires, run `migrate-telemetry` to migrate it",
+			db.Name(), name,
+		))
+		return nil
+	default:
+		return db.RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: name},
+			{Key: "expireAfterSeconds", Value: int64(retention.Seconds())},
+		}).Err()
+	}
+}
+
+// MigrateTelemetryCollection moves a plain telemetry collection to a
+// time-series one: it is renamed to <name>_legacy, the documents still within
+// retention are moved over batch by batch, then it is dropped. An interrupted
+// migration is resumed by running it again, at worst copying one batch twice.
+func MigrateTelemetryCollection(ctx context.Context, db *mongo.Database, name string, retention time.Duration) error {
+	legacyName := name + "_legacy"
+	spec, err := collectionSpec(ctx, db, name)
+	if err != nil {
+		return err
+	}
+	if spec != nil && spec.Type != "timeseries" {
+		err := db.Client().Database("admin").RunCommand(ctx, bson.D{
+			{Key: "renameCollection", Value: db.Name() + "." + name},
+			{Key: "to", Value: db.Name() + "." + legacyName},
+		}).Err()
+		if err != nil {
+			return errors.Join(err, fmt.Errorf("could not rename %s", name))
+		}
+	}
+	if err := EnsureTelemetryCollection(ctx, db, name, retention); err != nil {
+		return err
+	}
+
+	legacy, err := collectionSpec(ctx, db, legacyName)
+	if err != nil || legacy == nil {
+		return err
+	}
+	legacyCol := db.Collection(legacyName)
+	cur, err := legacyCol.Find(ctx, bson.M{"ts": bson.M{"$gte": time.Now().Add(-retention)}})
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	col := db.Collection(name)
+	moved := 0
+	ids := []any{}
+	batch := []any{}
+	move := func() error {
+		if _, err := col.InsertMany(ctx, batch); err != nil {
+			return errors.Join(err, fmt.Errorf("could not copy %s", legacyName))
+		}
+		if _, err := legacyCol.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+			return err
+		}
+		moved += len(batch)
+		ids, batch = []any{}, []any{}
+		return nil
+	}
+	for cur.Next(ctx) {
+		var doc bson.M
+		if err := cur.Decode(&doc); err != nil {
+			return err
+		}
+		ids = append(ids, doc["_id"])
+		delete(doc, "_id")
+		batch = append(batch, doc)
+		if len(batch) < telemetryMigrationBatch {
+			continue
+		}
+		if err := move(); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		if err := move(); err != nil {
+			return err
+		}
+	}
+
+	slog.InfoContext(ctx, fmt.Sprintf("moved %d documents from %s to %s", moved, legacyName, name))
+	return db.Collection(legacyName).Drop(ctx)
+}
+
+func createTelemetryCollection(ctx context.Context, db *mongo.Database, name string, retention time.Duration) error {
+	err := db.CreateCollection(ctx, name, options.CreateCollection().
+		SetTimeSeriesOptions(options.TimeSeries().
+			SetTimeField("ts").
+			SetMetaField("name").
+			SetGranularity("seconds"),
+		).
+		SetExpireAfterSeconds(int64(retention.Seconds())),
+	)
+	// another replica may have created it in the meantime
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Name == "NamespaceExists" {
+		return nil
+	}
+	return err
+}
+
+func collectionSpec(ctx context.Context, db *mongo.Database, name string) (*mongo.CollectionSpecification, error) {
+	specs, err := db.ListCollectionSpecifications(ctx, bson.M{"name": name})
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	return specs[0], nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+)
+
+// Weights of each kind of activity in a trending score.
+const (
+	trendingVoteWeight       = 1.0
+	trendingEnrollmentWeight = 3.0
+	trendingForkWeight       = 2.0
+	trendingViewWeight       = 0.1
+
+	// trendingGravity is how fast scores decay with age, as in Hacker News.
+	trendingGravity = 1.8
+)
+
+// trendingWindows are the periods activity is counted over, per window.
+var trendingWindows = map[models.TrendingWindow]time.Duration{
+	models.TrendingDay:   24 * time.Hour,
+	models.TrendingWeek:  7 * 24 * time.Hour,
+	models.TrendingMonth: 30 * 24 * time.Hour,
+}
+
+// TrendingService ranks roadmaps by recent activity, decayed by age so new
+// content gets a chance against roadmaps with many old upvotes.
+type TrendingService interface {
+	// RecordView counts a view of a roadmap.
+	RecordView(ctx context.Context, roadmapId string) error
+
+	// Recompute updates the trending scores stored on every roadmap. Meant to
+	// be run as a daemon.
+	Recompute() error
+
+	// Trending returns the size roadmaps with the highest score over window.
+	Trending(ctx context.Context, window models.TrendingWindow, size int) ([]models.Roadmap, error)
+}
+
+// TrendingScore decays the points of a roadmap with its age, Hacker News
+// style: points / (hours + 2)^gravity. Upvotes are all-time, not windowed like
+// the other counts, so they count logarithmically to leave the recent activity
+// room to move the ranking.
+func TrendingScore(upvotes int, enrollments int, forks int, views int, age time.Duration) float64 {
+	points := trendingVoteWeight*math.Log1p(float64(max(upvotes, 0))) +
+		trendingEnrollmentWeight*float64(enrollments) +
+		trendingForkWeight*float64(forks) +
+		trendingViewWeight*float64(views)
+	return points / math.Pow(max(age.Hours(), 0)+2, trendingGravity)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TrendingServiceMongoImpl struct {
+	roadmapsCol       *mongo.Collection
+	viewsCol          *mongo.Collection
+	enrollmentService EnrollmentService
+}
+
+func NewTrendingServiceMongoImpl(roadmapsCol, viewsCol *mongo.Collection, enrollmentService EnrollmentService) TrendingService {
+	return &TrendingServiceMongoImpl{
+		roadmapsCol:       roadmapsCol,
+		viewsCol:          viewsCol,
+		enrollmentService: enrollmentService,
+	}
+}
+
+func (s *TrendingServiceMongoImpl) RecordView(ctx context.Context, roadmapId string) error {
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	_, err := s.viewsCol.UpdateOne(
+		ctx,
+		bson.M{"roadmapId": roadmapId, "day": day},
+		bson.M{"$inc": bson.M{"count": 1}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (s *TrendingServiceMongoImpl) Recompute() error {
+	ctx := context.Background()
+	now := time.Now()
+
+	enrollments := map[models.TrendingWindow]map[string]int{}
+	forks := map[models.TrendingWindow]map[string]int{}
+	views := map[models.TrendingWindow]map[string]int{}
+	for window, d := range trendingWindows {
+		var err error
+		enrollments[window], err = s.enrollmentService.EnrollmentCounts(ctx, now.Add(-d))
+		if err != nil {
+			return err
+		}
+		forks[window], err = s.forkCounts(ctx, now.Add(-d))
+		if err != nil {
+			return err
+		}
+		views[window], err = s.viewCounts(ctx, now.Add(-d))
+		if err != nil {
+			return err
+		}
+	}
+
+	opts := options.Find().SetProjection(bson.M{"upvotes": 1})
+	cur, err := s.roadmapsCol.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return err
+	}
+	var roadmaps []models.Roadmap
+	if err := cur.All(ctx, &roadmaps); err != nil {
+		return err
+	}
+
+	writes := []mongo.WriteModel{}
+	for _, rm := range roadmaps {
+		id := rm.ID.Hex()
+		age := now.Sub(rm.ID.Timestamp())
+		scores := models.TrendingScores{
+			Day:       TrendingScore(rm.Upvotes, enrollments[models.TrendingDay][id], forks[models.TrendingDay][id], views[models.TrendingDay][id], age),
+			Week:      TrendingScore(rm.Upvotes, enrollments[models.TrendingWeek][id], forks[models.TrendingWeek][id], views[models.TrendingWeek][id], age),
+			Month:     TrendingScore(rm.Upvotes, enrollments[models.TrendingMonth][id], forks[models.TrendingMonth][id], views[models.TrendingMonth][id], age),
+			UpdatedAt: now,
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": rm.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"trending": scores}}))
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+
+	_, err = s.roadmapsCol.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// viewCounts returns the number of views since a day, by roadmap ID.
+func (s *TrendingServiceMongoImpl) viewCounts(ctx context.Context, since time.Time) (map[string]int, error) {
+	cur, err := s.viewsCol.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"day": bson.M{"$gte": since.Truncate(24 * time.Hour)}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$roadmapId", "count": bson.M{"$sum": "$count"}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ID    string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, r := range rows {
+		counts[r.ID] = r.Count
+	}
+	return counts, nil
+}
+
+// forkCounts returns the number of forks created since a time, by ID hex of
+// the forked roadmap. Forks are dated by their ObjectID.
+func (s *TrendingServiceMongoImpl) forkCounts(ctx context.Context, since time.Time) (map[string]int, error) {
+	cur, err := s.roadmapsCol.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"forkedFrom": bson.M{"$exists": true},
+			"_id":        bson.M{"$gte": primitive.NewObjectIDFromTimestamp(since)},
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": "$forkedFrom", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int                `bson:"count"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, r := range rows {
+		counts[r.ID.Hex()] = r.Count
+	}
+	return counts, nil
+}
+
+func (s *TrendingServiceMongoImpl) Trending(ctx context.Context, window models.TrendingWindow, size int) ([]models.Roadmap, error) {
+	if _, ok := trendingWindows[window]; !ok {
+		return nil, fmt.Errorf("unknown trending window %q", window)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "trending." + string(window), Value: -1}, {Key: "upvotes", Value: -1}}).
+		SetLimit(int64(size))
+	cur, err := s.roadmapsCol.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	roadmaps := []models.Roadmap{}
+	if err := cur.All(ctx, &roadmaps); err != nil {
+		return nil, err
+	}
+	return roadmaps, nil
+}
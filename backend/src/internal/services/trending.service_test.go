@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/services"
+)
+
+func TestTrendingScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		higher float64
+		lower  float64
+	}{
+		{
+			name:   "new content beats old upvotes",
+			higher: services.TrendingScore(10, 2, 1, 50, 3*time.Hour),
+			lower:  services.TrendingScore(5000, 0, 0, 0, 90*24*time.Hour),
+		},
+		{
+			name:   "fresh enrollments beat old upvotes",
+			higher: services.TrendingScore(0, 50, 0, 0, 6*time.Hour),
+			lower:  services.TrendingScore(10_000_000, 0, 0, 0, 7*24*time.Hour),
+		},
+		{
+			name:   "windowed activity beats all-time upvotes at the same age",
+			higher: services.TrendingScore(10, 20, 0, 0, 24*time.Hour),
+			lower:  services.TrendingScore(10_000_000, 0, 0, 0, 24*time.Hour),
+		},
+		{
+			name:   "decays with age",
+			higher: services.TrendingScore(100, 0, 0, 0, time.Hour),
+			lower:  services.TrendingScore(100, 0, 0, 0, 2*time.Hour),
+		},
+		{
+			name:   "enrollments weigh more than views",
+			higher: services.TrendingScore(0, 1, 0, 0, time.Hour),
+			lower:  services.TrendingScore(0, 0, 0, 1, time.Hour),
+		},
+		{
+			name:   "forks weigh more than upvotes",
+			higher: services.TrendingScore(0, 0, 1, 0, time.Hour),
+			lower:  services.TrendingScore(1, 0, 0, 0, time.Hour),
+		},
+		{
+			name:   "forks weigh more than views",
+			higher: services.TrendingScore(0, 0, 1, 0, time.Hour),
+			lower:  services.TrendingScore(0, 0, 0, 10, time.Hour),
+		},
+		{
+			name:   "enrollments weigh more than forks",
+			higher: services.TrendingScore(0, 1, 0, 0, time.Hour),
+			lower:  services.TrendingScore(0, 0, 1, 0, time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.higher <= tt.lower {
+				t.Errorf("TrendingScore() = %g, want more than %g", tt.higher, tt.lower)
+			}
+		})
+	}
+
+	if s := services.TrendingScore(0, 0, 0, 0, time.Hour); s != 0 {
+		t.Errorf("TrendingScore() without activity = %g, want 0", s)
+	}
+}
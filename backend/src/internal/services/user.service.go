@@ -6,10 +6,15 @@ import (
 	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/internal/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserService interface {
 	Users(ctx context.Context) ([]models.User, error)
+	User(ctx context.Context, email string) (models.User, error)
+
+	// SetSkillLevel stores the level email says they are at, creating the user if needed.
+	SetSkillLevel(ctx context.Context, email string, level models.SkillLevel) error
 }
 
 type UserServiceImpl struct {
@@ -44,3 +49,19 @@ func (s *UserServiceImpl) Users(ctx context.Context) ([]models.User, error) {
 	}
 	return users, nil
 }
+
+func (s *UserServiceImpl) User(ctx context.Context, email string) (models.User, error) {
+	var user models.User
+	err := s.usersCol.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	return user, err
+}
+
+func (s *UserServiceImpl) SetSkillLevel(ctx context.Context, email string, level models.SkillLevel) error {
+	_, err := s.usersCol.UpdateOne(
+		ctx,
+		bson.M{"email": email},
+		bson.M{"$set": bson.M{"skillLevel": level}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeText lowercases s, strips accents and collapses whitespace, so that
+// "Quero  virar um DevOps" and "quero virar um devops" compare as equal.
+func NormalizeText(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	stripped, _, err := transform.String(t, s)
+	if err != nil {
+		stripped = s
+	}
+
+	return strings.Join(strings.Fields(strings.ToLower(stripped)), " ")
+}
+
+// slugSymbols keeps the symbols that tell languages apart, like "c++" and "c#".
+var slugSymbols = strings.NewReplacer("+", " plus ", "#", " sharp ")
+
+// Slugify turns s into a lowercase, accentless slug with words joined by
+// hyphens, so that "Dev Ops", "dev_ops" and "DEV-OPS" all become "dev-ops".
+func Slugify(s string) string {
+	words := strings.FieldsFunc(slugSymbols.Replace(NormalizeText(s)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
@@ -0,0 +1,61 @@
+package common_test
+
+import (
+	"testing"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/common"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "case and whitespace",
+			in:   "  Quero virar   um DevOps\tEngineer ",
+			want: "quero virar um devops engineer",
+		},
+		{
+			name: "accents",
+			in:   "Programação em Python é ótima",
+			want: "programacao em python e otima",
+		},
+		{
+			name: "empty",
+			in:   "   ",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := common.NormalizeText(tt.in); got != tt.want {
+				t.Errorf("NormalizeText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "DevOps", want: "devops"},
+		{in: " Dev Ops ", want: "dev-ops"},
+		{in: "dev_ops", want: "dev-ops"},
+		{in: "Ciência de Dados", want: "ciencia-de-dados"},
+		{in: "Node.js", want: "node-js"},
+		{in: "C++", want: "c-plus-plus"},
+		{in: "C#", want: "c-sharp"},
+		{in: "--", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := common.Slugify(tt.in); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -7,14 +7,56 @@ import (
 )
 
 const (
-	TimestampStrFormat       string = time.RFC3339 // "yyyy-mm-ddThh:mm:ssZhh:mm" and "2006-01-02T15:04:05-07:00"
-	DefaultTimzone           string = "GMT-3"
-	GinCtxJwtClaimKeyName    string = "jwtClaims"
-	JwtTimeoutSecs           int    = 30 * 60
-	OptLen                   int    = 128
-	OrgInviteTimeoutDays     int    = 15
-	PasswordResetTimeoutDays int    = 1
-	MaxRequestSize           int64  = 5 * 1024 * 1024 // 5MB default
+	TimestampStrFormat        string        = time.RFC3339 // "yyyy-mm-ddThh:mm:ssZhh:mm" and "2006-01-02T15:04:05-07:00"
+	DefaultTimzone            string        = "GMT-3"
+	GinCtxJwtClaimKeyName     string        = "jwtClaims"
+	JwtTimeoutSecs            int           = 30 * 60
+	OptLen                    int           = 128
+	OrgInviteTimeoutDays      int           = 15
+	PasswordResetTimeoutDays  int           = 1
+	MaxRequestSize            int64         = 5 * 1024 * 1024 // 5MB default
+	GenerationCacheTTL        time.Duration = 7 * 24 * time.Hour
+	PromptMinLen              int           = 3
+	PromptMaxLen              int           = 500
+	SuggestTimeout            time.Duration = 150 * time.Millisecond
+	SuggestFallbackTimeout    time.Duration = 300 * time.Millisecond
+	SearchSyncBatchSize       int           = 500
+	SimilarTimeout            time.Duration = 300 * time.Millisecond
+	SimilarFallbackTimeout    time.Duration = 1 * time.Second
+	SimilarFallbackCandidates int64         = 1000
+	TrendingWindow            time.Duration = 7 * 24 * time.Hour
+	TrendingRecomputeInterval time.Duration = 15 * time.Minute
+	ViewsTTL                  time.Duration = 31 * 24 * time.Hour
+	TagBackfillInterval       time.Duration = 24 * time.Hour
+	DuplicateThreshold        float64       = 0.6
+	DuplicateCandidates       int64         = 200
+	DedupBackfillInterval     time.Duration = time.Hour
+	PromptDuplicateThreshold  float64       = 0.8
+	PromptDuplicateCandidates int64         = 50
+	AnalyticsMaxBuckets       int           = 2000
+	FunnelWindow              time.Duration = 90 * 24 * time.Hour
+	FunnelRecomputeInterval   time.Duration = 6 * time.Hour
+	CohortWeeks               int           = 12
+	CreatorDaysInterval       time.Duration = time.Hour
+	CreatorStatsInterval      time.Duration = 24 * time.Hour
+	CalibrationInterval       time.Duration = 24 * time.Hour
+	CalibrationMinSamples     int           = 5
+	CalibrationMinNodes       int           = 3
+	CalibrationMaxFactor      float64       = 4
+	CalibrationHintTopics     int           = 20
+	MaxStudyGap               time.Duration = 12 * time.Hour       // longer between two completions, the learner was away
+	ActivityTTL               time.Duration = 180 * 24 * time.Hour // the per-day index is kept
+	TelemetryQueueSize        int           = 10_000
+	TelemetryBatchSize        int           = 500
+	TelemetryFlushInterval    time.Duration = time.Second
+	TelemetryMetricRetention  time.Duration = 14 * 24 * time.Hour  // raw metrics, longer than a day for the daily rollups
+	TelemetryEventRetention   time.Duration = 90 * 24 * time.Hour  // raw events
+	HourlyRollupTTL           time.Duration = 400 * 24 * time.Hour // daily rollups are kept forever
+	RollupInterval            time.Duration = 15 * time.Minute
+	RollupDelay               time.Duration = 5 * time.Minute
+	TelemetryExpireInterval   time.Duration = time.Hour
+	ShutdownTimeout           time.Duration = 10 * time.Second
+	SearchOutboxTTL           time.Duration = 7 * 24 * time.Hour
 )
 
 var (
@@ -27,4 +69,23 @@ var (
 	S3Endpoint                        string = common.GetEnvVarDefault("S3_ENDPOINT", "https://br-se1.magaluobjects.com")
 	S3Region                          string = common.GetEnvVarDefault("S3_REGION", "br-se1")
 	S3Bucket                          string = common.GetEnvVarDefault("S3_BUCKET", ProjectName+"-roady")
+	GenServiceUrl                     string = common.GetEnvVarDefault("GENSERVICE_URL", "http://genservice:5000/")
+	GeneratorVersion                  string = common.GetEnvVarDefault("GENERATOR_VERSION", "v1")
+	AdminToken                        string = common.GetEnvVarDefault("ADMIN_TOKEN", "")
+	MetricsToken                      string = common.GetEnvVarDefault("METRICS_TOKEN", "")
+	PromptDenyList                    string = common.GetEnvVarDefault("PROMPT_DENY_LIST", "bomba,explosivo,bomb,explosive,malware,ransomware,porn,pornografia")
+	SearchBackend                     string = common.GetEnvVarDefault("SEARCH_BACKEND", "elastic") // "elastic" or "memory"
+	SearchIndex                       string = common.GetEnvVarDefault("SEARCH_INDEX", "roadmaps")
+	ElasticAddresses                  string = common.GetEnvVarDefault("ELASTIC_ADDRESSES", "https://elastic.roady.patos.dev/") // comma separated
+	ElasticUsername                   string = common.GetEnvVarDefault("ELASTIC_USERNAME", "")
+	ElasticPassword                   string = common.GetEnvVarDefault("ELASTIC_PASSWORD", "")
+	ElasticApiKey                     string = common.GetEnvVarDefault("ELASTIC_API_KEY", "")
+	ElasticCaCertPath                 string = common.GetEnvVarDefault("ELASTIC_CA_CERT", "")
+	TelemetryOverflowPolicy           string = common.GetEnvVarDefault("TELEMETRY_OVERFLOW_POLICY", "drop-oldest") // "drop-newest", "drop-oldest" or "sample"
+	TelemetryMetricRetentionOverrides string = common.GetEnvVarDefault("TELEMETRY_METRIC_RETENTION", "")           // e.g. "api_call_duration=72h"
+	TelemetryEventRetentionOverrides  string = common.GetEnvVarDefault("TELEMETRY_EVENT_RETENTION", "")            // e.g. "feed_impression=30d"
+	EventValidationMode               string = common.GetEnvVarDefault("EVENT_VALIDATION", "quarantine")           // "reject" or "quarantine"
+	TracingExporter                   string = common.GetEnvVarDefault("TRACING_EXPORTER", "none")                 // "none", "otlp", "stdout" or "file"
+	TracingFile                       string = common.GetEnvVarDefault("TRACING_FILE", "traces.jsonl")
+	TrustedProxies                    string = common.GetEnvVarDefault("TRUSTED_PROXIES", "") // comma separated IPs or CIDRs, none by default
 )
@@ -7,4 +7,9 @@ var (
 	ErrAuth                = errors.New("auth error")
 	ErrDbConflict          = errors.New("db conflict error")
 	ErrDbTransactionCreate = errors.New("could not create DB transaction")
+	ErrRefinementTarget    = errors.New("refinement target not found")
+	ErrReindexRunning      = errors.New("a reindex is already running")
+	ErrAnalyticsQuery      = errors.New("invalid analytics query")
+	ErrUnknownEvent        = errors.New("unknown event")
+	ErrInvalidEvent        = errors.New("invalid event")
 )
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"log/slog"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 // Task is a struct that defines a task to be run in the background.
 type Task struct {
+	Name     string
 	Interval time.Duration
 	Callable func() error
 	Workers  uint32
@@ -18,42 +22,78 @@ type Task struct {
 type TaskRunner struct {
 	// List of Tasks to be run in the background
 	tasks []Task
+
+	runDuration *prometheus.HistogramVec
+	runErrors   *prometheus.CounterVec
 }
 
 // RegisterTask Creates and inserts a task in the TaskRunner, runs only after Dispatch() call
-func (f *TaskRunner) RegisterTask(interval time.Duration, callable func() error, workers uint32) {
+func (f *TaskRunner) RegisterTask(name string, interval time.Duration, callable func() error, workers uint32) {
 	f.tasks = append(f.tasks, Task{
+		Name:     name,
 		Interval: interval,
 		Callable: callable,
 		Workers:  workers,
 	})
 }
 
+// Instrument records the duration and errors of every task run in reg, call it
+// before Dispatch().
+func (f *TaskRunner) Instrument(reg prometheus.Registerer) {
+	f.runDuration = promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "task_run_duration_seconds",
+		Help:    "Duration of background task runs.",
+		Buckets: []float64{.01, .1, 1, 10, 60, 300, 1800},
+	}, []string{"task"})
+	f.runErrors = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "task_run_errors_total",
+		Help: "Background task runs that failed or panicked.",
+	}, []string{"task"})
+}
+
 // Dispatches Tasks to be run
 func (f *TaskRunner) Dispatch() {
 	for _, v := range f.tasks {
 		for range v.Workers {
-			go taskRunner(v)
+			go f.taskRunner(v)
 		}
 	}
 }
 
-func taskWrapper(t Task) {
+func (f *TaskRunner) taskWrapper(t Task) {
+	start := time.Now()
 	defer func() {
 		if r := recover(); r != nil {
-			slog.Error(fmt.Sprintf("Task crashed: %v, waiting 5s to restart...", r))
+			// the restart delay is not part of the run
+			f.recordDuration(t, start)
+			f.recordError(t)
+			slog.Error(fmt.Sprintf("Task %s crashed: %v, waiting 5s to restart...", t.Name, r))
 			time.Sleep(5 * time.Second)
 		}
 	}()
 	err := t.Callable()
+	f.recordDuration(t, start)
 	if err != nil {
+		f.recordError(t)
 		slog.Error(err.Error())
 	}
 }
 
-func taskRunner(t Task) {
+func (f *TaskRunner) recordDuration(t Task, start time.Time) {
+	if f.runDuration != nil {
+		f.runDuration.WithLabelValues(t.Name).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (f *TaskRunner) recordError(t Task) {
+	if f.runErrors != nil {
+		f.runErrors.WithLabelValues(t.Name).Inc()
+	}
+}
+
+func (f *TaskRunner) taskRunner(t Task) {
 	for {
-		taskWrapper(t)
+		f.taskWrapper(t)
 		time.Sleep(t.Interval)
 	}
 }
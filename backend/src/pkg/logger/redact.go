@@ -0,0 +1,24 @@
+package logger
+
+import "regexp"
+
+var piiPatterns = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`), "[email]"},
+	{regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`), "[cpf]"},
+	// before cards, whose digit groups an international phone number also matches
+	{regexp.MustCompile(`\+55\s?\(?\d{2}\)?\s?9?\d{4}[-\s]?\d{4}\b`), "[phone]"},
+	{regexp.MustCompile(`\b(?:\d[ -]?){13,16}\b`), "[card]"},
+	{regexp.MustCompile(`(?:\+?55\s?)?\(?\d{2}\)?\s?9?\d{4}[-\s]?\d{4}\b`), "[phone]"},
+}
+
+// RedactPII replaces emails, CPFs, card and phone numbers in s with placeholders,
+// it should be used on any user provided text before it reaches the logs.
+func RedactPII(s string) string {
+	for _, p := range piiPatterns {
+		s = p.re.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
@@ -0,0 +1,32 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/logger"
+)
+
+func TestRedactPII(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "nothing to redact", in: "quero aprender go em 3 meses", want: "quero aprender go em 3 meses"},
+		{name: "email", in: "sou joao.silva+dev@mail.example.com, oi", want: "sou [email], oi"},
+		{name: "formatted cpf", in: "meu cpf é 123.456.789-09", want: "meu cpf é [cpf]"},
+		{name: "bare cpf", in: "cpf 12345678909", want: "cpf [cpf]"},
+		{name: "card", in: "cartão 4111 1111 1111 1111", want: "cartão [card]"},
+		{name: "mobile phone", in: "liga (11) 98765-4321", want: "liga [phone]"},
+		{name: "phone with country code", in: "whats +55 11 98765-4321", want: "whats [phone]"},
+		{name: "landline", in: "fixo 11 3456-7890", want: "fixo [phone]"},
+		{name: "several", in: "a@b.co / 123.456.789-09", want: "[email] / [cpf]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logger.RedactPII(tt.in); got != tt.want {
+				t.Errorf("RedactPII(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
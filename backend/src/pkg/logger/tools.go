@@ -25,13 +25,13 @@ func InitSlogger() {
 		"ERROR":   slog.LevelError,
 	}
 
-	logger := slog.New(slog.NewTextHandler(
+	logger := slog.New(TraceHandler{slog.NewTextHandler(
 		os.Stdout,
 		&slog.HandlerOptions{
 			AddSource: true,
 			Level:     lvls[LOG_LEVEL],
 		},
-	))
+	)})
 
 	slog.SetDefault(logger)
 }
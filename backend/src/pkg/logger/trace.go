@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceHandler adds the trace and span IDs of the record's context to log
+// lines, so they can be found from a trace. Use the slog *Context functions.
+type TraceHandler struct {
+	slog.Handler
+}
+
+func (h TraceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h TraceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return TraceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h TraceHandler) WithGroup(name string) slog.Handler {
+	return TraceHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,35 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/LombardiDaniel/hackathon-secomp-2025/backend/src/pkg/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(logger.TraceHandler{Handler: slog.NewTextHandler(&buf, nil)})
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	})
+	log.InfoContext(trace.ContextWithSpanContext(context.Background(), sc), "traced")
+	log.With("k", "v").InfoContext(context.Background(), "untraced")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2", len(lines))
+	}
+	if !strings.Contains(lines[0], "trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7") {
+		t.Errorf("traced line = %q, want trace and span IDs", lines[0])
+	}
+	if strings.Contains(lines[1], "trace_id") {
+		t.Errorf("untraced line = %q, want no trace ID", lines[1])
+	}
+}
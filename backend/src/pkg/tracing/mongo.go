@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewMongoMonitor returns a command monitor wrapping every Mongo command in a
+// client span, child of the span in the command's context. Command bodies are
+// left out, they hold user data.
+func NewMongoMonitor() *event.CommandMonitor {
+	var spans sync.Map // request ID to trace.Span
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			attrs := []attribute.KeyValue{
+				attribute.String("db.system", "mongodb"),
+				attribute.String("db.namespace", evt.DatabaseName),
+				attribute.String("db.operation.name", evt.CommandName),
+			}
+			name := evt.CommandName
+			if collection, ok := evt.Command.Lookup(evt.CommandName).StringValueOK(); ok {
+				attrs = append(attrs, attribute.String("db.collection.name", collection))
+				name += " " + collection
+			}
+			_, span := Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+			spans.Store(evt.RequestID, span)
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			if span, ok := spans.LoadAndDelete(evt.RequestID); ok {
+				span.(trace.Span).End()
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			if span, ok := spans.LoadAndDelete(evt.RequestID); ok {
+				span.(trace.Span).SetStatus(codes.Error, evt.Failure)
+				span.(trace.Span).End()
+			}
+		},
+	}
+}
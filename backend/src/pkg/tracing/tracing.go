@@ -0,0 +1,104 @@
+// Package tracing sets up OpenTelemetry tracing: the exporter, the W3C trace
+// context propagation and the instrumentation of clients without an official one.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of the spans started by this module.
+const TracerName = "github.com/LombardiDaniel/hackathon-secomp-2025/backend/src"
+
+// Exporter tells where spans go.
+type Exporter string
+
+const (
+	ExporterNone   Exporter = "none"
+	ExporterOtlp   Exporter = "otlp"   // OTLP over HTTP, configured by the OTEL_EXPORTER_OTLP_* env vars
+	ExporterStdout Exporter = "stdout" // pretty printed JSON, for local debugging
+	ExporterFile   Exporter = "file"   // JSON lines appended to a file, for offline use
+)
+
+type Config struct {
+	ServiceName string
+	Exporter    Exporter
+	FilePath    string // for ExporterFile
+}
+
+// Tracer returns the tracer of this module.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Init installs the global tracer provider and propagator, returning a function
+// that flushes pending spans and shuts the provider down. Sampling follows the
+// OTEL_TRACES_SAMPLER env vars, always on by default.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	if cfg.Exporter == ExporterNone || cfg.Exporter == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+		err      error
+	)
+	switch cfg.Exporter {
+	case ExporterOtlp:
+		exporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterFile:
+		var f *os.File
+		f, err = os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, errors.Join(err, errors.New("could not open trace file"))
+		}
+		closer = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, expected none, otlp, stdout or file", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, errors.Join(err, errors.New("could not create trace exporter"))
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
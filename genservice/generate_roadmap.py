@@ -114,14 +114,68 @@ Sua saída DEVE seguir estritamente o JSON Schema abaixo.
 {schema_string}
 ```"""
 
+# --- 3. OPÇÕES DE GERAÇÃO ---
+DESCRICAO_OPCOES = {
+    "difficulty": "Dificuldade alvo do plano: {}",
+    "skillLevel": "Nível atual de conhecimento do cliente: {}",
+    "weeklyHours": "Horas disponíveis por semana: {}",
+    "timeBudgetHours": "Tempo total disponível (horas): {}. O `estimatedTotalMinutes` não deve ultrapassar esse limite.",
+    "language": "Idioma de todo o conteúdo gerado: {}",
+    "focusTags": "Tópicos em que o plano deve focar: {}",
+}
+
+
+def montar_prompt(prompt_usuario: str, opcoes: dict):
+    linhas = []
+    for chave, descricao in DESCRICAO_OPCOES.items():
+        valor = opcoes.get(chave)
+        if not valor:
+            continue
+        if isinstance(valor, list):
+            valor = ", ".join(valor)
+        linhas.append("- " + descricao.format(valor))
+
+    linhas += descrever_calibracao(opcoes.get("calibration"))
+
+    if not linhas:
+        return prompt_usuario
+    return prompt_usuario + "\n\n### PREFERÊNCIAS DO CLIENTE\n" + "\n".join(linhas)
+
+
+def descrever_calibracao(calibracao: dict):
+    """
+    Traduz as dicas de calibração do backend em instruções para as estimativas.
+    Um fator é quanto tempo os alunos realmente levam em relação ao estimado,
+    por exemplo 1.5 quer dizer 50% a mais.
+    """
+    if not calibracao:
+        return []
+
+    linhas = []
+    geral = calibracao.get("overall")
+    if geral:
+        linhas.append(
+            f"- Calibração de tempo: os alunos levam em média {geral:.2f}x o tempo estimado. "
+            "Ajuste os `estimatedMinutes` por esse fator."
+        )
+    topicos = calibracao.get("topics") or {}
+    if topicos:
+        fatores = ", ".join(f"{tag} {fator:.2f}x" for tag, fator in sorted(topicos.items()))
+        linhas.append(
+            f"- Calibração de tempo por tópico: {fatores}. "
+            "Nos tópicos listados, use o fator do tópico em vez do geral."
+        )
+    return linhas
+
+
 # --- 4. CHAMADA À API ---
-def gerar_plano_estudos(prompt_usuario: str):
+def gerar_plano_estudos(prompt_usuario: str, opcoes: dict = None):
     print("Entrei na função para gerar o plano de estudos")
     response = client.chat.completions.create(
         model="gpt-4o",
         messages=[
             {"role": "system", "content": create_system_message(JSON_SCHEMA)},
-            {"role": "user", "content": prompt_usuario}
+            {"role": "user", "content": montar_prompt(prompt_usuario, opcoes or {})}
         ],
         response_format={"type": "json_object"}  # força saída em JSON válido
     )
@@ -129,8 +183,8 @@ def gerar_plano_estudos(prompt_usuario: str):
     conteudo = response.choices[0].message.content
     try:
         print("Mandando conteudo")
+        # o roadmap gerado pode repetir dados pessoais do prompt, não é logado
         data = json.loads(conteudo)
-        print(data)
         print("Validando JSON com schema")
         # validate(instance=data, schema=JSON_SCHEMA)
         print("JSON válido e validado pelo schema!")
@@ -138,4 +192,42 @@ def gerar_plano_estudos(prompt_usuario: str):
     except ValidationError as e:
         print("Erro de validação do JSON:", e)
     except json.JSONDecodeError as e:
-        print("Erro ao decodificar JSON:", e)
\ No newline at end of file
+        print("Erro ao decodificar JSON:", e)
+
+
+# --- 5. REFINAMENTO ---
+INSTRUCOES_ESCOPO = {
+    "roadmap": "Reescreva o plano de estudos inteiro seguindo a instrução do cliente. Mantenha os títulos de módulos e tópicos que não precisarem mudar.",
+    "module": "Regenere APENAS o módulo com id `{target_id}`, mantendo o mesmo `id` de módulo. Os demais módulos e tópicos devem ser devolvidos sem alterações.",
+    "node": "Expanda o tópico com id `{target_id}` em sub-tópicos mais detalhados. Devolva o plano completo, com os novos sub-tópicos adicionados ao mesmo módulo do tópico original.",
+}
+
+
+def refinar_plano_estudos(roadmap: dict, escopo: str, target_id: str, instrucao: str):
+    print("Entrei na função para refinar o plano de estudos")
+    tarefa = INSTRUCOES_ESCOPO.get(escopo, INSTRUCOES_ESCOPO["roadmap"]).format(target_id=target_id)
+    mensagem = f"""### PLANO ATUAL
+```json
+{json.dumps(roadmap, ensure_ascii=False, indent=2)}
+```
+
+### TAREFA
+{tarefa}
+
+### INSTRUÇÃO DO CLIENTE
+{instrucao or "Melhore o conteúdo mantendo o mesmo tema."}"""
+
+    response = client.chat.completions.create(
+        model="gpt-4o",
+        messages=[
+            {"role": "system", "content": create_system_message(JSON_SCHEMA)},
+            {"role": "user", "content": mensagem}
+        ],
+        response_format={"type": "json_object"}
+    )
+    print("Resposta recebida da API")
+    conteudo = response.choices[0].message.content
+    try:
+        return json.loads(conteudo)
+    except json.JSONDecodeError as e:
+        print("Erro ao decodificar JSON:", e)
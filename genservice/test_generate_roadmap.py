@@ -0,0 +1,32 @@
+import unittest
+
+from generate_roadmap import montar_prompt
+
+
+class MontarPromptTest(unittest.TestCase):
+    def test_sem_opcoes(self):
+        self.assertEqual(montar_prompt("Aprender Go", {}), "Aprender Go")
+
+    def test_calibracao(self):
+        prompt = montar_prompt(
+            "Aprender Go",
+            {
+                "difficulty": "beginner",
+                "calibration": {"overall": 1.3, "topics": {"react": 1.6, "python": 1.1}},
+            },
+        )
+        self.assertIn("Dificuldade alvo do plano: beginner", prompt)
+        self.assertIn("os alunos levam em média 1.30x o tempo estimado", prompt)
+        self.assertIn("Calibração de tempo por tópico: python 1.10x, react 1.60x", prompt)
+
+    def test_calibracao_sem_fator_geral(self):
+        prompt = montar_prompt("Aprender Go", {"calibration": {"overall": 0, "topics": {"go": 2}}})
+        self.assertNotIn("em média", prompt)
+        self.assertIn("Calibração de tempo por tópico: go 2.00x", prompt)
+
+    def test_calibracao_vazia(self):
+        self.assertEqual(montar_prompt("Aprender Go", {"calibration": {"overall": 0}}), "Aprender Go")
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,39 @@
+import os
+
+from opentelemetry import propagate, trace
+from opentelemetry.baggage.propagation import W3CBaggagePropagator
+from opentelemetry.instrumentation.flask import FlaskInstrumentor
+from opentelemetry.instrumentation.httpx import HTTPXClientInstrumentor
+from opentelemetry.propagators.composite import CompositePropagator
+from opentelemetry.sdk.resources import Resource
+from opentelemetry.sdk.trace import TracerProvider
+from opentelemetry.sdk.trace.export import BatchSpanProcessor, ConsoleSpanExporter
+from opentelemetry.trace.propagation.tracecontext import TraceContextTextMapPropagator
+
+
+def configurar_tracing(app):
+    """
+    Instrumenta o Flask e as chamadas à OpenAI (feitas com httpx), como o backend:
+    o traceparent recebido é continuado, com os propagadores W3C.
+    TRACING_EXPORTER escolhe para onde vão os spans: "none" (padrão), "otlp",
+    configurado pelas variáveis OTEL_EXPORTER_OTLP_*, ou "stdout".
+    """
+    propagate.set_global_textmap(
+        CompositePropagator([TraceContextTextMapPropagator(), W3CBaggagePropagator()])
+    )
+
+    exportador = os.getenv("TRACING_EXPORTER", "none").lower()
+    resource = Resource.create({"service.name": os.getenv("OTEL_SERVICE_NAME", "genservice")})
+    provider = TracerProvider(resource=resource)
+    if exportador == "otlp":
+        from opentelemetry.exporter.otlp.proto.http.trace_exporter import OTLPSpanExporter
+
+        provider.add_span_processor(BatchSpanProcessor(OTLPSpanExporter()))
+    elif exportador == "stdout":
+        provider.add_span_processor(BatchSpanProcessor(ConsoleSpanExporter()))
+    elif exportador != "none":
+        raise ValueError(f"TRACING_EXPORTER {exportador!r} desconhecido, esperado none, otlp ou stdout")
+    trace.set_tracer_provider(provider)
+
+    FlaskInstrumentor().instrument_app(app)
+    HTTPXClientInstrumentor().instrument()